@@ -2,27 +2,41 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"runtime"
 	"strings"
 	"time"
 
 	"github.com/VictoriaMetrics/metrics"
 	"github.com/danielgtaylor/huma/v2"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
+	"github.com/rlibaert/service-example-go/client"
 	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/grpcapi"
 	"github.com/rlibaert/service-example-go/restapi"
 	"github.com/rlibaert/service-example-go/router"
 	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/webhooks"
 	"github.com/rlibaert/service-example-go/wrappers"
 )
 
 type ServerOptions struct {
-	Host              string        `short:"H" doc:"host to listen on"                    default:""`
-	Port              string        `short:"p" doc:"port to listen on"                    default:"8888"`
-	ReadHeaderTimeout time.Duration `          doc:"time allowed to read request headers" default:"15s"`
+	Host              string        `short:"H" doc:"host to listen on"                                default:""`
+	Port              string        `short:"p" doc:"port to listen on"                                default:"8888"`
+	ReadHeaderTimeout time.Duration `          doc:"time allowed to read request headers"             default:"15s"`
+	ShutdownDelay     time.Duration `          doc:"time between failing readiness and shutting down" default:"0s"`
+	GRPCPort          string        `          doc:"port to serve the gRPC API on, disabled if empty"`
 }
 
 func NewServer(options *ServerOptions, handler http.Handler, logger *slog.Logger) *http.Server {
@@ -34,8 +48,224 @@ func NewServer(options *ServerOptions, handler http.Handler, logger *slog.Logger
 	}
 }
 
+type StoreOptions struct {
+	StoreDSN       string        `doc:"SQLite database DSN, e.g. file:contacts.db; an in-memory mock is used if empty"`
+	TrashRetention time.Duration `doc:"time deleted contacts are kept in the trash, forever if zero"                   default:"720h"`
+}
+
+// NewStore opens the [domain.Store] selected by the options.
+func NewStore(ctx context.Context, options *StoreOptions) (domain.Store, error) {
+	if options.StoreDSN == "" {
+		return stores.MustNewMock(&domain.Contact{
+			Firstname: "john",
+			Lastname:  "smith",
+			Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		}), nil
+	}
+	return stores.NewSQLite(ctx, options.StoreDSN)
+}
+
+type WebhooksOptions struct {
+	WebhooksInterval    time.Duration `doc:"time between deliveries of contact events to webhooks"     default:"5s"`
+	WebhooksMaxAttempts int           `doc:"attempts of webhook deliveries before dead-lettering them" default:"10"`
+	WebhooksTimeout     time.Duration `doc:"time allowed to webhook deliveries"                        default:"10s"`
+	WebhooksWorkers     int           `doc:"webhook deliveries attempted concurrently"                 default:"8"`
+	WebhooksRetention   time.Duration `doc:"time dead-lettered webhook deliveries are kept"            default:"168h"`
+	WebhooksPrivate     bool          `doc:"deliver webhooks to private, loopback and link-local addresses too"`
+}
+
+// NewWebhooksStore returns the store if it can keep webhook subscriptions, an in-memory one otherwise.
+func NewWebhooksStore(store domain.Store) webhooks.Store {
+	if s, ok := store.(webhooks.Store); ok {
+		return s
+	}
+	return &webhooks.Memory{}
+}
+
+// NewDispatcher returns a [webhooks.Dispatcher] of the events of the store, nil if it has no outbox.
+func NewDispatcher(
+	options *WebhooksOptions,
+	store domain.Store,
+	subscriptions webhooks.Store,
+	logger *slog.Logger,
+) *webhooks.Dispatcher {
+	outbox, ok := store.(domain.OutboxStore)
+	if !ok {
+		return nil
+	}
+	client := webhooks.NewClient(options.WebhooksTimeout)
+	if options.WebhooksPrivate {
+		client.Transport = http.DefaultTransport
+	}
+	return &webhooks.Dispatcher{
+		Outbox:      outbox,
+		Store:       subscriptions,
+		Client:      client,
+		Payload:     func(e *domain.Event) any { return restapi.NewEventModel(e) },
+		MaxAttempts: options.WebhooksMaxAttempts,
+		Workers:     options.WebhooksWorkers,
+		Retention:   options.WebhooksRetention,
+		ErrorHandler: func(ctx context.Context, err error) {
+			logger.LogAttrs(ctx, slog.LevelWarn, "could not deliver webhooks", slog.Any("err", err))
+		},
+	}
+}
+
+type AuthOptions struct {
+	JWKS        string `doc:"authenticate JWT bearer tokens with the keys of a JWKS file or URL"`
+	JWTIssuer   string `doc:"expected issuer of JWT bearer tokens"`
+	JWTAudience string `doc:"expected audience of JWT bearer tokens"`
+	APIKeysFile string `doc:"authenticate API keys with a JSON file mapping keys to principals"`
+	PolicyFile  string `doc:"authorize callers with a JSON file mapping roles to permissions"`
+}
+
+// Auth is the authentication and authorization of the endpoints, both disabled without authenticators.
+type Auth struct {
+	Authenticators map[string]router.Authenticator
+	Policy         wrappers.Policy
+}
+
+// NewAuth returns the [Auth] selected by the options.
+func NewAuth(ctx context.Context, options *AuthOptions) (*Auth, error) {
+	auth := &Auth{Authenticators: map[string]router.Authenticator{}}
+
+	if options.JWKS != "" {
+		validator := &router.JWTValidator{
+			Keys:     &router.JWKS{Location: options.JWKS},
+			Issuer:   options.JWTIssuer,
+			Audience: options.JWTAudience,
+		}
+		_, err := validator.Keys.Keys(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		auth.Authenticators["bearer"] = router.BearerAuthenticator("JWT", validator.Authenticate)
+	}
+
+	if options.APIKeysFile != "" {
+		b, err := os.ReadFile(options.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		var keys map[string]*router.Principal
+		err = json.Unmarshal(b, &keys)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", options.APIKeysFile, err)
+		}
+		auth.Authenticators["apikey"] = router.APIKeyAuthenticator("X-API-Key", keys)
+	}
+
+	if options.PolicyFile != "" {
+		var err error
+		auth.Policy, err = wrappers.LoadPolicy(options.PolicyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return auth, nil
+}
+
+// opt returns [router.OptAuth], or a no-op option if there are no authenticators.
+func (auth *Auth) opt() func(huma.API) {
+	if len(auth.Authenticators) == 0 {
+		return func(huma.API) {}
+	}
+	return router.OptAuth(auth.Authenticators)
+}
+
+// wrap returns a [domain.Service] authorizing callers, or the service as is if there are no authenticators.
+func (auth *Auth) wrap(service domain.Service) domain.Service {
+	if len(auth.Authenticators) == 0 {
+		return service
+	}
+	return wrappers.ServiceAuthorizer{Service: service, Policy: auth.Policy, Roles: auth.roles}
+}
+
+// wrapWebhooks returns a [webhooks.Store] authorizing callers, or the store as is if there are no authenticators.
+func (auth *Auth) wrapWebhooks(store webhooks.Store) webhooks.Store {
+	if len(auth.Authenticators) == 0 {
+		return store
+	}
+	return wrappers.WebhooksAuthorizer{Store: store, Policy: auth.Policy, Roles: auth.roles}
+}
+
+// roles returns the roles of the authenticated caller.
+func (*Auth) roles(ctx context.Context) []string {
+	p := router.PrincipalFrom(ctx)
+	if p == nil {
+		return nil
+	}
+	return p.Roles
+}
+
+type ClientOptions struct {
+	Endpoint string `doc:"URL of the API of a running instance, for the contacts commands" default:"http://localhost:8888/api"`
+	APIKey   string `doc:"API key the contacts commands authenticate with"`
+	Token    string `doc:"JWT bearer token the contacts commands authenticate with"`
+}
+
+// NewClient returns the [client.Service] of the running instance selected by the options,
+// sending the tenant of calls in a header.
+func NewClient(options *ClientOptions, tenantHeader string) *client.Service {
+	header := http.Header{}
+	if options.APIKey != "" {
+		header.Set("X-Api-Key", options.APIKey)
+	}
+	if options.Token != "" {
+		header.Set("Authorization", "Bearer "+options.Token)
+	}
+	return &client.Service{Endpoint: options.Endpoint, Header: header, TenantHeader: tenantHeader}
+}
+
 type RouterOptions struct {
-	EndpointsPrefix string `doc:"mount endpoints at a prefix" default:"/api"`
+	EndpointsPrefix      string `doc:"mount endpoints at a prefix"                            default:"/api"`
+	RequirePreconditions bool   `doc:"require If-Match on contact updates and deletes"`
+	TenantHeader         string `doc:"read the tenant from a request header, e.g. X-Tenant-Id"`
+	TenantDomain         string `doc:"read the tenant from the subdomains of a domain"`
+	MetricsTenants       string `doc:"comma-separated tenants labeled in metrics besides those of principals"`
+
+	IdempotencyTTL time.Duration `doc:"time the responses to requests with an Idempotency-Key are replayed" default:"24h"`
+
+	EventsLogSize   int           `doc:"contact events retained for event streams to resume"  default:"1000"`
+	EventsHeartbeat time.Duration `doc:"time between the heartbeats of event streams"         default:"15s"`
+}
+
+// NewEventLog returns the [wrappers.EventLog] streaming the events of contacts, to close on shutdown.
+func NewEventLog(options *RouterOptions) *wrappers.EventLog {
+	return &wrappers.EventLog{Size: options.EventsLogSize}
+}
+
+// metricsTenants returns the tenants of the options labeled in metrics.
+func metricsTenants(options *RouterOptions) []string {
+	var tenants []string
+	for tenant := range strings.SplitSeq(options.MetricsTenants, ",") {
+		if tenant = strings.TrimSpace(tenant); tenant != "" {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants
+}
+
+// optTenant returns the [huma.API] option scoping requests to their tenant, that of the principal first.
+func optTenant(options *RouterOptions) func(huma.API) {
+	resolvers := []func(huma.Context) string{router.TenantFromPrincipal}
+	if options.TenantHeader != "" {
+		resolvers = append(resolvers, router.TenantFromHeader(options.TenantHeader))
+	}
+	if options.TenantDomain != "" {
+		resolvers = append(resolvers, router.TenantFromSubdomain(options.TenantDomain))
+	}
+
+	return func(api huma.API) {
+		api.UseMiddleware(
+			router.TenantMiddleware(api, resolvers...),
+			func(ctx huma.Context, next func(huma.Context)) {
+				tenant := domain.TenantID(router.TenantFrom(ctx.Context()))
+				next(huma.WithContext(ctx, domain.WithTenant(ctx.Context(), tenant)))
+			},
+		)
+	}
 }
 
 func NewRouter(
@@ -45,7 +275,17 @@ func NewRouter(
 	revision string,
 	created string,
 	logger *slog.Logger,
+	store domain.Store,
+	webhooksStore webhooks.Store,
+	events *wrappers.EventLog,
+	health *router.Health,
+	tracerProvider trace.TracerProvider,
+	auth *Auth,
 ) http.Handler {
+	if checker, ok := store.(interface{ Check(context.Context) error }); ok {
+		health.Register(router.HealthCheck{Name: "store", Check: checker.Check, Critical: true})
+	}
+
 	buildinfoMetric := joinQuote("build_info{goversion=", runtime.Version(),
 		",title=", title,
 		",version=", version,
@@ -53,56 +293,171 @@ func NewRouter(
 		",created=", created,
 		"} 1\n")
 	metriks := metrics.NewSet()
+	tracer := tracerProvider.Tracer("github.com/rlibaert/service-example-go")
 	return router.New(title, version,
-		func(_ http.ResponseWriter, _ *http.Request) {},
+		health.ServeHTTP,
 		func(w http.ResponseWriter, _ *http.Request) {
 			fmt.Fprint(w, buildinfoMetric)
 			metriks.WritePrometheus(w)
 			metrics.WriteProcessMetrics(w)
 		},
 		router.OptUseMiddleware(
+			router.TracingMiddleware(tracer, propagation.NewCompositeTextMapPropagator(
+				propagation.TraceContext{},
+				propagation.Baggage{},
+			)),
 			ctxlog{}.setMiddleware(logger),
 			router.RequestsLogMiddleware(func(ctx context.Context, r slog.Record) {
 				h := ctxlog{}.get(ctx).Handler()
 				h.Handle(ctx, r) //nolint: errcheck,gosec // ignored by [slog.Logger.Log] as well
 			}),
 			router.RequestsMetricsMiddleware(metriks),
-			router.ResponsesMetricsMiddleware(metriks),
+			router.ResponsesMetricsMiddleware(metriks, metricsTenants(options)...),
 			router.RecoverMiddleware(func(ctx context.Context, a any) {
 				ctxlog{}.get(ctx).LogAttrs(ctx, slog.LevelError, "panic occurred", slog.Any("recovered", a))
 			}),
 		),
-		router.OptGroup(options.EndpointsPrefix,
-			router.OptAutoRegister(&restapi.ServiceRegisterer{
-				Service: wrappers.ServiceErrorHandler{
-					Service: &domain.ServiceStore{
-						Store: stores.MustNewMock(&domain.Contact{
-							Firstname: "john",
-							Lastname:  "smith",
-							Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
-						}),
-					},
-					ErrorHandler: func(ctx context.Context, err error) {
-						ctxlog{}.get(ctx).
-							LogAttrs(context.Background(), slog.LevelError, "service error", slog.Any("err", err))
-					},
-				},
-			}),
-		),
+		endpoints(options, store, webhooksStore, events, tracer, auth),
+	)
+}
+
+// NewOpenAPI returns the OpenAPI document of the router selected by the options,
+// without opening any of its resources nor loading the keys of its authenticators.
+func NewOpenAPI(options *RouterOptions, authOptions *AuthOptions, title, version string) *huma.OpenAPI {
+	auth := &Auth{Authenticators: map[string]router.Authenticator{}}
+	if authOptions.JWKS != "" {
+		auth.Authenticators["bearer"] = router.BearerAuthenticator("JWT", nil)
+	}
+	if authOptions.APIKeysFile != "" {
+		auth.Authenticators["apikey"] = router.APIKeyAuthenticator("X-API-Key", nil)
+	}
+
+	store := stores.MustNewMock()
+	return router.OpenAPI(title, version,
+		endpoints(options, store, NewWebhooksStore(store), NewEventLog(options), noop.Tracer{}, auth))
+}
+
+// endpoints returns the [huma.API] option registering the endpoints under the prefix of the options.
+func endpoints(
+	options *RouterOptions,
+	store domain.Store,
+	webhooksStore webhooks.Store,
+	events *wrappers.EventLog,
+	tracer trace.Tracer,
+	auth *Auth,
+) func(huma.API) {
+	return router.OptGroup(options.EndpointsPrefix,
+		auth.opt(),
+		optTenant(options),
+		router.OptIdempotency(idempotencyStore(store), options.IdempotencyTTL),
+		router.OptAutoRegister(&restapi.ServiceRegisterer{
+			RequirePreconditions: options.RequirePreconditions,
+			EventsHeartbeat:      options.EventsHeartbeat,
+			Service:              NewService(store, events, tracer, auth),
+		}),
+		router.OptAutoRegister(&restapi.WebhooksRegisterer{Store: auth.wrapWebhooks(webhooksStore)}),
 	)
 }
 
+// NewGRPCServer returns the [grpc.Server] exposing the contacts on [ServerOptions.GRPCPort],
+// authenticating and scoping calls to their tenant like [NewRouter] does requests,
+// and the [health.Server] to fail on shutdown.
+func NewGRPCServer(
+	options *RouterOptions,
+	logger *slog.Logger,
+	store domain.Store,
+	events *wrappers.EventLog,
+	tracerProvider trace.TracerProvider,
+	auth *Auth,
+) (*grpc.Server, *health.Server) {
+	interceptors := []grpc.UnaryServerInterceptor{ctxlog{}.setInterceptor(logger)}
+	if len(auth.Authenticators) > 0 {
+		interceptors = append(interceptors, grpcapi.SkipHealth(grpcapi.AuthInterceptor(auth.Authenticators)))
+	}
+	interceptors = append(interceptors,
+		grpcapi.SkipHealth(grpcapi.TenantInterceptor(strings.ToLower(options.TenantHeader))))
+
+	tracer := tracerProvider.Tracer("github.com/rlibaert/service-example-go")
+	server, health := grpcapi.NewServer(NewService(store, events, tracer, auth),
+		grpc.ChainUnaryInterceptor(interceptors...))
+	health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	return server, health
+}
+
+// NewService returns the [domain.Service] over the store exposed by both the HTTP and gRPC APIs,
+// recording the history of contacts if the store keeps one and authorizing callers as they do.
+func NewService(store domain.Store, events *wrappers.EventLog, tracer trace.Tracer, auth *Auth) domain.Service {
+	var s domain.Store = wrappers.StoreTracer{Store: store, Tracer: tracer}
+	if _, ok := store.(domain.AuditStore); ok {
+		s = wrappers.StoreAuditor{
+			Store: s,
+			Actor: func(ctx context.Context) string {
+				p := router.PrincipalFrom(ctx)
+				if p == nil {
+					return ""
+				}
+				return p.Subject
+			},
+			RequestID: router.RequestIDFrom,
+		}
+	}
+	var service domain.Service = &domain.ServiceStore{Store: s, Events: events}
+	return wrappers.ServiceErrorHandler{
+		Service: auth.wrap(wrappers.ServiceTracer{
+			Service: service,
+			Tracer:  tracer,
+		}),
+		ErrorHandler: func(ctx context.Context, err error) {
+			ctxlog{}.get(ctx).
+				LogAttrs(context.Background(), slog.LevelError, "service error", slog.Any("err", err))
+		},
+	}
+}
+
+// idempotencyStore returns the store if it can keep idempotency keys, an in-memory one otherwise.
+func idempotencyStore(store domain.Store) router.IdempotencyStore {
+	if s, ok := store.(router.IdempotencyStore); ok {
+		return s
+	}
+	return &router.IdempotencyMemory{}
+}
+
 // ctxlog is a [context.Context] key and acts as a virtual package for operations related to it.
 type ctxlog struct{}
 
 func (key ctxlog) setMiddleware(parent *slog.Logger) func(huma.Context, func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		logger := parent.With("x-request-id", ctx.Header("X-Request-Id"))
+		if span := trace.SpanContextFromContext(ctx.Context()); span.IsValid() {
+			logger = logger.With("trace-id", span.TraceID().String())
+		}
 		ctx = huma.WithValue(ctx, key, logger)
 		next(ctx)
 	}
 }
 
+func (key ctxlog) setInterceptor(parent *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		logger := parent.With("grpc-method", info.FullMethod)
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			logger = logger.With("trace-id", span.TraceID().String())
+		}
+		resp, err := handler(context.WithValue(ctx, key, logger), req)
+		logger.LogAttrs(ctx, slog.LevelInfo, "call",
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return resp, err
+	}
+}
+
+// WithLogger returns a context carrying the logger of the calls of a [NewService] service made outside of
+// the APIs, e.g. by commands.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxlog{}, logger)
+}
+
 func (key ctxlog) get(ctx context.Context) *slog.Logger {
 	l, _ := ctx.Value(key).(*slog.Logger)
 	return l
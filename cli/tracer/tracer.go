@@ -0,0 +1,71 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// Options configure the export of traces.
+//
+// The otlp exporter is configured by the standard OTEL_EXPORTER_OTLP_* environment variables,
+// e.g. OTEL_EXPORTER_OTLP_ENDPOINT.
+type Options struct {
+	Exporter string `doc:"export traces to none, otlp or stdout" default:"none"`
+	File     string `doc:"append stdout traces to file"`
+}
+
+// nopCloser is the [io.Closer] of outputs not to be closed.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// New returns a tracer provider exporting the spans of a service, to be shut down to flush them,
+// and the closer of the file they are exported to, to be closed once the provider is shut down.
+func New(ctx context.Context, options *Options, title, version string) (*sdktrace.TracerProvider, io.Closer, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(title),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	var closer io.Closer = nopCloser{}
+
+	switch strings.ToLower(options.Exporter) {
+	case "", "none":
+	case "otlp":
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case "stdout":
+		var output io.Writer = os.Stdout
+		if options.File != "" && options.File != "-" {
+			file, err := os.OpenFile(options.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			if err != nil {
+				return nil, nil, err
+			}
+			output, closer = file, file
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(output))
+		if err != nil {
+			return nil, nil, errors.Join(err, closer.Close())
+		}
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+	default:
+		return nil, nil, errors.New("could not parse tracer exporter")
+	}
+
+	return sdktrace.NewTracerProvider(opts...), closer, nil
+}
@@ -0,0 +1,274 @@
+// Package client provides a [domain.Service] implementation calling the REST API exposed by [restapi].
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/router"
+)
+
+// Service defaults.
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = 100 * time.Millisecond
+	DefaultMaxBackoff  = 5 * time.Second
+)
+
+// maxProblemBytes limits the size of the error responses read.
+const maxProblemBytes = 1 << 16
+
+// Service implements [domain.Service] by calling the endpoints registered by [restapi.ServiceRegisterer].
+//
+// Error responses are returned as [*StatusError] unwrapping to the [domain] error of their status, e.g.
+// [domain.ErrNotFound], along with a [domain.FieldError] per error detail.
+//
+// Calls failing with network errors or transient statuses are retried with an exponential backoff:
+// POST requests carry an Idempotency-Key for the server to replay their response rather than apply them twice,
+// and writes of contacts carry If-Match headers. Every request carries the X-Request-Id of the context,
+// see [router.RequestIDFrom], or else one generated per call.
+type Service struct {
+	Endpoint     string        // URL the endpoints are mounted at, e.g. http://localhost:8888/api
+	Client       *http.Client  // defaults to [http.DefaultClient]
+	Header       http.Header   // sent with every request, e.g. credentials
+	TenantHeader string        // carries the tenant of contexts, see [domain.TenantFrom], e.g. X-Tenant-Id
+	Timeout      time.Duration // of calls, retries included, see [DefaultTimeout]
+	MaxAttempts  int           // of calls, see [DefaultMaxAttempts]
+	Backoff      time.Duration // before the first retry, doubled on the others, see [DefaultBackoff]
+	MaxBackoff   time.Duration // see [DefaultMaxBackoff]
+}
+
+var _ domain.Service = (*Service)(nil)
+
+func (s *Service) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return s.Timeout
+}
+
+// request is an HTTP request of a call, sent as many times as attempted.
+type request struct {
+	method string
+	path   string // relative to the endpoint
+	query  url.Values
+	header http.Header
+	body   any // encoded as JSON unless nil
+}
+
+// call sends a request and decodes the JSON body of its response into out unless nil,
+// returning the response headers.
+func (s *Service) call(ctx context.Context, r *request, out any) (http.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	resp, err := s.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		err = json.NewDecoder(resp.Body).Decode(out)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: decoding response: %w", r.method, r.path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// send sends a request until it gets a response not worth retrying or runs out of attempts,
+// returning error responses as [*StatusError].
+func (s *Service) send(ctx context.Context, r *request) (*http.Response, error) {
+	var body []byte
+	header := http.Header{}
+	for name, values := range s.Header {
+		header[name] = values
+	}
+	for name, values := range r.header {
+		header[name] = values
+	}
+	if header.Get("Accept") == "" {
+		header.Set("Accept", "application/json")
+	}
+	if r.body != nil {
+		var err error
+		body, err = json.Marshal(r.body)
+		if err != nil {
+			return nil, err
+		}
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+	requestID := router.RequestIDFrom(ctx)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	header.Set("X-Request-Id", requestID)
+	if tenant := domain.TenantFrom(ctx); s.TenantHeader != "" && tenant != "" {
+		header.Set(s.TenantHeader, string(tenant))
+	}
+	if r.method == http.MethodPost {
+		header.Set("Idempotency-Key", uuid.NewString())
+	}
+
+	u := strings.TrimSuffix(s.Endpoint, "/") + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, r.method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = header.Clone()
+
+		resp, err := client.Do(req)
+		if attempt < maxAttempts && retryable(ctx, resp, err) {
+			timer := time.NewTimer(s.backoff(attempt, resp))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+				if resp != nil {
+					drain(resp)
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			defer resp.Body.Close()
+			return nil, newStatusError(resp)
+		}
+		return resp, nil
+	}
+}
+
+// drain drains and closes the body of a response not read, for its connection to be reused.
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxProblemBytes)) //nolint: errcheck,gosec // drains for reuse only
+	resp.Body.Close()
+}
+
+// retryable reports whether an attempt failed transiently: with a network error, or a status telling the
+// request was not applied, e.g. [http.StatusServiceUnavailable], or that its Idempotency-Key is in use.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusConflict, http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented:
+		return false
+	default:
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// backoff returns the delay before retrying a call attempted a number of times,
+// that of the Retry-After header of the response if any.
+func (s *Service) backoff(attempts int, resp *http.Response) time.Duration {
+	backoff, maxBackoff := s.Backoff, s.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+	for range attempts - 1 {
+		if backoff >= maxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// StatusError is an error response, described by an RFC 9457 problem details document if the server sent one.
+type StatusError struct {
+	Status  int
+	Problem huma.ErrorModel
+
+	errs []error // domain error of the status and field errors of the problem
+}
+
+// statusErrors maps HTTP response statuses to [domain] errors.
+var statusErrors = map[int]error{ //nolint: gochecknoglobals // read-only table
+	http.StatusBadRequest:          domain.ErrInvalid,
+	http.StatusForbidden:           domain.ErrForbidden,
+	http.StatusNotFound:            domain.ErrNotFound,
+	http.StatusPreconditionFailed:  domain.ErrConflict,
+	http.StatusUnprocessableEntity: domain.ErrInvalid,
+	http.StatusFailedDependency:    domain.ErrAborted,
+	http.StatusGone:                domain.ErrEventsLost,
+	http.StatusNotImplemented:      errors.ErrUnsupported,
+}
+
+// newStatusError returns the [*StatusError] of a response.
+func newStatusError(resp *http.Response) *StatusError {
+	var problem huma.ErrorModel
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxProblemBytes))
+	_ = json.Unmarshal(b, &problem) // the status says enough if the body is not a problem
+	problem.Status = resp.StatusCode
+	return problemError(&problem, "")
+}
+
+// problemError returns the [*StatusError] of a problem details document, its details located relative to a prefix,
+// e.g. "body.actions[0].contact.", or else to the request part they are in.
+func problemError(problem *huma.ErrorModel, prefix string) *StatusError {
+	e := &StatusError{Status: problem.Status, Problem: *problem}
+	if err, ok := statusErrors[problem.Status]; ok {
+		e.errs = append(e.errs, err)
+	}
+	for _, d := range problem.Errors {
+		field, ok := strings.CutPrefix(d.Location, prefix)
+		if !ok || prefix == "" {
+			_, field, _ = strings.Cut(d.Location, ".")
+		}
+		e.errs = append(e.errs, &domain.FieldError{Field: field, Value: d.Value, Err: errors.New(d.Message)})
+	}
+	return e
+}
+
+func (e *StatusError) Error() string {
+	if e.Problem.Detail != "" {
+		return e.Problem.Detail
+	}
+	return strconv.Itoa(e.Status) + " " + http.StatusText(e.Status)
+}
+
+func (e *StatusError) Unwrap() []error { return e.errs }
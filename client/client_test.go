@@ -0,0 +1,209 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/rlibaert/service-example-go/cli/api"
+	"github.com/rlibaert/service-example-go/client"
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/restapi"
+	"github.com/rlibaert/service-example-go/router"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+// serve returns a [client.Service] calling a server of the REST API over a mock store.
+func serve(t *testing.T) *client.Service {
+	t.Helper()
+	store := stores.MustNewMock()
+	events := &wrappers.EventLog{}
+	handler := api.NewRouter(&api.RouterOptions{
+		EndpointsPrefix: "/api",
+		TenantHeader:    "X-Tenant-Id",
+		IdempotencyTTL:  time.Hour,
+	}, "test", "", "", "", slog.New(slog.DiscardHandler),
+		store, api.NewWebhooksStore(store), events, &router.Health{}, noop.NewTracerProvider(), &api.Auth{})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Cleanup(events.Close) // ends event streams before closing the server
+	return &client.Service{Endpoint: server.URL + "/api", Client: server.Client(), TenantHeader: "X-Tenant-Id"}
+}
+
+func TestService(t *testing.T) {
+	// birthdays are dates to the REST API
+	domaintest.TestService(t, serve(t), domaintest.OptSkip(domaintest.TimeZones))
+}
+
+func TestErrors(t *testing.T) {
+	service := serve(t)
+
+	c := domaintest.NewContact()
+	c.Phones[0].Number = "not a number"
+	_, err := service.ContactsCreate(t.Context(), c)
+	var se *client.StatusError
+	var fe *domain.FieldError
+	if !errors.Is(err, domain.ErrInvalid) || !errors.As(err, &se) || se.Status != http.StatusUnprocessableEntity {
+		t.Error("ContactsCreate of an invalid contact returned", err)
+	}
+	if !errors.As(err, &fe) || fe.Field != "phones[0].number" {
+		t.Error("ContactsCreate of an invalid contact returned field error", fe)
+	}
+
+	_, err = service.ContactsList(t.Context(), &domain.ContactsQuery{Limit: domain.ContactsListMaxLimit + 1})
+	if !errors.Is(err, domain.ErrInvalid) || !errors.As(err, &fe) || fe.Field != "limit" {
+		t.Error("ContactsList over the limit returned", err, fe)
+	}
+
+	id, err := service.ContactsCreate(t.Context(), domaintest.NewContact())
+	if err != nil {
+		t.Fatal("ContactsCreate:", err)
+	}
+	err = service.ContactsDelete(t.Context(), id, 2)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Error("ContactsDelete with a stale version returned", err)
+	}
+	page, err := service.ContactsHistory(t.Context(), &domain.AuditQuery{ContactID: id})
+	if err != nil || len(page.Events) != 1 || page.Events[0].Action != domain.AuditCreate {
+		t.Error("ContactsHistory returned", page, err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	service := serve(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	events, err := service.ContactsEvents(ctx, &domain.EventsQuery{})
+	if err != nil {
+		t.Fatal("ContactsEvents:", err)
+	}
+	c := domaintest.NewContact()
+	id, err := service.ContactsCreate(t.Context(), c)
+	if err != nil {
+		t.Fatal("ContactsCreate:", err)
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != domain.ContactCreated || e.ContactID != id || e.Contact == nil ||
+			e.Contact.Firstname != c.Firstname {
+			t.Errorf("got event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("got no event")
+	}
+
+	contact, err := service.ContactsEvents(ctx, &domain.EventsQuery{ContactID: id})
+	if err != nil {
+		t.Fatal("ContactsEvents:", err)
+	}
+	if _, err := service.ContactsCreate(t.Context(), domaintest.NewContact()); err != nil {
+		t.Fatal("ContactsCreate:", err)
+	}
+	if err := service.ContactsDelete(t.Context(), id, 0); err != nil {
+		t.Fatal("ContactsDelete:", err)
+	}
+	select {
+	case e := <-contact:
+		if e.Type != domain.ContactDeleted || e.ContactID != id {
+			t.Errorf("got event %+v of the contact", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("got no event of the contact")
+	}
+	_, err = service.ContactsEvents(ctx, &domain.EventsQuery{LastID: "unknown"})
+	if !errors.Is(err, domain.ErrEventsLost) {
+		t.Error("ContactsEvents after an unknown event got", err, "want", domain.ErrEventsLost)
+	}
+
+	cancel()
+	for range events { //nolint: revive // waits for the channel to be closed
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var (
+		attempts   atomic.Int32
+		requestIDs = map[string]bool{}
+		keys       = map[string]bool{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs[r.Header.Get("X-Request-Id")] = true
+		keys[r.Header.Get("Idempotency-Key")] = true
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": uuid.NewString()}) //nolint: errcheck,errchkjson // test
+	}))
+	t.Cleanup(server.Close)
+
+	service := &client.Service{Endpoint: server.URL, Backoff: time.Millisecond}
+	_, err := service.ContactsCreate(t.Context(), domaintest.NewContact())
+	if err != nil || attempts.Load() != 3 {
+		t.Error("ContactsCreate returned", err, "after", attempts.Load(), "attempts, want 3")
+	}
+	if len(requestIDs) != 1 || len(keys) != 1 || requestIDs[""] || keys[""] {
+		t.Error("attempts carried request IDs", requestIDs, "and idempotency keys", keys, "want one of each")
+	}
+
+	attempts.Store(0)
+	service.MaxAttempts = 2
+	_, err = service.ContactsCreate(t.Context(), domaintest.NewContact())
+	var se *client.StatusError
+	if !errors.As(err, &se) || se.Status != http.StatusServiceUnavailable || attempts.Load() != 2 {
+		t.Error("ContactsCreate returned", err, "after", attempts.Load(), "attempts, want a 503 after 2")
+	}
+}
+
+func TestETag(t *testing.T) {
+	c := restapi.NewContactModel(domaintest.NewContact())
+	var tag string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if tag != "" {
+			w.Header().Set("ETag", tag)
+		}
+		json.NewEncoder(w).Encode(c) //nolint: errcheck,errchkjson // test
+	}))
+	t.Cleanup(server.Close)
+
+	service := &client.Service{Endpoint: server.URL}
+	for _, tag = range []string{"", `"0"`, `W/"3"`, "3"} {
+		if _, err := service.ContactsRead(t.Context(), c.ID); err == nil {
+			t.Errorf("ContactsRead succeeded with ETag %q", tag)
+		}
+	}
+	tag = `"3"`
+	if got, err := service.ContactsRead(t.Context(), c.ID); err != nil || got.Version != 3 {
+		t.Error("ContactsRead returned", got, err, "want version 3")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	service := &client.Service{Endpoint: server.URL, Timeout: 10 * time.Millisecond}
+	_, err := service.ContactsRead(t.Context(), domain.ContactID{UUID: uuid.New()})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("ContactsRead of a hanging server returned", err)
+	}
+	_, err = service.ContactsEvents(t.Context(), &domain.EventsQuery{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("ContactsEvents of a hanging server returned", err)
+	}
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/restapi"
+)
+
+func contactPath(id domain.ContactID) string { return "/contacts/" + id.String() }
+
+// ifMatch returns the headers conditioning a write on a [domain.Contact] version, none if zero.
+func ifMatch(version int) http.Header {
+	if version == 0 {
+		return nil
+	}
+	return http.Header{"If-Match": {etag(version)}}
+}
+
+func (s *Service) ContactsCreate(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	var out restapi.ContactIDModel
+	_, err := s.call(ctx, &request{method: http.MethodPost, path: "/contacts", body: contactModel(c)}, &out)
+	return out.ID, err
+}
+
+func (s *Service) ContactsRead(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	var out restapi.ContactModel
+	header, err := s.call(ctx, &request{method: http.MethodGet, path: contactPath(id)}, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := contact(&out)
+	if err != nil {
+		return nil, err
+	}
+	c.Version, err = version(header.Get("ETag"))
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Service) ContactsUpdate(ctx context.Context, id domain.ContactID, c *domain.Contact) (int, error) {
+	header, err := s.call(ctx, &request{
+		method: http.MethodPut,
+		path:   contactPath(id),
+		header: ifMatch(c.Version),
+		body:   contactModel(c),
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return version(header.Get("ETag"))
+}
+
+func (s *Service) ContactsDelete(ctx context.Context, id domain.ContactID, version int) error {
+	_, err := s.call(ctx, &request{method: http.MethodDelete, path: contactPath(id), header: ifMatch(version)}, nil)
+	return err
+}
+
+func (s *Service) ContactsRestore(ctx context.Context, id domain.ContactID) error {
+	_, err := s.call(ctx, &request{method: http.MethodPost, path: "/contacts/trash/" + id.String() + "/restore"}, nil)
+	return err
+}
+
+func (s *Service) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	_, err := s.call(ctx, &request{method: http.MethodDelete, path: "/contacts/trash/" + id.String()}, nil)
+	return err
+}
+
+// ContactsPatch sends the patch as a merge patch, conditioned on its version unless zero.
+func (s *Service) ContactsPatch(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	header := http.Header{"Content-Type": {"application/merge-patch+json"}}
+	if p.Version != 0 {
+		header.Set("If-Match", etag(p.Version))
+	}
+
+	var out restapi.ContactModel
+	header, err := s.call(ctx, &request{
+		method: http.MethodPatch,
+		path:   contactPath(id),
+		header: header,
+		body:   restapi.NewMergePatch(p),
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := contact(&out)
+	if err != nil {
+		return nil, err
+	}
+	c.Version, err = version(header.Get("ETag"))
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Service) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	r := &request{method: http.MethodGet, path: "/contacts", query: url.Values{}}
+	if q.Trashed {
+		r.path = "/contacts/trash"
+	}
+	for name, value := range map[string]string{
+		"sort":   string(q.Sort),
+		"name":   q.NamePrefix,
+		"cursor": q.Cursor,
+	} {
+		if value != "" {
+			r.query.Set(name, value)
+		}
+	}
+	if q.Limit != 0 {
+		r.query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if !q.BornAfter.IsZero() {
+		r.query.Set("born_after", q.BornAfter.Format(time.DateOnly))
+	}
+	if !q.BornBefore.IsZero() {
+		r.query.Set("born_before", q.BornBefore.Format(time.DateOnly))
+	}
+
+	var out restapi.TrashPageModel
+	_, err := s.call(ctx, r, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ContactsPage{Contacts: make([]*domain.Contact, len(out.Items)), Next: out.Next}
+	for n, m := range out.Items {
+		page.Contacts[n], err = contact(&m.ContactModel)
+		if err != nil {
+			return nil, err
+		}
+		page.Contacts[n].DeletedAt = m.DeletedAt
+	}
+	return page, nil
+}
+
+func (s *Service) ContactsBatch(ctx context.Context, actions []domain.BatchAction) ([]domain.BatchResult, error) {
+	in := restapi.BatchInputModel{Actions: make([]restapi.BatchActionModel, len(actions))}
+	for n, a := range actions {
+		m := restapi.BatchActionModel{Op: string(a.Op), Version: a.Version}
+		if a.Op != domain.BatchCreate {
+			m.ID = &a.ID
+		}
+		if a.Contact != nil {
+			m.Contact = contactModel(a.Contact)
+			if a.Op == domain.BatchUpdate {
+				m.Version = a.Contact.Version
+			}
+		}
+		in.Actions[n] = m
+	}
+
+	var out restapi.BatchReportModel
+	_, err := s.call(ctx, &request{method: http.MethodPost, path: "/contacts:batch", body: in}, &out)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Items) != len(actions) {
+		return nil, fmt.Errorf("got %d batch results, want %d", len(out.Items), len(actions))
+	}
+
+	results := make([]domain.BatchResult, len(out.Items))
+	for n, item := range out.Items {
+		if item.ID != nil {
+			results[n].ID = *item.ID
+		}
+		if item.Status < 200 || item.Status > 299 {
+			results[n].Err = problemError(&huma.ErrorModel{
+				Status: item.Status,
+				Detail: item.Detail,
+				Errors: item.Errors,
+			}, fmt.Sprintf("body.actions[%d].contact.", n))
+		}
+	}
+	return results, nil
+}
+
+func (s *Service) ContactsHistory(ctx context.Context, q *domain.AuditQuery) (*domain.AuditPage, error) {
+	r := &request{method: http.MethodGet, path: contactPath(q.ContactID) + "/history", query: url.Values{}}
+	if q.Limit != 0 {
+		r.query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Cursor != "" {
+		r.query.Set("cursor", q.Cursor)
+	}
+
+	var out restapi.HistoryPageModel
+	_, err := s.call(ctx, r, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.AuditPage{Events: make([]*domain.AuditEvent, len(out.Items)), Next: out.Next}
+	for n, m := range out.Items {
+		page.Events[n] = &domain.AuditEvent{
+			ContactID: q.ContactID,
+			Action:    domain.AuditAction(m.Action),
+			Actor:     m.Actor,
+			RequestID: m.RequestID,
+			Time:      m.Time,
+			Changes: convert(m.Changes, func(c restapi.AuditChangeModel) domain.AuditChange {
+				return domain.AuditChange(c)
+			}),
+		}
+	}
+	return page, nil
+}
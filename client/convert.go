@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/restapi"
+)
+
+// etag returns the entity tag of a [domain.Contact] version.
+func etag(version int) string { return `"` + strconv.Itoa(version) + `"` }
+
+// version returns the [domain.Contact] version of an entity tag.
+//
+// It fails if the tag is missing or malformed rather than returning zero, which would make writes conditioned on
+// the version unconditional.
+func version(tag string) (int, error) {
+	v, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || v <= 0 || etag(v) != tag {
+		return 0, fmt.Errorf("invalid ETag %q in response, want a contact version", tag)
+	}
+	return v, nil
+}
+
+// convert converts a slice element-wise, nil if empty.
+func convert[T, U any](s []T, f func(T) U) []U {
+	if len(s) == 0 {
+		return nil
+	}
+	out := make([]U, len(s))
+	for n, v := range s {
+		out[n] = f(v)
+	}
+	return out
+}
+
+func contactModel(c *domain.Contact) *restapi.ContactModel {
+	return &restapi.ContactModel{
+		Firstname: c.Firstname,
+		Lastname:  c.Lastname,
+		Birthday:  c.Birthday.Format(time.DateOnly),
+		Emails: convert(c.Emails, func(e domain.Email) restapi.EmailModel {
+			return restapi.EmailModel{Address: e.Address, Label: string(e.Label), Primary: e.Primary}
+		}),
+		Phones: convert(c.Phones, func(p domain.Phone) restapi.PhoneModel {
+			return restapi.PhoneModel{Number: p.Number, Label: string(p.Label), Primary: p.Primary}
+		}),
+		Addresses: convert(c.Addresses, func(a domain.Address) restapi.AddressModel {
+			return restapi.AddressModel{
+				Street:     a.Street,
+				Locality:   a.Locality,
+				Region:     a.Region,
+				PostalCode: a.PostalCode,
+				Country:    a.Country,
+				Label:      string(a.Label),
+				Primary:    a.Primary,
+			}
+		}),
+	}
+}
+
+// contact returns the [domain.Contact] described by a model.
+func contact(m *restapi.ContactModel) (*domain.Contact, error) {
+	birthday, err := time.Parse(time.DateOnly, m.Birthday)
+	if err != nil {
+		return nil, fmt.Errorf("contact %s: invalid birthday: %w", m.ID, err)
+	}
+
+	return &domain.Contact{
+		ID:        m.ID,
+		Firstname: m.Firstname,
+		Lastname:  m.Lastname,
+		Birthday:  birthday,
+		Emails: convert(m.Emails, func(e restapi.EmailModel) domain.Email {
+			return domain.Email{Address: e.Address, Label: domain.Label(e.Label), Primary: e.Primary}
+		}),
+		Phones: convert(m.Phones, func(p restapi.PhoneModel) domain.Phone {
+			return domain.Phone{Number: p.Number, Label: domain.Label(p.Label), Primary: p.Primary}
+		}),
+		Addresses: convert(m.Addresses, func(a restapi.AddressModel) domain.Address {
+			return domain.Address{
+				Street:     a.Street,
+				Locality:   a.Locality,
+				Region:     a.Region,
+				PostalCode: a.PostalCode,
+				Country:    a.Country,
+				Label:      domain.Label(a.Label),
+				Primary:    a.Primary,
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/restapi"
+)
+
+// maxEventBytes limits the size of the lines of event streams.
+const maxEventBytes = 1 << 20
+
+// ContactsEvents streams the events of contacts from their Server-Sent Events endpoint,
+// the timeout only applying until the stream starts.
+func (s *Service) ContactsEvents(ctx context.Context, q *domain.EventsQuery) (<-chan *domain.Event, error) {
+	r := &request{method: http.MethodGet, path: "/contacts/events", query: url.Values{}, header: http.Header{
+		"Accept": {restapi.EventStreamMediaType},
+	}}
+	if q.LastID != "" {
+		r.header.Set("Last-Event-Id", q.LastID)
+	}
+	if q.ContactID != (domain.ContactID{}) {
+		r.query.Set("contact_id", q.ContactID.String())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(s.timeout(), cancel)
+	resp, err := s.send(ctx, r)
+	if !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		err = context.DeadlineExceeded
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	events := make(chan *domain.Event)
+	go func() {
+		defer close(events)
+		defer cancel()
+		defer resp.Body.Close()
+
+		tenant := domain.TenantFrom(ctx)
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(nil, maxEventBytes)
+		var data bytes.Buffer
+		for scanner.Scan() {
+			field, value, _ := strings.Cut(scanner.Text(), ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "data":
+				data.WriteString(value)
+				data.WriteByte('\n')
+				continue
+			case "":
+				if scanner.Text() != "" || data.Len() == 0 {
+					continue // comments, e.g. heartbeats, or events without data
+				}
+			default:
+				continue // the ID is also that of the event of the data
+			}
+
+			var m restapi.EventModel
+			err := json.Unmarshal(data.Bytes(), &m)
+			data.Reset()
+			if err != nil {
+				return // the stream is corrupted, callers resume from the last event received
+			}
+			e := &domain.Event{
+				ID:        m.ID,
+				Type:      domain.EventType(m.Type),
+				Tenant:    tenant,
+				ContactID: m.ContactID,
+				Time:      m.Time,
+			}
+			if m.Contact != nil {
+				e.Contact, err = contact(m.Contact)
+				if err != nil {
+					return
+				}
+			}
+
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,100 @@
+// Package contactcsv encodes and decodes [domain.Contact] objects as CSV (RFC 4180) rows.
+//
+// Columns map to fields of a contact. Detail fields, i.e. email, phone and address fields,
+// may be suffixed by a label, e.g. "email:work", and address fields of a same label make up one address.
+// Cells starting with a formula character are prefixed by a quote to keep spreadsheets from evaluating them.
+package contactcsv
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// MediaType is the media type of CSV files.
+const MediaType = "text/csv"
+
+// Fields of a contact columns map to.
+const (
+	FieldID         = "id" // only encoded
+	FieldFirstname  = "firstname"
+	FieldLastname   = "lastname"
+	FieldBirthday   = "birthday"
+	FieldEmail      = "email"
+	FieldPhone      = "phone"
+	FieldStreet     = "street"
+	FieldLocality   = "locality"
+	FieldRegion     = "region"
+	FieldPostalCode = "postal_code"
+	FieldCountry    = "country"
+)
+
+// header is the header of encoded files, decoded by default.
+var header = []string{ //nolint: gochecknoglobals // read-only table
+	FieldID, FieldFirstname, FieldLastname, FieldBirthday, FieldEmail, FieldPhone,
+	FieldStreet, FieldLocality, FieldRegion, FieldPostalCode, FieldCountry,
+}
+
+// formulaPrefixes are the characters spreadsheets evaluate formulas from.
+const formulaPrefixes = "=+-@"
+
+// escape prefixes a cell starting with a formula character by a quote.
+func escape(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescape reverses [escape].
+func unescape(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// Mapping maps column headers to fields, e.g. "Work Email" to "email:work".
+type Mapping map[string]string
+
+// ParseMapping parses a [Mapping] from "header=field" pairs.
+func ParseMapping(pairs []string) (Mapping, error) {
+	m := Mapping{}
+	for _, pair := range pairs {
+		h, f, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("column mapping %q: missing '='", pair)
+		}
+		_, _, err := parseField(f)
+		if err != nil {
+			return nil, fmt.Errorf("column mapping %q: %w", pair, err)
+		}
+		m[strings.TrimSpace(h)] = strings.TrimSpace(f)
+	}
+	return m, nil
+}
+
+// parseField parses a field and its label, [domain.LabelOther] if none.
+func parseField(s string) (string, domain.Label, error) {
+	field, label, labeled := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+	switch field {
+	case FieldFirstname, FieldLastname, FieldBirthday:
+		if labeled {
+			return "", "", fmt.Errorf("field %s has no label", field)
+		}
+		return field, "", nil
+	case FieldEmail, FieldPhone, FieldStreet, FieldLocality, FieldRegion, FieldPostalCode, FieldCountry:
+		switch l := domain.Label(label); {
+		case !labeled:
+			return field, domain.LabelOther, nil
+		case slices.Contains([]domain.Label{domain.LabelHome, domain.LabelWork, domain.LabelOther}, l):
+			return field, l, nil
+		default:
+			return "", "", fmt.Errorf("unknown label %q", label)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown field %q", field)
+	}
+}
@@ -0,0 +1,124 @@
+package contactcsv_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/contactcsv"
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+func TestRoundTrip(t *testing.T) {
+	want := &domain.Contact{
+		Firstname: "=HYPERLINK(\"http://example.com\")",
+		Lastname:  "Smith, Jr",
+		Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		Emails:    []domain.Email{{Address: "john@example.com", Label: domain.LabelOther, Primary: true}},
+		Phones:    []domain.Phone{{Number: "+33123456789", Label: domain.LabelOther, Primary: true}},
+		Addresses: []domain.Address{{
+			Street:   "1 rue de Rivoli\nBâtiment B",
+			Locality: "Paris",
+			Country:  "FR",
+			Label:    domain.LabelOther,
+			Primary:  true,
+		}},
+	}
+
+	var b strings.Builder
+	enc := contactcsv.NewEncoder(&b)
+	c := want.Clone()
+	c.ID = domain.ContactID{UUID: uuid.New()}
+	if err := enc.Encode(c); err != nil {
+		t.Fatal("Encode:", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal("Flush:", err)
+	}
+	if !strings.Contains(b.String(), `"'=HYPERLINK(""http://example.com"")"`) {
+		t.Errorf("formula not escaped in\n%s", b.String())
+	}
+
+	d := contactcsv.NewDecoder(strings.NewReader(b.String()), nil)
+	got, err := d.Decode()
+	if err != nil {
+		t.Fatal("Decode:", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v\nfrom\n%s", got, want, b.String())
+	}
+	if _, err := d.Decode(); !errors.Is(err, io.EOF) {
+		t.Error("Decode after the last row got", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	mapping, err := contactcsv.ParseMapping([]string{
+		"First Name=firstname",
+		"Last Name=lastname",
+		"Birthday=birthday",
+		"Work Email=email:work",
+		"Home City=locality:home",
+		"Home Country=country:home",
+	})
+	if err != nil {
+		t.Fatal("ParseMapping:", err)
+	}
+
+	const file = "\ufeffFirst Name,Last Name,Notes,Birthday,Work Email,Home City,Home Country\n" +
+		"John,Smith,ignored,1999-12-31,john@example.com,Paris,FR\n" +
+		"Jane,Doe,,31/12/1999,,,\n" +
+		"Bob\n"
+
+	d := contactcsv.NewDecoder(strings.NewReader(file), mapping)
+	got, err := d.Decode()
+	want := &domain.Contact{
+		Firstname: "John",
+		Lastname:  "Smith",
+		Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		Emails:    []domain.Email{{Address: "john@example.com", Label: domain.LabelWork, Primary: true}},
+		Addresses: []domain.Address{{Locality: "Paris", Country: "FR", Label: domain.LabelHome, Primary: true}},
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("row 1 got %+v, %v, want %+v", got, err, want)
+	}
+
+	_, err = d.Decode()
+	var cerr *contactcsv.Error
+	if !errors.As(err, &cerr) || cerr.Row != 2 || cerr.Line != 3 || cerr.Column != "Birthday" {
+		t.Errorf("row 2 got %v, want an error in column Birthday at line 3", err)
+	}
+
+	got, err = d.Decode()
+	if err != nil || !reflect.DeepEqual(got, &domain.Contact{Firstname: "Bob"}) {
+		t.Errorf("row 3 got %+v, %v", got, err)
+	}
+
+	if _, err := d.Decode(); !errors.Is(err, io.EOF) {
+		t.Error("Decode after the last row got", err)
+	}
+}
+
+func TestMappingErrors(t *testing.T) {
+	for _, pairs := range [][]string{
+		{"Name"},
+		{"Name=nickname"},
+		{"Name=firstname:work"},
+		{"Email=email:mobile"},
+	} {
+		if _, err := contactcsv.ParseMapping(pairs); err == nil {
+			t.Error("ParseMapping", pairs, "succeeded")
+		}
+	}
+
+	mapping := contactcsv.Mapping{"Surname": "lastname"}
+	_, err := contactcsv.NewDecoder(strings.NewReader("Name\nsmith\n"), mapping).Decode()
+	if err == nil || errors.As(err, new(*contactcsv.Error)) {
+		t.Error("decoding without a mapped column got", err)
+	}
+}
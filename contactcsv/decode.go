@@ -0,0 +1,181 @@
+package contactcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Error is an invalid row. Decoding may continue with the next row.
+type Error struct {
+	Row    int    // 1-based index of the row, header excluded
+	Line   int    // line the row starts at
+	Column string // header of the invalid column, if any
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("contactcsv: row %d, column %q: %v", e.Row, e.Column, e.Err)
+	}
+	return fmt.Sprintf("contactcsv: row %d: %v", e.Row, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// column is a column mapped to a field.
+type column struct {
+	header string
+	field  string
+	label  domain.Label
+}
+
+// Decoder reads contacts from CSV rows, one at a time, without holding more than a row in memory.
+//
+// The first row is the header. Columns map to fields through a [Mapping], or by their header
+// if none, unmapped columns being ignored. Empty cells are ignored.
+type Decoder struct {
+	r       *csv.Reader
+	mapping Mapping
+	columns []*column // by index, nil if unmapped
+	row     int
+}
+
+// NewDecoder returns a [Decoder] reading from r, mapping columns with a [Mapping] if not nil.
+func NewDecoder(r io.Reader, mapping Mapping) *Decoder {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+	return &Decoder{r: cr, mapping: mapping}
+}
+
+// Decode reads the next row. It returns [io.EOF] at the end of the stream and an [*Error] for invalid rows,
+// any other error, e.g. a header lacking a mapped column, ends decoding.
+//
+// Decoded contacts are neither normalized nor validated,
+// the first detail of each kind being primary.
+func (d *Decoder) Decode() (*domain.Contact, error) {
+	if d.columns == nil {
+		err := d.readHeader()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := d.r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	d.row++
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		return nil, &Error{Row: d.row, Line: perr.StartLine, Err: perr.Err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	line, _ := d.r.FieldPos(0)
+
+	var (
+		c         domain.Contact
+		addresses = map[domain.Label]int{} // indexes by label
+	)
+	for n, value := range record {
+		if n >= len(d.columns) || d.columns[n] == nil {
+			continue
+		}
+		col := d.columns[n]
+		value = unescape(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		switch col.field {
+		case FieldFirstname:
+			c.Firstname = value
+		case FieldLastname:
+			c.Lastname = value
+		case FieldBirthday:
+			c.Birthday, err = time.Parse(time.DateOnly, value)
+			if err != nil {
+				return nil, &Error{Row: d.row, Line: line, Column: col.header,
+					Err: fmt.Errorf("invalid birthday %q, want YYYY-MM-DD", value)}
+			}
+		case FieldEmail:
+			c.Emails = append(c.Emails, domain.Email{Address: value, Label: col.label, Primary: len(c.Emails) == 0})
+		case FieldPhone:
+			c.Phones = append(c.Phones, domain.Phone{Number: value, Label: col.label, Primary: len(c.Phones) == 0})
+		default:
+			i, ok := addresses[col.label]
+			if !ok {
+				i = len(c.Addresses)
+				addresses[col.label] = i
+				c.Addresses = append(c.Addresses, domain.Address{Label: col.label, Primary: i == 0})
+			}
+			a := &c.Addresses[i]
+			switch col.field {
+			case FieldStreet:
+				a.Street = value
+			case FieldLocality:
+				a.Locality = value
+			case FieldRegion:
+				a.Region = value
+			case FieldPostalCode:
+				a.PostalCode = value
+			case FieldCountry:
+				a.Country = value
+			}
+		}
+	}
+	return &c, nil
+}
+
+// readHeader reads the header and maps its columns.
+func (d *Decoder) readHeader() error {
+	record, err := d.r.Read()
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+
+	d.columns = make([]*column, len(record))
+	found := map[string]bool{}
+	for n, h := range record {
+		if n == 0 {
+			h = strings.TrimPrefix(h, "\ufeff") // byte order mark of spreadsheet exports
+		}
+		h = strings.TrimSpace(h)
+
+		f, ok := d.mapping[h]
+		if d.mapping == nil {
+			f, ok = h, true
+		}
+		if !ok {
+			continue
+		}
+		field, label, err := parseField(f)
+		if err != nil && d.mapping == nil {
+			continue // e.g. the id column
+		}
+		if err != nil {
+			return fmt.Errorf("column %q: %w", h, err)
+		}
+		d.columns[n] = &column{header: h, field: field, label: label}
+		found[h] = true
+	}
+
+	for h := range d.mapping {
+		if !found[h] {
+			return fmt.Errorf("missing column %q", h)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package contactcsv
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Encoder writes contacts as CSV rows, after a header naming their fields.
+//
+// Rows hold the primary detail of each kind, or the first one if none is primary.
+type Encoder struct {
+	w      *csv.Writer
+	headed bool
+}
+
+// NewEncoder returns an [Encoder] writing to w. Rows are buffered until [Encoder.Flush].
+func NewEncoder(w io.Writer) *Encoder { return &Encoder{w: csv.NewWriter(w)} }
+
+// Encode writes a [domain.Contact] as a row, after the header if first.
+func (e *Encoder) Encode(c *domain.Contact) error {
+	if !e.headed {
+		err := e.w.Write(header)
+		if err != nil {
+			return err
+		}
+		e.headed = true
+	}
+
+	var (
+		email   = primary(c.Emails, func(e domain.Email) bool { return e.Primary })
+		phone   = primary(c.Phones, func(p domain.Phone) bool { return p.Primary })
+		address = primary(c.Addresses, func(a domain.Address) bool { return a.Primary })
+		id      string
+	)
+	if c.ID != (domain.ContactID{}) {
+		id = c.ID.String()
+	}
+	var birthday string
+	if !c.Birthday.IsZero() {
+		birthday = c.Birthday.Format(time.DateOnly)
+	}
+	return e.w.Write([]string{
+		id,
+		escape(c.Firstname),
+		escape(c.Lastname),
+		birthday,
+		escape(email.Address),
+		escape(phone.Number),
+		escape(address.Street),
+		escape(address.Locality),
+		escape(address.Region),
+		escape(address.PostalCode),
+		escape(address.Country),
+	})
+}
+
+// Flush writes the buffered rows.
+func (e *Encoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// primary returns the primary item, or the first one if none is primary, zero if empty.
+func primary[T any](items []T, isPrimary func(T) bool) T {
+	var zero T
+	for _, item := range items {
+		if isPrimary(item) {
+			return item
+		}
+	}
+	if len(items) > 0 {
+		return items[0]
+	}
+	return zero
+}
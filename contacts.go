@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/danielgtaylor/huma/v2/yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/rlibaert/service-example-go/cli/api"
+	"github.com/rlibaert/service-example-go/cli/logger"
+	"github.com/rlibaert/service-example-go/client"
+	"github.com/rlibaert/service-example-go/contactcsv"
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/restapi"
+	"github.com/rlibaert/service-example-go/vcard"
+)
+
+// Exit codes of the contacts commands, for scripts to tell failures apart.
+const (
+	exitFailure  = 1
+	exitNotFound = 3
+	exitInvalid  = 4
+	exitConflict = 5
+)
+
+// contactsFlags are the flags shared by the contacts commands, along with the exit code of the one run.
+type contactsFlags struct {
+	output string
+	tenant string
+	code   int
+}
+
+// contactOutput is a contact as printed and read by the contacts commands, along with its version.
+type contactOutput struct {
+	restapi.ContactModel
+
+	Version int `json:"version,omitempty"`
+}
+
+// contactsCommand returns the command grouping the commands managing the contacts of a running instance,
+// setting the exit code of the flags.
+func contactsCommand(flags *contactsFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contacts",
+		Short: "Manage the contacts of a running instance over its API",
+		Long: "Manage the contacts of the running instance at --endpoint over its REST API.\n\n" +
+			"Commands exit with 3 if a contact is not found, 4 if a contact or a query is invalid, " +
+			"5 on version conflicts and 1 on other failures.",
+	}
+	cmd.PersistentFlags().StringVarP(&flags.output, "output", "o", "table", "output format: table, json or yaml")
+	cmd.PersistentFlags().StringVar(&flags.tenant, "tenant", "", "tenant of the contacts")
+	cmd.AddCommand(
+		contactsCreateCommand(flags),
+		contactsGetCommand(flags),
+		contactsUpdateCommand(flags),
+		contactsDeleteCommand(flags),
+		contactsListCommand(flags),
+		contactsImportCommand(flags),
+		contactsExportCommand(flags),
+	)
+	return cmd
+}
+
+// contactsFunc is the function of a contacts command, calling a running instance.
+type contactsFunc func(ctx context.Context, cmd *cobra.Command, args []string, service *client.Service) error
+
+// run returns a cobra run function calling a running instance with f, and setting the exit code of the flags.
+func (flags *contactsFlags) run(f contactsFunc) func(*cobra.Command, []string) {
+	return humacli.WithOptions(func(cmd *cobra.Command, args []string, options *Options) {
+		flags.code = flags.call(cmd, args, options, f)
+	})
+}
+
+// call calls a running instance with f, and returns the exit code of its error if any.
+func (flags *contactsFlags) call(cmd *cobra.Command, args []string, options *Options, f contactsFunc) int {
+	logger := logger.New(&options.Logger)
+	switch flags.output {
+	case "table", "json", "yaml":
+	default:
+		logger.Error("unknown output format, want table, json or yaml", "output", flags.output)
+		return exitFailure
+	}
+
+	tenantHeader := options.TenantHeader
+	if tenantHeader == "" {
+		tenantHeader = "X-Tenant-Id"
+	}
+	service := api.NewClient(&options.ClientOptions, tenantHeader)
+	ctx := domain.WithTenant(cmd.Context(), domain.TenantID(flags.tenant))
+
+	err := f(ctx, cmd, args, service)
+	if err != nil {
+		return fail(logger, err)
+	}
+	return 0
+}
+
+// fail logs an error along with the details of its problem, and returns its exit code.
+func fail(logger *slog.Logger, err error) int {
+	attrs := []any{"err", err}
+	var se *client.StatusError
+	if errors.As(err, &se) {
+		attrs = append(attrs, "status", se.Status)
+		for _, d := range se.Problem.Errors {
+			attrs = append(attrs, slog.Group("error", "location", d.Location, "message", d.Message, "value", d.Value))
+		}
+	}
+	logger.Error("contacts command failed", attrs...)
+
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, domain.ErrInvalid):
+		return exitInvalid
+	case errors.Is(err, domain.ErrConflict):
+		return exitConflict
+	default:
+		return exitFailure
+	}
+}
+
+// print writes a value in the output format, using table for the table one.
+func (flags *contactsFlags) print(w io.Writer, v any, table func(w io.Writer)) error {
+	switch flags.output {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint: mnd // column padding
+		table(tw)
+		return tw.Flush()
+	case "yaml":
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return yaml.Convert(w, bytes.NewReader(b))
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+// contactsTable writes contacts as table rows, with their primary email & phone.
+func contactsTable(w io.Writer, contacts []restapi.ContactModel) {
+	fmt.Fprintln(w, "ID\tFIRSTNAME\tLASTNAME\tBIRTHDAY\tEMAIL\tPHONE")
+	for _, c := range contacts {
+		var email, phone string
+		for n, e := range c.Emails {
+			if n == 0 || e.Primary {
+				email = e.Address
+			}
+		}
+		for n, p := range c.Phones {
+			if n == 0 || p.Primary {
+				phone = p.Number
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.ID, c.Firstname, c.Lastname, c.Birthday, email, phone)
+	}
+}
+
+// open returns the reader of a file, stdin for -.
+func open(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
+
+// readContact reads a contact from a JSON file, as printed by the get command.
+func readContact(file string) (*domain.Contact, int, error) {
+	r, err := open(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Close()
+
+	var m contactOutput
+	err = json.NewDecoder(r).Decode(&m)
+	if err != nil {
+		return nil, 0, errors.Join(domain.ErrInvalid, fmt.Errorf("%s: %w", file, err))
+	}
+	c, err := m.Contact()
+	if err != nil {
+		return nil, 0, errors.Join(domain.ErrInvalid, fmt.Errorf("%s: %w", file, err))
+	}
+	return c, m.Version, nil
+}
+
+func contactsCreateCommand(flags *contactsFlags) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a contact read as JSON, printing its ID",
+		Args:  cobra.NoArgs,
+		Run: flags.run(func(ctx context.Context, cmd *cobra.Command, _ []string, service *client.Service) error {
+			c, _, err := readContact(file)
+			if err != nil {
+				return err
+			}
+			id, err := service.ContactsCreate(ctx, c)
+			if err != nil {
+				return err
+			}
+			return flags.print(cmd.OutOrStdout(), restapi.ContactIDModel{ID: id}, func(w io.Writer) {
+				fmt.Fprintln(w, id)
+			})
+		}),
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "JSON file of the contact, - for stdin")
+	return cmd
+}
+
+func contactsGetCommand(flags *contactsFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Print a contact along with its version",
+		Args:  cobra.ExactArgs(1),
+		Run: flags.run(func(ctx context.Context, cmd *cobra.Command, args []string, service *client.Service) error {
+			id, err := parseContactID(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := service.ContactsRead(ctx, id)
+			if err != nil {
+				return err
+			}
+			out := contactOutput{ContactModel: restapi.NewContactModel(c), Version: c.Version}
+			return flags.print(cmd.OutOrStdout(), out, func(w io.Writer) {
+				contactsTable(w, []restapi.ContactModel{out.ContactModel})
+			})
+		}),
+	}
+}
+
+func contactsUpdateCommand(flags *contactsFlags) *cobra.Command {
+	var (
+		file    string
+		version int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Replace a contact with one read as JSON",
+		Long: "Replace a contact with one read as JSON, as printed by get -o json.\n\n" +
+			"The update is conditioned on the version of the file, or that of --version, unless zero.",
+		Args: cobra.ExactArgs(1),
+		Run: flags.run(func(ctx context.Context, cmd *cobra.Command, args []string, service *client.Service) error {
+			id, err := parseContactID(args[0])
+			if err != nil {
+				return err
+			}
+			c, v, err := readContact(file)
+			if err != nil {
+				return err
+			}
+			c.Version = v
+			if cmd.Flags().Changed("version") {
+				c.Version = version
+			}
+			_, err = service.ContactsUpdate(ctx, id, c)
+			return err
+		}),
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "JSON file of the contact, - for stdin")
+	cmd.Flags().IntVar(&version, "version", 0, "version of the contact to update, 0 for any")
+	return cmd
+}
+
+func contactsDeleteCommand(flags *contactsFlags) *cobra.Command {
+	var version int
+
+	cmd := &cobra.Command{
+		Use:   "delete ID",
+		Short: "Delete a contact, moving it to the trash",
+		Args:  cobra.ExactArgs(1),
+		Run: flags.run(func(ctx context.Context, _ *cobra.Command, args []string, service *client.Service) error {
+			id, err := parseContactID(args[0])
+			if err != nil {
+				return err
+			}
+			return service.ContactsDelete(ctx, id, version)
+		}),
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "version of the contact to delete, 0 for any")
+	return cmd
+}
+
+func contactsListCommand(flags *contactsFlags) *cobra.Command {
+	var (
+		q          domain.ContactsQuery
+		sort       string
+		bornAfter  string
+		bornBefore string
+		all        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List contacts a page at a time",
+		Args:  cobra.NoArgs,
+		Run: flags.run(func(ctx context.Context, cmd *cobra.Command, _ []string, service *client.Service) error {
+			q.Sort = domain.ContactsSort(sort)
+			for _, bound := range []struct {
+				name  string
+				value string
+				time  *time.Time
+			}{
+				{"born-after", bornAfter, &q.BornAfter},
+				{"born-before", bornBefore, &q.BornBefore},
+			} {
+				if bound.value == "" {
+					continue
+				}
+				t, err := time.Parse(time.DateOnly, bound.value)
+				if err != nil {
+					return errors.Join(domain.ErrInvalid,
+						&domain.FieldError{Field: bound.name, Value: bound.value, Err: err})
+				}
+				*bound.time = t
+			}
+
+			var (
+				contacts = []restapi.ContactModel{}
+				trashed  = []restapi.TrashedContactModel{}
+				next     string
+			)
+			for {
+				page, err := service.ContactsList(ctx, &q)
+				if err != nil {
+					return err
+				}
+				for _, c := range page.Contacts {
+					contacts = append(contacts, restapi.NewContactModel(c))
+					trashed = append(trashed, restapi.TrashedContactModel{ContactModel: contacts[len(contacts)-1],
+						DeletedAt: c.DeletedAt})
+				}
+				next = page.Next
+				if !all || next == "" {
+					break
+				}
+				q.Cursor = next
+			}
+
+			var out any = restapi.ContactsPageModel{Items: contacts, Next: next}
+			if q.Trashed {
+				out = restapi.TrashPageModel{Items: trashed, Next: next}
+			}
+			return flags.print(cmd.OutOrStdout(), out, func(w io.Writer) {
+				contactsTable(w, contacts)
+				if next != "" {
+					fmt.Fprintln(cmd.ErrOrStderr(), "more contacts with --cursor", next)
+				}
+			})
+		}),
+	}
+	cmd.Flags().StringVar(&q.NamePrefix, "name", "", "firstname or lastname prefix")
+	cmd.Flags().StringVar(&sort, "sort", "", "sort order: lastname, firstname or birthday")
+	cmd.Flags().StringVar(&bornAfter, "born-after", "", "exclusive birthday lower bound, as YYYY-MM-DD")
+	cmd.Flags().StringVar(&bornBefore, "born-before", "", "exclusive birthday upper bound, as YYYY-MM-DD")
+	cmd.Flags().IntVar(&q.Limit, "limit", 0, "maximum number of contacts per page, the server default if 0")
+	cmd.Flags().StringVar(&q.Cursor, "cursor", "", "cursor from a previous listing")
+	cmd.Flags().BoolVar(&q.Trashed, "trash", false, "list the contacts in the trash instead")
+	cmd.Flags().BoolVar(&all, "all", false, "list every page")
+	return cmd
+}
+
+func contactsImportCommand(flags *contactsFlags) *cobra.Command {
+	var (
+		file    string
+		format  string
+		columns []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create contacts read from a vCard or CSV file",
+		Long: "Create contacts read from a vCard or CSV file, printing the report of the import.\n\n" +
+			"The command fails if any contact could not be imported.",
+		Args: cobra.NoArgs,
+		Run: flags.run(func(ctx context.Context, cmd *cobra.Command, _ []string, service *client.Service) error {
+			var dec restapi.ContactDecoder
+			r, err := open(file)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			switch format {
+			case "vcard":
+				dec = vcard.NewDecoder(r)
+			case "csv":
+				var mapping contactcsv.Mapping
+				if len(columns) > 0 {
+					mapping, err = contactcsv.ParseMapping(columns)
+					if err != nil {
+						return errors.Join(domain.ErrInvalid, err)
+					}
+				}
+				dec = contactcsv.NewDecoder(r, mapping)
+			default:
+				return fmt.Errorf("%w: unknown format %q, want vcard or csv", domain.ErrInvalid, format)
+			}
+
+			report, err := restapi.ImportContacts(ctx, service, dec)
+			if report != nil {
+				perr := flags.print(cmd.OutOrStdout(), report, func(w io.Writer) {
+					fmt.Fprintln(w, "INDEX\tSTATUS\tDETAIL")
+					for _, item := range report.Items {
+						fmt.Fprintf(w, "%d\t%d\t%s\n", item.Index, item.Status, item.Detail)
+					}
+				})
+				if err == nil {
+					err = perr
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("could not read file after %d contacts created: %w", report.Created, err)
+			}
+			if report.Failed > 0 {
+				return fmt.Errorf("%w: %d contacts could not be imported, %d created",
+					domain.ErrInvalid, report.Failed, report.Created)
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "file to import, - for stdin")
+	cmd.Flags().StringVar(&format, "format", "vcard", "format of the file: vcard or csv")
+	cmd.Flags().StringArrayVar(&columns, "column", nil,
+		"CSV column mapping as header=field, e.g. 'Work Email=email:work'; columns named after fields by default")
+	return cmd
+}
+
+func contactsExportCommand(flags *contactsFlags) *cobra.Command {
+	var (
+		file   string
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every contact to a vCard or CSV file",
+		Args:  cobra.NoArgs,
+		Run: flags.run(func(ctx context.Context, cmd *cobra.Command, _ []string, service *client.Service) error {
+			var encoder func(io.Writer) restapi.ContactEncoder
+			switch format {
+			case "vcard":
+				encoder = func(w io.Writer) restapi.ContactEncoder { return vcard.NewEncoder(w) }
+			case "csv":
+				encoder = func(w io.Writer) restapi.ContactEncoder { return contactcsv.NewEncoder(w) }
+			default:
+				return fmt.Errorf("%w: unknown format %q, want vcard or csv", domain.ErrInvalid, format)
+			}
+
+			if file == "-" {
+				return exportContacts(ctx, service, encoder(cmd.OutOrStdout()))
+			}
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			err = exportContacts(ctx, service, encoder(f))
+			return errors.Join(err, f.Close()) // a failed close may leave the file truncated
+		}),
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "file to write, - for stdout")
+	cmd.Flags().StringVar(&format, "format", "vcard", "format of the file: vcard or csv")
+	return cmd
+}
+
+// exportContacts encodes every contact of the service, flushing the encoder after each page if it buffers.
+func exportContacts(ctx context.Context, service *client.Service, enc restapi.ContactEncoder) error {
+	flusher, _ := enc.(interface{ Flush() error })
+	q := &domain.ContactsQuery{Limit: domain.ContactsListMaxLimit}
+	for {
+		page, err := service.ContactsList(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, c := range page.Contacts {
+			err = enc.Encode(c)
+			if err != nil {
+				return err
+			}
+		}
+		if flusher != nil {
+			err = flusher.Flush()
+			if err != nil {
+				return err
+			}
+		}
+		if page.Next == "" {
+			return nil
+		}
+		q.Cursor = page.Next
+	}
+}
+
+// parseContactID parses the ID of a contact given as argument.
+func parseContactID(s string) (domain.ContactID, error) {
+	var id domain.ContactID
+	err := id.UnmarshalText([]byte(s))
+	if err != nil {
+		return id, errors.Join(domain.ErrInvalid, &domain.FieldError{Field: "id", Value: s, Err: err})
+	}
+	return id, nil
+}
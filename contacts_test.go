@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/rlibaert/service-example-go/cli/api"
+	"github.com/rlibaert/service-example-go/restapi"
+	"github.com/rlibaert/service-example-go/router"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+// contacts returns a function running the contacts commands against a served in-memory instance,
+// returning their output and exit code.
+func contacts(t *testing.T) func(args ...string) (string, int) {
+	t.Helper()
+	store := stores.MustNewMock()
+	handler := api.NewRouter(&api.RouterOptions{
+		EndpointsPrefix: "/api",
+		TenantHeader:    "X-Tenant-Id",
+		IdempotencyTTL:  time.Hour,
+	}, "test", "", "", "", slog.New(slog.DiscardHandler),
+		store, api.NewWebhooksStore(store), &wrappers.EventLog{}, &router.Health{}, noop.NewTracerProvider(),
+		&api.Auth{})
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return func(args ...string) (string, int) {
+		t.Helper()
+		var flags contactsFlags
+		cli := humacli.New(func(humacli.Hooks, *Options) {})
+		cli.Root().AddCommand(contactsCommand(&flags))
+		var out bytes.Buffer
+		cli.Root().SetOut(&out)
+		cli.Root().SetErr(&out)
+		cli.Root().SetArgs(append([]string{"contacts", "--endpoint", server.URL + "/api", "--logger.file", os.DevNull},
+			args...))
+		cli.Run()
+		return out.String(), flags.code
+	}
+}
+
+// write writes a file in a temporary directory and returns its path.
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+const contactJSON = `{"firstname": "john", "lastname": "smith", "birthday": "1999-12-31"}`
+
+func TestContactsCommand(t *testing.T) {
+	run := contacts(t)
+
+	out, code := run("create", "-f", write(t, "contact.json", contactJSON))
+	if code != 0 {
+		t.Fatal("create exited with", code, out)
+	}
+	id := strings.TrimSpace(out)
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"not found", []string{"get", "00000000-0000-0000-0000-000000000000"}, exitNotFound},
+		{"malformed ID", []string{"get", "nope"}, exitInvalid},
+		{"invalid contact", []string{"create", "-f", write(t, "invalid.json", `{"firstname": "john"}`)}, exitInvalid},
+		{"invalid query", []string{"list", "--born-after", "yesterday"}, exitInvalid},
+		{"conflict", []string{"delete", id, "--version", "2"}, exitConflict},
+		{"output format", []string{"get", id, "-o", "xml"}, exitFailure},
+		{"unreachable", []string{"get", id, "--endpoint", "http://127.0.0.1:0"}, exitFailure},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if out, code := run(tc.args...); code != tc.code {
+				t.Errorf("%v exited with %d, want %d: %s", tc.args, code, tc.code, out)
+			}
+		})
+	}
+
+	t.Run("Output", func(t *testing.T) {
+		for format, want := range map[string]string{
+			"table": "ID                                    FIRSTNAME  LASTNAME  BIRTHDAY",
+			"json":  `"firstname": "john"`,
+			"yaml":  "firstname: john",
+		} {
+			if out, code := run("get", id, "-o", format); code != 0 || !strings.Contains(out, want) {
+				t.Errorf("get -o %s exited with %d: %s, want %q", format, code, out, want)
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		out, code := run("get", id, "-o", "json")
+		if code != 0 {
+			t.Fatal("get exited with", code, out)
+		}
+		file := write(t, "contact.json", out)
+
+		for _, tc := range []struct {
+			args []string
+			code int
+		}{
+			{[]string{"update", id, "-f", file}, 0},
+			{[]string{"update", id, "-f", file}, exitConflict}, // version of the file, now stale
+			{[]string{"update", id, "-f", file, "--version", "2"}, 0},
+			{[]string{"update", id, "-f", file, "--version", "0"}, 0},
+		} {
+			if out, code := run(tc.args...); code != tc.code {
+				t.Errorf("%v exited with %d, want %d: %s", tc.args, code, tc.code, out)
+			}
+		}
+		var c contactOutput
+		out, _ = run("get", id, "-o", "json")
+		if err := json.Unmarshal([]byte(out), &c); err != nil || c.Version != 4 {
+			t.Error("get after updates got", out, err, "want version 4")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for range 2 {
+			if out, code := run("create", "-f", write(t, "contact.json", contactJSON)); code != 0 {
+				t.Fatal("create exited with", code, out)
+			}
+		}
+
+		for _, tc := range []struct {
+			args  []string
+			items int
+			next  bool
+		}{
+			{[]string{"list", "--limit", "1", "-o", "json"}, 1, true},
+			{[]string{"list", "--limit", "1", "--all", "-o", "json"}, 3, false},
+		} {
+			out, code := run(tc.args...)
+			var page restapi.ContactsPageModel
+			if err := json.Unmarshal([]byte(out), &page); code != 0 || err != nil {
+				t.Fatal(tc.args, "exited with", code, out, err)
+			}
+			if len(page.Items) != tc.items || (page.Next != "") != tc.next {
+				t.Errorf("%v got %d contacts and next %q, want %d", tc.args, len(page.Items), page.Next, tc.items)
+			}
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		file := write(t, "contacts.csv", "firstname,lastname,birthday\n"+
+			"jane,doe,2000-01-01\n"+
+			"jim,doe,someday\n")
+		out, code := run("import", "-f", file, "--format", "csv", "-o", "json")
+		if code != exitInvalid {
+			t.Error("import of an invalid contact exited with", code, "want", exitInvalid)
+		}
+		var report restapi.ImportReportModel
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatal(out, err)
+		}
+		if report.Created != 1 || report.Failed != 1 || len(report.Items) != 1 || report.Items[0].Index != 2 {
+			t.Errorf("import got report %+v", report)
+		}
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "contacts.csv")
+		if out, code := run("export", "-f", file, "--format", "csv"); code != 0 {
+			t.Fatal("export exited with", code, out)
+		}
+		if b, err := os.ReadFile(file); err != nil || !strings.Contains(string(b), "john,smith,1999-12-31") {
+			t.Errorf("export wrote %q, %v", b, err)
+		}
+
+		file = filepath.Join(t.TempDir(), "contacts.xml")
+		if out, code := run("export", "-f", file, "--format", "xml"); code != exitInvalid {
+			t.Error("export to an unknown format exited with", code, "want", exitInvalid, out)
+		}
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Error("export to an unknown format created the file:", err)
+		}
+
+		if _, err := os.Stat("/dev/full"); err == nil {
+			if out, code := run("export", "-f", "/dev/full"); code != exitFailure {
+				t.Error("export to a full device exited with", code, "want", exitFailure, out)
+			}
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AuditAction is the change recorded by an [AuditEvent].
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// AuditEvent is an immutable record of a change to a [Contact].
+type AuditEvent struct {
+	ID        int64 // assigned by stores in increasing order
+	ContactID ContactID
+	Action    AuditAction
+	Actor     string // subject of the caller, empty if anonymous
+	RequestID string
+	Time      time.Time
+	Changes   []AuditChange
+}
+
+// AuditChange is the change of a field of a [Contact], values being formatted by [AuditDiff].
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// AuditStore persists the [AuditEvent] objects of the contacts.
+//
+// Like a [Store], it must only let a tenant see its own events, given by [TenantFrom].
+type AuditStore interface {
+	// AuditAppend records an [AuditEvent], setting its ID.
+	AuditAppend(context.Context, *AuditEvent) error
+	// AuditList retrieves a page of the [AuditEvent] objects matching an [AuditQuery], most recent first.
+	AuditList(context.Context, *AuditQuery) (*AuditPage, error)
+}
+
+// AuditQuery paginates the history of a [Contact].
+type AuditQuery struct {
+	ContactID ContactID
+	Limit     int    // zero means unlimited to an [AuditStore]
+	Cursor    string // opaque, as returned in [AuditPage.Next]
+}
+
+// AuditPage is a page of the history of a [Contact].
+type AuditPage struct {
+	Events []*AuditEvent
+	Next   string // opaque cursor to the next page, empty on the last page
+}
+
+// AuditCursor returns the opaque cursor to the events recorded before an [AuditEvent] ID.
+func AuditCursor(id int64) string {
+	return Cursor{Key: strconv.FormatInt(id, 10)}.Encode()
+}
+
+// DecodeAuditCursor parses an opaque cursor returned by [AuditCursor]; zero is returned for "".
+func DecodeAuditCursor(s string) (int64, error) {
+	cur, err := DecodeCursor(s)
+	if err != nil || s == "" {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(cur.Key, 10, 64)
+	if err != nil {
+		return 0, errors.Join(ErrInvalid, &FieldError{Field: "cursor", Value: s, Err: errors.New("malformed cursor")})
+	}
+	return id, nil
+}
+
+// AuditDiff returns the changes of the fields of a [Contact], either being nil when created or deleted.
+//
+// Names and birthdays are formatted as is, the latter as a date, other details as JSON.
+func AuditDiff(before, after *Contact) []AuditChange {
+	names := []string{"firstname", "lastname", "birthday", "emails", "phones", "addresses"}
+	fields := func(c *Contact) []string {
+		if c == nil {
+			return make([]string, len(names))
+		}
+		var birthday string
+		if !c.Birthday.IsZero() {
+			birthday = c.Birthday.Format(time.DateOnly)
+		}
+		return []string{
+			c.Firstname, c.Lastname, birthday,
+			auditJSON(c.Emails), auditJSON(c.Phones), auditJSON(c.Addresses),
+		}
+	}
+
+	var changes []AuditChange
+	b, a := fields(before), fields(after)
+	for n, name := range names {
+		if b[n] != a[n] {
+			changes = append(changes, AuditChange{Field: name, Before: b[n], After: a[n]})
+		}
+	}
+	return changes
+}
+
+// auditJSON formats a list of contact details, empty if there are none.
+func auditJSON[T any](details []T) string {
+	if len(details) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(details) //nolint: errchkjson // plain structs always marshal
+	return string(b)
+}
+
+// ContactsHistory lists the events of the store, applying the defaults and limits of contact listings.
+// It fails with [errors.ErrUnsupported] if the store is not an [AuditStore].
+func (svc *ServiceStore) ContactsHistory(ctx context.Context, q *AuditQuery) (*AuditPage, error) {
+	audit, ok := svc.Store.(AuditStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: no history kept", errors.ErrUnsupported)
+	}
+
+	q2 := *q
+	if q2.Limit == 0 {
+		q2.Limit = ContactsListDefaultLimit
+	}
+	if q2.Limit < 0 || q2.Limit > ContactsListMaxLimit {
+		return nil, errors.Join(ErrInvalid, &FieldError{
+			Field: "limit",
+			Value: q.Limit,
+			Err:   fmt.Errorf("must be between 1 and %d", ContactsListMaxLimit),
+		})
+	}
+	return audit.AuditList(ctx, &q2)
+}
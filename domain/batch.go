@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ContactsBatchMaxActions is the maximum number of actions of a batch.
+const ContactsBatchMaxActions = 1000
+
+// ErrAborted is the error of batch actions not applied because another one failed.
+var ErrAborted = errors.New("domain: aborted")
+
+// AtomicStore is a [Store] able to apply several calls atomically.
+type AtomicStore interface {
+	Store
+	// ContactsAtomic calls a function with a [Store] whose calls are all applied if it returns no error,
+	// none otherwise, its error being returned as is.
+	// It may fail with [errors.ErrUnsupported] without calling the function, e.g. if wrapping a non-atomic store.
+	ContactsAtomic(context.Context, func(Store) error) error
+}
+
+// BatchOp is the operation of a [BatchAction].
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchAction is an action of a batch.
+type BatchAction struct {
+	Op      BatchOp
+	ID      ContactID // of the updated or deleted contact
+	Contact *Contact  // created or updated, its version being checked unless zero
+	Version int       // of the deleted contact, checked unless zero
+}
+
+// BatchResult is the result of a [BatchAction].
+type BatchResult struct {
+	ID  ContactID // of the created, updated or deleted contact
+	Err error
+}
+
+// ContactsBatch applies a batch of actions and returns their results, in order.
+//
+// The batch is atomic if the store is an [AtomicStore]: no action is applied if any fails,
+// the others failing with [ErrAborted]. Actions are otherwise applied independently.
+func (svc *ServiceStore) ContactsBatch(ctx context.Context, actions []BatchAction) ([]BatchResult, error) {
+	if len(actions) > ContactsBatchMaxActions {
+		return nil, errors.Join(ErrInvalid, &FieldError{
+			Field: "actions",
+			Value: len(actions),
+			Err:   fmt.Errorf("more than %d actions", ContactsBatchMaxActions),
+		})
+	}
+
+	if store, ok := svc.Store.(AtomicStore); ok {
+		var (
+			results []BatchResult
+			events  []*Event
+		)
+		errFailed := errors.New("batch failed")
+		err := store.ContactsAtomic(ctx, func(store Store) error {
+			results, events = svc.batch(ctx, store, actions)
+			for _, r := range results {
+				if r.Err != nil {
+					return errFailed
+				}
+			}
+			return outboxAppend(ctx, store, events)
+		})
+		switch {
+		case errors.Is(err, errFailed):
+			for n := range results {
+				if results[n].Err == nil {
+					results[n].Err = ErrAborted
+				}
+			}
+			return results, nil
+		case errors.Is(err, errors.ErrUnsupported) && results == nil:
+		case err != nil:
+			return nil, err
+		default:
+			svc.publish(events)
+			return results, nil
+		}
+	}
+
+	results, events := svc.batch(ctx, svc.Store, actions)
+	err := outboxAppend(ctx, svc.Store, events)
+	if err != nil {
+		return results, err
+	}
+	svc.publish(events)
+	return results, nil
+}
+
+// batch applies actions one by one to a store, and returns the events of those applied.
+func (svc *ServiceStore) batch(ctx context.Context, store Store, actions []BatchAction) ([]BatchResult, []*Event) {
+	results := make([]BatchResult, len(actions))
+	var events []*Event
+	for n, a := range actions {
+		r := &results[n]
+		if a.Op != BatchCreate {
+			r.ID = a.ID
+		}
+		typ := ContactUpdated
+		switch {
+		case a.Op != BatchCreate && a.Op != BatchUpdate && a.Op != BatchDelete:
+			r.Err = errors.Join(ErrInvalid, &FieldError{Field: "op", Value: a.Op, Err: errors.New("unknown operation")})
+		case a.Op == BatchDelete:
+			typ = ContactDeleted
+			r.Err = store.ContactsDel(ctx, a.ID, a.Version)
+		case a.Contact == nil:
+			r.Err = errors.Join(ErrInvalid, &FieldError{Field: "contact", Err: errors.New("required")})
+		default:
+			var c *Contact
+			c, r.Err = svc.check(a.Contact)
+			switch {
+			case r.Err != nil:
+			case a.Op == BatchCreate:
+				typ = ContactCreated
+				r.ID, r.Err = store.ContactsSet(ctx, c)
+			default:
+				r.Err = store.ContactsReset(ctx, a.ID, c)
+			}
+		}
+
+		if r.Err != nil {
+			continue
+		}
+		var e *Event
+		e, r.Err = svc.event(ctx, store, typ, r.ID)
+		if r.Err == nil {
+			events = append(events, e)
+		}
+	}
+	return results, events
+}
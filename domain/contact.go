@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,9 +11,26 @@ import (
 type ContactID struct{ uuid.UUID }
 
 // Contact contains a contact's personal informations.
+//
+// Version is maintained by stores: it starts at 1 and is incremented on every update.
+// DeletedAt is set by stores as well, when the contact is moved to the trash.
 type Contact struct {
 	ID        ContactID
+	Version   int
 	Firstname string
 	Lastname  string
 	Birthday  time.Time
+	Emails    []Email
+	Phones    []Phone
+	Addresses []Address
+	DeletedAt time.Time // zero unless in the trash
+}
+
+// Clone returns a copy of the [Contact] sharing no memory with it.
+func (c *Contact) Clone() *Contact {
+	clone := *c
+	clone.Emails = slices.Clone(c.Emails)
+	clone.Phones = slices.Clone(c.Phones)
+	clone.Addresses = slices.Clone(c.Addresses)
+	return &clone
 }
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Label qualifies a contact detail.
+type Label string
+
+// Labels of contact details.
+const (
+	LabelHome  Label = "home"
+	LabelWork  Label = "work"
+	LabelOther Label = "other"
+)
+
+// Contact details validation limits.
+const (
+	DetailsMaxCount       = 10  // per kind of detail
+	EmailMaxLength        = 254 // in bytes
+	AddressFieldMaxLength = 200 // in runes
+)
+
+// Email is an email address of a [Contact].
+type Email struct {
+	Address string
+	Label   Label
+	Primary bool
+}
+
+// Phone is a phone number of a [Contact], in E.164 format once normalized, e.g. "+33123456789".
+type Phone struct {
+	Number  string
+	Label   Label
+	Primary bool
+}
+
+// Address is a postal address of a [Contact].
+type Address struct {
+	Street     string
+	Locality   string
+	Region     string
+	PostalCode string
+	Country    string // ISO 3166-1 alpha-2 code, e.g. "FR"
+	Label      Label
+	Primary    bool
+}
+
+// e164 matches phone numbers in E.164 format.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// normalizeLabel defaults empty labels to [LabelOther].
+func normalizeLabel(l Label) Label {
+	l = Label(strings.ToLower(strings.TrimSpace(string(l))))
+	if l == "" {
+		return LabelOther
+	}
+	return l
+}
+
+// normalizeEmail trims an email address and lowercases its domain.
+func normalizeEmail(s string) string {
+	s = strings.TrimSpace(s)
+	at := strings.LastIndexByte(s, '@')
+	if at < 0 {
+		return s
+	}
+	return s[:at] + strings.ToLower(s[at:])
+}
+
+// normalizePhone strips the separators of a phone number and turns its 00 international prefix into +.
+func normalizePhone(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || strings.ContainsRune("-./()", r) {
+			return -1
+		}
+		return r
+	}, s)
+	if rest, ok := strings.CutPrefix(s, "00"); ok {
+		return "+" + rest
+	}
+	return s
+}
+
+// normalizeAddressField trims an address field and puts it in Unicode normalization form C.
+func normalizeAddressField(s string) string { return norm.NFC.String(strings.TrimSpace(s)) }
+
+func normalizeEmails(emails []Email) {
+	for n := range emails {
+		e := &emails[n]
+		e.Address = normalizeEmail(e.Address)
+		e.Label = normalizeLabel(e.Label)
+	}
+}
+
+func normalizePhones(phones []Phone) {
+	for n := range phones {
+		p := &phones[n]
+		p.Number = normalizePhone(p.Number)
+		p.Label = normalizeLabel(p.Label)
+	}
+}
+
+func normalizeAddresses(addresses []Address) {
+	for n := range addresses {
+		a := &addresses[n]
+		a.Street = normalizeAddressField(a.Street)
+		a.Locality = normalizeAddressField(a.Locality)
+		a.Region = normalizeAddressField(a.Region)
+		a.PostalCode = normalizeAddressField(a.PostalCode)
+		a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+		a.Label = normalizeLabel(a.Label)
+	}
+}
+
+func validateEmails(e *ValidationError, emails []Email) {
+	validateCount(e, "emails", len(emails))
+	var primaries int
+	for n, email := range emails {
+		field := fmt.Sprintf("emails[%d]", n)
+		addr, err := mail.ParseAddress(email.Address)
+		switch {
+		case email.Address == "":
+			e.add(field+".address", email.Address, "required")
+		case len(email.Address) > EmailMaxLength:
+			e.add(field+".address", email.Address, fmt.Sprintf("must not exceed %d bytes", EmailMaxLength))
+		case err != nil || addr.Address != email.Address:
+			e.add(field+".address", email.Address, "must be an email address")
+		}
+		validateLabel(e, field, email.Label)
+		primaries += btoi(email.Primary)
+	}
+	validatePrimaries(e, "emails", primaries)
+}
+
+func validatePhones(e *ValidationError, phones []Phone) {
+	validateCount(e, "phones", len(phones))
+	var primaries int
+	for n, phone := range phones {
+		field := fmt.Sprintf("phones[%d]", n)
+		switch {
+		case phone.Number == "":
+			e.add(field+".number", phone.Number, "required")
+		case !e164.MatchString(phone.Number):
+			e.add(field+".number", phone.Number, "must be an international phone number, e.g. +33123456789")
+		}
+		validateLabel(e, field, phone.Label)
+		primaries += btoi(phone.Primary)
+	}
+	validatePrimaries(e, "phones", primaries)
+}
+
+func validateAddresses(e *ValidationError, addresses []Address) {
+	validateCount(e, "addresses", len(addresses))
+	var primaries int
+	for n, a := range addresses {
+		field := fmt.Sprintf("addresses[%d]", n)
+		validateAddressField(e, field+".street", a.Street, false)
+		validateAddressField(e, field+".locality", a.Locality, true)
+		validateAddressField(e, field+".region", a.Region, false)
+		validateAddressField(e, field+".postal_code", a.PostalCode, false)
+		if len(a.Country) != 2 || strings.ContainsFunc(a.Country, func(r rune) bool { return r < 'A' || r > 'Z' }) {
+			e.add(field+".country", a.Country, "must be an ISO 3166-1 alpha-2 country code")
+		}
+		validateLabel(e, field, a.Label)
+		primaries += btoi(a.Primary)
+	}
+	validatePrimaries(e, "addresses", primaries)
+}
+
+func validateCount(e *ValidationError, field string, count int) {
+	if count > DetailsMaxCount {
+		e.add(field, count, fmt.Sprintf("must not exceed %d items", DetailsMaxCount))
+	}
+}
+
+func validatePrimaries(e *ValidationError, field string, primaries int) {
+	if primaries > 1 {
+		e.add(field, primaries, "must have at most one primary item")
+	}
+}
+
+func validateLabel(e *ValidationError, field string, l Label) {
+	switch l {
+	case LabelHome, LabelWork, LabelOther:
+	default:
+		e.add(field+".label", l, "must be one of home, work or other")
+	}
+}
+
+func validateAddressField(e *ValidationError, field, value string, required bool) {
+	switch {
+	case value == "" && required:
+		e.add(field, value, "required")
+	case !utf8.ValidString(value):
+		e.add(field, value, "must be valid UTF-8")
+	case utf8.RuneCountInString(value) > AddressFieldMaxLength:
+		e.add(field, value, fmt.Sprintf("must not exceed %d characters", AddressFieldMaxLength))
+	case strings.ContainsFunc(value, unicode.IsControl):
+		e.add(field, value, "must not contain control characters")
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -4,6 +4,8 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 )
 
 // Store is an abstraction to an underlying storage.
@@ -13,9 +15,24 @@ type Store interface {
 	// ContactsGet retrieves a [Contact] given its [ContactID].
 	ContactsGet(context.Context, ContactID) (*Contact, error)
 	// ContactsReset updates a [Contact] given its [ContactID].
+	// It fails with [ErrConflict] if the [Contact] version is not zero and differs from the stored one.
 	ContactsReset(context.Context, ContactID, *Contact) error
-	// ContactsDel deletes a [Contact] given its [ContactID].
-	ContactsDel(context.Context, ContactID) error
+	// ContactsDel moves a [Contact] to the trash given its [ContactID].
+	// It fails with [ErrConflict] if the version is not zero and differs from the stored one.
+	// Other methods ignore trashed contacts, but for listings of the trash.
+	ContactsDel(context.Context, ContactID, int) error
+	// ContactsUndel restores a [Contact] from the trash given its [ContactID].
+	ContactsUndel(context.Context, ContactID) error
+	// ContactsPurge permanently deletes a [Contact] from the trash given its [ContactID].
+	ContactsPurge(context.Context, ContactID) error
+	// ContactsPurgeBefore permanently deletes the [Contact] objects moved to the trash before a time,
+	// whatever their tenant, and returns their number.
+	ContactsPurgeBefore(context.Context, time.Time) (int, error)
+	// ContactsModify atomically applies a [ContactPatch] to a [Contact] given its [ContactID] and returns the result.
+	// It fails with [ErrConflict] if the patch version is not zero and differs from the stored one.
+	ContactsModify(context.Context, ContactID, *ContactPatch) (*Contact, error)
+	// ContactsList retrieves a page of [Contact] objects matching a [ContactsQuery].
+	ContactsList(context.Context, *ContactsQuery) (*ContactsPage, error)
 }
 
 type Service interface {
@@ -23,36 +40,207 @@ type Service interface {
 	ContactsCreate(context.Context, *Contact) (ContactID, error)
 	// ContactsRead retrieves a [Contact] given its [ContactID].
 	ContactsRead(context.Context, ContactID) (*Contact, error)
-	// ContactsUpdate updates a [Contact] given its [ContactID].
-	ContactsUpdate(context.Context, ContactID, *Contact) error
-	// ContactsDelete deletes a [Contact] given its [ContactID].
-	ContactsDelete(context.Context, ContactID) error
+	// ContactsUpdate updates a [Contact] given its [ContactID] and returns its new version.
+	// It fails with [ErrConflict] if the [Contact] version is not zero and differs from the stored one.
+	ContactsUpdate(context.Context, ContactID, *Contact) (int, error)
+	// ContactsDelete moves a [Contact] to the trash given its [ContactID].
+	// It fails with [ErrConflict] if the version is not zero and differs from the stored one.
+	ContactsDelete(context.Context, ContactID, int) error
+	// ContactsRestore restores a [Contact] from the trash given its [ContactID].
+	ContactsRestore(context.Context, ContactID) error
+	// ContactsPurge permanently deletes a [Contact] from the trash given its [ContactID].
+	ContactsPurge(context.Context, ContactID) error
+	// ContactsPatch atomically applies a [ContactPatch] to a [Contact] given its [ContactID] and returns the result.
+	// It fails with [ErrConflict] if the patch version is not zero and differs from the stored one.
+	ContactsPatch(context.Context, ContactID, *ContactPatch) (*Contact, error)
+	// ContactsList retrieves a page of [Contact] objects matching a [ContactsQuery], in the trash or not.
+	ContactsList(context.Context, *ContactsQuery) (*ContactsPage, error)
+	// ContactsBatch applies a batch of [BatchAction] objects and returns their [BatchResult], in order.
+	// Failed actions are reported by their result, the batch failing as a whole only on unexpected errors.
+	ContactsBatch(context.Context, []BatchAction) ([]BatchResult, error)
+	// ContactsHistory retrieves a page of the [AuditEvent] objects of a [Contact], most recent first.
+	// It fails with [errors.ErrUnsupported] if no history is kept.
+	ContactsHistory(context.Context, *AuditQuery) (*AuditPage, error)
+	// ContactsEvents streams the [Event] objects of contacts matching an [EventsQuery] following the one with
+	// its LastID, or only those to come if it is empty. The channel is closed once the context is done, or early
+	// if events are lost, for callers to resume from the last one received.
+	// It fails with [ErrEventsLost] if the last event is no longer retained,
+	// and with [errors.ErrUnsupported] if no events are streamed.
+	ContactsEvents(context.Context, *EventsQuery) (<-chan *Event, error)
 }
 
 var (
-	ErrNotFound = errors.New("domain: not found")
-	ErrInvalid  = errors.New("domain: invalid argument")
+	ErrNotFound  = errors.New("domain: not found")
+	ErrInvalid   = errors.New("domain: invalid argument")
+	ErrConflict  = errors.New("domain: version conflict")
+	ErrForbidden = errors.New("domain: forbidden")
 )
 
+// FieldError is an error related to a specific field of an entity.
+//
+// Field is a path-like string identifying the field, e.g. "birthday" or "emails[1].address".
+type FieldError struct {
+	Field string
+	Value any
+	Err   error
+}
+
+func (e *FieldError) Error() string { return fmt.Sprintf("%s: %v", e.Field, e.Err) }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
 // ServiceStore implements [Service] using a [Store].
+//
+// Contacts are normalized and validated before being stored. Their changes are notified in the outbox of the store
+// if it is an [OutboxStore], and published once written to its [EventStream], which ContactsEvents subscribes to.
+// Their history is read from the store if it is an [AuditStore], recording it being left to store decorators.
 type ServiceStore struct {
-	Store Store
+	Store  Store
+	Now    func() time.Time // defaults to [time.Now]
+	Events EventStream      // of the changes once written, none streamed if nil
 }
 
 var _ Service = (*ServiceStore)(nil)
 
+func (svc *ServiceStore) now() time.Time {
+	if svc.Now == nil {
+		return time.Now()
+	}
+	return svc.Now()
+}
+
+// check returns a normalized copy of a [Contact] if valid, without deletion time: the trash is only changed by
+// deletions, restorations and purges.
+func (svc *ServiceStore) check(c *Contact) (*Contact, error) {
+	c = c.Clone()
+	c.DeletedAt = time.Time{}
+	c.Normalize()
+	return c, c.Validate(svc.now())
+}
+
 func (svc *ServiceStore) ContactsCreate(ctx context.Context, c *Contact) (ContactID, error) {
-	return svc.Store.ContactsSet(ctx, c)
+	c, err := svc.check(c)
+	if err != nil {
+		return ContactID{}, err
+	}
+
+	var id ContactID
+	err = svc.write(ctx, func(store Store) ([]*Event, error) {
+		id, err = store.ContactsSet(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		e, err := svc.event(ctx, store, ContactCreated, id)
+		return []*Event{e}, err
+	})
+	return id, err
 }
 
 func (svc *ServiceStore) ContactsRead(ctx context.Context, id ContactID) (*Contact, error) {
 	return svc.Store.ContactsGet(ctx, id)
 }
 
-func (svc *ServiceStore) ContactsUpdate(ctx context.Context, id ContactID, c *Contact) error {
-	return svc.Store.ContactsReset(ctx, id, c)
+func (svc *ServiceStore) ContactsUpdate(ctx context.Context, id ContactID, c *Contact) (int, error) {
+	c, err := svc.check(c)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = svc.write(ctx, func(store Store) ([]*Event, error) {
+		err := store.ContactsReset(ctx, id, c)
+		if err != nil {
+			return nil, err
+		}
+		e, err := svc.event(ctx, store, ContactUpdated, id)
+		if err != nil {
+			return nil, err
+		}
+		version = e.Contact.Version
+		return []*Event{e}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
-func (svc *ServiceStore) ContactsDelete(ctx context.Context, id ContactID) error {
-	return svc.Store.ContactsDel(ctx, id)
+func (svc *ServiceStore) ContactsDelete(ctx context.Context, id ContactID, version int) error {
+	return svc.write(ctx, func(store Store) ([]*Event, error) {
+		err := store.ContactsDel(ctx, id, version)
+		if err != nil {
+			return nil, err
+		}
+		e, err := svc.event(ctx, store, ContactDeleted, id)
+		return []*Event{e}, err
+	})
+}
+
+func (svc *ServiceStore) ContactsRestore(ctx context.Context, id ContactID) error {
+	return svc.write(ctx, func(store Store) ([]*Event, error) {
+		err := store.ContactsUndel(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		e, err := svc.event(ctx, store, ContactUpdated, id)
+		return []*Event{e}, err
+	})
+}
+
+func (svc *ServiceStore) ContactsPurge(ctx context.Context, id ContactID) error {
+	return svc.Store.ContactsPurge(ctx, id)
+}
+
+func (svc *ServiceStore) ContactsPatch(ctx context.Context, id ContactID, p *ContactPatch) (*Contact, error) {
+	p = p.Clone()
+	p.Normalize()
+	err := p.Validate(svc.now())
+	if err != nil {
+		return nil, err
+	}
+
+	var c *Contact
+	err = svc.write(ctx, func(store Store) ([]*Event, error) {
+		var err error
+		c, err = store.ContactsModify(ctx, id, p)
+		if err != nil {
+			return nil, err
+		}
+		e, err := svc.event(ctx, store, ContactUpdated, id)
+		return []*Event{e}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ContactsList validates the query and applies its defaults before listing.
+func (svc *ServiceStore) ContactsList(ctx context.Context, q *ContactsQuery) (*ContactsPage, error) {
+	q2 := *q
+	if q2.Sort == "" {
+		q2.Sort = ContactsSortLastname
+	}
+	if q2.Limit == 0 {
+		q2.Limit = ContactsListDefaultLimit
+	}
+
+	var errs []error
+	switch q2.Sort {
+	case ContactsSortLastname, ContactsSortFirstname, ContactsSortBirthday:
+	default:
+		errs = append(errs, &FieldError{Field: "sort", Value: q.Sort, Err: errors.New("unknown sort order")})
+	}
+	if q2.Limit < 0 || q2.Limit > ContactsListMaxLimit {
+		errs = append(errs, &FieldError{
+			Field: "limit",
+			Value: q.Limit,
+			Err:   fmt.Errorf("must be between 1 and %d", ContactsListMaxLimit),
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(append([]error{ErrInvalid}, errs...)...)
+	}
+
+	return svc.Store.ContactsList(ctx, &q2)
 }
@@ -1,7 +1,11 @@
 package domain_test
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rlibaert/service-example-go/domain"
 	"github.com/rlibaert/service-example-go/domaintest"
@@ -10,4 +14,185 @@ import (
 
 func TestService(t *testing.T) {
 	domaintest.TestService(t, &domain.ServiceStore{Store: stores.MustNewMock()})
+
+	t.Run("NonAtomic", func(t *testing.T) {
+		// hides the atomicity of the mock for batches to be best-effort
+		domaintest.TestService(t, &domain.ServiceStore{Store: struct{ domain.Store }{stores.MustNewMock()}})
+	})
+}
+
+func TestEvents(t *testing.T) {
+	store := stores.MustNewMock()
+	service := &domain.ServiceStore{Store: store}
+	ctx := domain.WithTenant(t.Context(), "acme")
+
+	id, err := service.ContactsCreate(ctx, domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ContactsUpdate(ctx, id, domaintest.NewContact()); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ContactsDelete(ctx, id, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ContactsRestore(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	jane := "jane"
+	_, err = service.ContactsPatch(ctx, id, &domain.ContactPatch{Firstname: &jane})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// failed writes, atomic batches included, produce no events
+	if _, err := service.ContactsUpdate(ctx, id, &domain.Contact{Version: 1}); err == nil {
+		t.Fatal("ContactsUpdate with a stale version succeeded")
+	}
+	_, err = service.ContactsBatch(ctx, []domain.BatchAction{
+		{Op: domain.BatchCreate, Contact: domaintest.NewContact()},
+		{Op: domain.BatchDelete, ID: id, Version: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.ContactsBatch(ctx, []domain.BatchAction{{Op: domain.BatchDelete, ID: id}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := store.OutboxFetch(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []domain.EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+		if e.ContactID != id || e.Tenant != "acme" || e.ID == "" || e.Time.IsZero() ||
+			(e.Contact == nil) != (e.Type == domain.ContactDeleted) {
+			t.Errorf("got event %+v", e)
+		}
+	}
+	want := []domain.EventType{
+		domain.ContactCreated, domain.ContactUpdated, domain.ContactDeleted, domain.ContactUpdated,
+		domain.ContactUpdated, domain.ContactDeleted,
+	}
+	if !slices.Equal(types, want) {
+		t.Fatal("got", types, "want", want)
+	}
+	if c := events[4].Contact; c.Firstname != "jane" || c.Version != 4 {
+		t.Errorf("patch event got contact %+v", c)
+	}
+}
+
+func TestContactValidate(t *testing.T) {
+	now := time.Date(2025, time.November, 26, 0, 0, 0, 0, time.UTC)
+	valid := domain.Contact{
+		Firstname: "john",
+		Lastname:  "smith",
+		Birthday:  time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+		Emails:    []domain.Email{{Address: "john@example.com", Primary: true}},
+		Phones:    []domain.Phone{{Number: "+33 1 23 45 67 89"}},
+		Addresses: []domain.Address{{Locality: "Paris", Country: "fr"}},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*domain.Contact)
+		fields []string
+	}{
+		{"valid", func(*domain.Contact) {}, nil},
+		{"empty", func(c *domain.Contact) { *c = domain.Contact{} }, []string{"firstname", "lastname", "birthday"}},
+		{"blank", func(c *domain.Contact) { c.Firstname = " \t" }, []string{"firstname"}},
+		{"long", func(c *domain.Contact) { c.Lastname = strings.Repeat("é", 101) }, []string{"lastname"}},
+		{"control", func(c *domain.Contact) { c.Lastname = "smi\x00th" }, []string{"lastname"}},
+		{"future", func(c *domain.Contact) { c.Birthday = now.AddDate(0, 0, 1) }, []string{"birthday"}},
+		{"ancient", func(c *domain.Contact) { c.Birthday = now.AddDate(-151, 0, 0) }, []string{"birthday"}},
+		{"no details", func(c *domain.Contact) { c.Emails, c.Phones, c.Addresses = nil, nil, nil }, nil},
+		{"email", func(c *domain.Contact) { c.Emails[0].Address = "John <john@example.com>" }, []string{"emails[0].address"}},
+		{"email blank", func(c *domain.Contact) { c.Emails[0].Address = " " }, []string{"emails[0].address"}},
+		{"label", func(c *domain.Contact) { c.Emails[0].Label = "mobile" }, []string{"emails[0].label"}},
+		{"primaries", func(c *domain.Contact) {
+			c.Emails = append(c.Emails, domain.Email{Address: "jsmith@example.com", Primary: true})
+		}, []string{"emails"}},
+		{"too many", func(c *domain.Contact) {
+			c.Phones = slices.Repeat(c.Phones, domain.DetailsMaxCount+1)
+		}, []string{"phones"}},
+		{"phone local", func(c *domain.Contact) { c.Phones[0].Number = "01 23 45 67 89" }, []string{"phones[0].number"}},
+		{"phone letters", func(c *domain.Contact) { c.Phones[0].Number = "+33 CALL ME" }, []string{"phones[0].number"}},
+		{"address", func(c *domain.Contact) {
+			c.Addresses[0] = domain.Address{Street: "1\nrue", Country: "FRA"}
+		}, []string{"addresses[0].street", "addresses[0].locality", "addresses[0].country"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := *valid.Clone()
+			tc.modify(&c)
+			c.Normalize()
+			err := c.Validate(now)
+
+			if tc.fields == nil {
+				if err != nil {
+					t.Error("got", err)
+				}
+				return
+			}
+
+			var verr *domain.ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, domain.ErrInvalid) {
+				t.Fatal("got", err, "want a validation error")
+			}
+			var fields []string
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !slices.Equal(fields, tc.fields) {
+				t.Error("got invalid fields", fields, "want", tc.fields)
+			}
+		})
+	}
+}
+
+func TestContactNormalize(t *testing.T) {
+	c := domain.Contact{Firstname: " Zoë ", Lastname: "smith"}
+	c.Normalize()
+	if c.Firstname != "Zoë" {
+		t.Errorf("got %q", c.Firstname)
+	}
+
+	c = domain.Contact{
+		Emails:    []domain.Email{{Address: " John.Smith@Example.COM "}},
+		Phones:    []domain.Phone{{Number: "0033 (1) 23-45-67-89", Label: "Work"}},
+		Addresses: []domain.Address{{Locality: " Paris ", Country: "fr"}},
+	}
+	c.Normalize()
+	want := domain.Contact{
+		Emails:    []domain.Email{{Address: "John.Smith@example.com", Label: domain.LabelOther}},
+		Phones:    []domain.Phone{{Number: "+33123456789", Label: domain.LabelWork}},
+		Addresses: []domain.Address{{Locality: "Paris", Country: "FR", Label: domain.LabelOther}},
+	}
+	if !slices.Equal(c.Emails, want.Emails) || !slices.Equal(c.Phones, want.Phones) ||
+		!slices.Equal(c.Addresses, want.Addresses) {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+}
+
+func TestContactPatch(t *testing.T) {
+	now := time.Date(2025, time.November, 26, 0, 0, 0, 0, time.UTC)
+	firstname, lastname := " Zoë ", ""
+	p := &domain.ContactPatch{Firstname: &firstname, Emails: &[]domain.Email{}}
+	p.Normalize()
+	if err := p.Validate(now); err != nil {
+		t.Error("Validate:", err)
+	}
+
+	c := domain.Contact{Firstname: "john", Lastname: "smith", Emails: []domain.Email{{Address: "john@example.com"}}}
+	p.Apply(&c)
+	if c.Firstname != "Zoë" || c.Lastname != "smith" || len(c.Emails) != 0 {
+		t.Errorf("Apply got %+v", c)
+	}
+
+	p = &domain.ContactPatch{Lastname: &lastname}
+	var verr *domain.ValidationError
+	if err := p.Validate(now); !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "lastname" {
+		t.Error("Validate got", err, "want an invalid lastname")
+	}
 }
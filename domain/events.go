@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is the type of an [Event].
+type EventType string
+
+const (
+	ContactCreated EventType = "contact.created"
+	ContactUpdated EventType = "contact.updated" // restores included
+	ContactDeleted EventType = "contact.deleted" // moves to the trash, purges producing no event
+)
+
+// EventTypes are the types of the events produced by [ServiceStore].
+var EventTypes = []EventType{ContactCreated, ContactUpdated, ContactDeleted} //nolint: gochecknoglobals // read-only
+
+// Event notifies downstream systems of a change to a [Contact].
+type Event struct {
+	ID        string // unique, for consumers to ignore repeated deliveries
+	Type      EventType
+	Tenant    TenantID
+	ContactID ContactID
+	Contact   *Contact // state after the change, nil once deleted
+	Time      time.Time
+}
+
+// OutboxStore is a [Store] keeping [Event] objects until they are delivered.
+type OutboxStore interface {
+	Store
+	// OutboxAppend records events, atomically with the other calls of an [AtomicStore.ContactsAtomic] function.
+	// It may fail with [errors.ErrUnsupported], e.g. if wrapping a store without outbox.
+	OutboxAppend(context.Context, ...*Event) error
+	// OutboxFetch retrieves up to a number of the oldest events, whatever their tenant.
+	OutboxFetch(context.Context, int) ([]*Event, error)
+	// OutboxDelete deletes delivered events given their IDs.
+	OutboxDelete(context.Context, ...string) error
+}
+
+// ErrEventsLost is the error of resuming a stream of [Event] objects after one that is no longer retained,
+// for callers to know they missed events.
+var ErrEventsLost = errors.New("domain: events lost")
+
+// EventsQuery selects the [Event] objects streamed by [Service.ContactsEvents].
+type EventsQuery struct {
+	LastID    string    // of the last event received, to resume after it
+	ContactID ContactID // of the events streamed, all if zero
+}
+
+// Matches reports whether an event is of the contact of the query, if any.
+func (q *EventsQuery) Matches(e *Event) bool {
+	return q.ContactID == (ContactID{}) || e.ContactID == q.ContactID
+}
+
+// EventStream publishes the [Event] objects of a [ServiceStore] to subscribers.
+type EventStream interface {
+	// Publish sends events to the subscribers of their tenant.
+	Publish(...*Event)
+	// Subscribe returns a channel of the events of a tenant, see [Service.ContactsEvents].
+	Subscribe(context.Context, TenantID, *EventsQuery) (<-chan *Event, error)
+}
+
+// ContactsEvents subscribes to the events of the caller's tenant in the [EventStream] of the service.
+// It fails with [errors.ErrUnsupported] if it has none.
+func (svc *ServiceStore) ContactsEvents(ctx context.Context, q *EventsQuery) (<-chan *Event, error) {
+	if svc.Events == nil {
+		return nil, fmt.Errorf("%w: no events streamed", errors.ErrUnsupported)
+	}
+	return svc.Events.Subscribe(ctx, TenantFrom(ctx), q)
+}
+
+// event returns an [Event] of a contact, read from a store unless deleted.
+func (svc *ServiceStore) event(ctx context.Context, store Store, typ EventType, id ContactID) (*Event, error) {
+	e := &Event{ID: uuid.NewString(), Type: typ, Tenant: TenantFrom(ctx), ContactID: id, Time: svc.now()}
+	if typ != ContactDeleted {
+		var err error
+		e.Contact, err = store.ContactsGet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// write calls a function writing to the store and records the events it returns in the outbox of the store,
+// if it is an [OutboxStore], atomically if it is an [AtomicStore] too. The events are then published.
+func (svc *ServiceStore) write(ctx context.Context, f func(Store) ([]*Event, error)) error {
+	events, err := svc.apply(ctx, f)
+	if err != nil {
+		return err
+	}
+	svc.publish(events)
+	return nil
+}
+
+// apply is [ServiceStore.write] but for the publication of the events, which it returns.
+func (svc *ServiceStore) apply(ctx context.Context, f func(Store) ([]*Event, error)) ([]*Event, error) {
+	if _, ok := svc.Store.(OutboxStore); !ok {
+		return f(svc.Store)
+	}
+
+	if store, ok := svc.Store.(AtomicStore); ok {
+		var events []*Event
+		called := false
+		err := store.ContactsAtomic(ctx, func(store Store) error {
+			called = true
+			var err error
+			events, err = f(store)
+			if err != nil {
+				return err
+			}
+			return outboxAppend(ctx, store, events)
+		})
+		if called || !errors.Is(err, errors.ErrUnsupported) {
+			return events, err
+		}
+	}
+
+	events, err := f(svc.Store)
+	if err != nil {
+		return nil, err
+	}
+	return events, outboxAppend(ctx, svc.Store, events)
+}
+
+// publish publishes events to the [EventStream] of the service, if any.
+func (svc *ServiceStore) publish(events []*Event) {
+	if svc.Events != nil && len(events) > 0 {
+		svc.Events.Publish(events...)
+	}
+}
+
+// outboxAppend records events in the outbox of a store, if it has one.
+func outboxAppend(ctx context.Context, store Store, events []*Event) error {
+	outbox, ok := store.(OutboxStore)
+	if !ok || len(events) == 0 {
+		return nil
+	}
+	err := outbox.OutboxAppend(ctx, events...)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil
+	}
+	return err
+}
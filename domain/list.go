@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ContactsSort is a [Contact] listing order.
+type ContactsSort string
+
+const (
+	ContactsSortLastname  ContactsSort = "lastname"
+	ContactsSortFirstname ContactsSort = "firstname"
+	ContactsSortBirthday  ContactsSort = "birthday"
+)
+
+// Key returns the sort key of a [Contact]; keys are ordered lexicographically, ties are broken by ID.
+func (s ContactsSort) Key(c *Contact) string {
+	switch s {
+	case ContactsSortFirstname:
+		return strings.ToLower(c.Firstname)
+	case ContactsSortBirthday:
+		return c.Birthday.UTC().Format("20060102150405.000000000")
+	case ContactsSortLastname:
+		fallthrough
+	default:
+		return strings.ToLower(c.Lastname)
+	}
+}
+
+// Listing limits applied by [ServiceStore.ContactsList].
+const (
+	ContactsListDefaultLimit = 20
+	ContactsListMaxLimit     = 100
+)
+
+// ContactsQuery filters, sorts and paginates a [Contact] listing.
+type ContactsQuery struct {
+	Sort       ContactsSort
+	NamePrefix string    // case-insensitive prefix of either the firstname or the lastname
+	BornAfter  time.Time // ignored if zero
+	BornBefore time.Time // ignored if zero
+	Limit      int       // zero means unlimited to a [Store], [ServiceStore] applies defaults
+	Cursor     string    // opaque, as returned in [ContactsPage.Next]
+	Trashed    bool      // lists the contacts in the trash instead of the others
+}
+
+// ContactsPage is a page of a [Contact] listing.
+type ContactsPage struct {
+	Contacts []*Contact
+	Next     string // opaque cursor to the next page, empty on the last page
+}
+
+// Match reports whether a [Contact] satisfies the query filters.
+func (q *ContactsQuery) Match(c *Contact) bool {
+	prefix := strings.ToLower(q.NamePrefix)
+	return (strings.HasPrefix(strings.ToLower(c.Firstname), prefix) ||
+		strings.HasPrefix(strings.ToLower(c.Lastname), prefix)) &&
+		(q.BornAfter.IsZero() || c.Birthday.After(q.BornAfter)) &&
+		(q.BornBefore.IsZero() || c.Birthday.Before(q.BornBefore))
+}
+
+// Cursor is a position in a sorted [Contact] listing; stores encode it in [ContactsPage.Next].
+type Cursor struct {
+	Key string    `json:"k"`
+	ID  ContactID `json:"id"`
+}
+
+// Less reports whether the cursor sorts before a key and ID.
+func (cur Cursor) Less(key string, id ContactID) bool {
+	return cur.Key < key || cur.Key == key && cur.ID.String() < id.String()
+}
+
+// Encode returns the opaque representation of the cursor.
+func (cur Cursor) Encode() string {
+	b, _ := json.Marshal(cur) //nolint: errchkjson // cannot fail
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses an opaque cursor returned by [Cursor.Encode]; the zero [Cursor] is returned for "".
+func DecodeCursor(s string) (Cursor, error) {
+	var cur Cursor
+	if s == "" {
+		return cur, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &cur)
+	}
+	if err != nil {
+		return cur, errors.Join(ErrInvalid, &FieldError{Field: "cursor", Value: s, Err: errors.New("malformed cursor")})
+	}
+
+	return cur, nil
+}
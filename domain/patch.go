@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// ContactPatch is a partial update of a [Contact], as a JSON Merge Patch (RFC 7396) describes one:
+// nil fields are left unchanged and others replace the stored ones, details of a kind as a whole.
+//
+// Version conditions the patch the way it conditions [Service.ContactsUpdate].
+type ContactPatch struct {
+	Version   int
+	Firstname *string
+	Lastname  *string
+	Birthday  *time.Time
+	Emails    *[]Email
+	Phones    *[]Phone
+	Addresses *[]Address
+}
+
+// Clone returns a copy of the [ContactPatch] sharing no memory with it.
+func (p *ContactPatch) Clone() *ContactPatch {
+	clone := *p
+	clone.Firstname = clonePtr(p.Firstname)
+	clone.Lastname = clonePtr(p.Lastname)
+	clone.Birthday = clonePtr(p.Birthday)
+	clone.Emails = cloneSlicePtr(p.Emails)
+	clone.Phones = cloneSlicePtr(p.Phones)
+	clone.Addresses = cloneSlicePtr(p.Addresses)
+	return &clone
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneSlicePtr[T any](p *[]T) *[]T {
+	if p == nil {
+		return nil
+	}
+	s := slices.Clone(*p)
+	return &s
+}
+
+// Apply replaces the fields of a [Contact] set by the [ContactPatch], but its version.
+func (p *ContactPatch) Apply(c *Contact) {
+	if p.Firstname != nil {
+		c.Firstname = *p.Firstname
+	}
+	if p.Lastname != nil {
+		c.Lastname = *p.Lastname
+	}
+	if p.Birthday != nil {
+		c.Birthday = *p.Birthday
+	}
+	if p.Emails != nil {
+		c.Emails = slices.Clone(*p.Emails)
+	}
+	if p.Phones != nil {
+		c.Phones = slices.Clone(*p.Phones)
+	}
+	if p.Addresses != nil {
+		c.Addresses = slices.Clone(*p.Addresses)
+	}
+}
+
+// Normalize normalizes the fields set by the [ContactPatch] as [Contact.Normalize] does.
+func (p *ContactPatch) Normalize() {
+	if p.Firstname != nil {
+		*p.Firstname = normalizeName(*p.Firstname)
+	}
+	if p.Lastname != nil {
+		*p.Lastname = normalizeName(*p.Lastname)
+	}
+	if p.Emails != nil {
+		normalizeEmails(*p.Emails)
+	}
+	if p.Phones != nil {
+		normalizePhones(*p.Phones)
+	}
+	if p.Addresses != nil {
+		normalizeAddresses(*p.Addresses)
+	}
+}
+
+// Validate checks the fields set by a normalized [ContactPatch] as [Contact.Validate] does,
+// fields being validated independently of each other.
+func (p *ContactPatch) Validate(now time.Time) error {
+	var e ValidationError
+	if p.Firstname != nil {
+		validateName(&e, "firstname", *p.Firstname)
+	}
+	if p.Lastname != nil {
+		validateName(&e, "lastname", *p.Lastname)
+	}
+	if p.Birthday != nil {
+		validateBirthday(&e, *p.Birthday, now)
+	}
+	if p.Emails != nil {
+		validateEmails(&e, *p.Emails)
+	}
+	if p.Phones != nil {
+		validatePhones(&e, *p.Phones)
+	}
+	if p.Addresses != nil {
+		validateAddresses(&e, *p.Addresses)
+	}
+	return e.err()
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+// TenantID identifies a tenant, whose contacts are isolated from other tenants'.
+//
+// The zero value is the default tenant of single-tenant deployments.
+type TenantID string
+
+// tenantKey is the [context.Context] key of a [TenantID].
+type tenantKey struct{}
+
+// WithTenant returns a context scoping [Store] and [Service] calls to a tenant.
+func WithTenant(ctx context.Context, tenant TenantID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant of a context, the default one if unset.
+//
+// Every [Store] implementation must only let a tenant see and change its own contacts,
+// other tenants' contacts being [ErrNotFound].
+func TenantFrom(ctx context.Context) TenantID {
+	tenant, _ := ctx.Value(tenantKey{}).(TenantID)
+	return tenant
+}
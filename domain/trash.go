@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PurgeTrash permanently deletes the contacts moved to the trash for longer than a retention,
+// then every interval until the context is done, reporting the outcome of each purge.
+//
+// Purges go straight to the store, across tenants and without a caller: like those of [Service.ContactsPurge]
+// they produce no [Event], and unlike them no [AuditEvent] records them, the report being their only trace.
+func PurgeTrash(
+	ctx context.Context,
+	store Store,
+	retention, interval time.Duration,
+	report func(purged int, err error),
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := store.ContactsPurgeBefore(ctx, time.Now().Add(-retention))
+		report(n, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Contact validation limits.
+const (
+	NameMaxLength  = 100 // in runes
+	BirthdayMaxAge = 150 // in years
+)
+
+// ValidationError lists every invalid field of an entity and wraps [ErrInvalid].
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for n, f := range e.Fields {
+		msgs[n] = f.Error()
+	}
+	return ErrInvalid.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := []error{ErrInvalid}
+	for _, f := range e.Fields {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
+// add records an invalid field.
+func (e *ValidationError) add(field string, value any, msg string) {
+	e.Fields = append(e.Fields, &FieldError{Field: field, Value: value, Err: errors.New(msg)})
+}
+
+// err returns the [ValidationError] if any field was recorded, nil otherwise.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Normalize trims the names of a [Contact] and puts them in Unicode normalization form C,
+// lowercases the domain of its emails, puts its phone numbers in E.164 format when written with
+// an international prefix, uppercases its countries and defaults its labels to [LabelOther].
+func (c *Contact) Normalize() {
+	c.Firstname = normalizeName(c.Firstname)
+	c.Lastname = normalizeName(c.Lastname)
+	normalizeEmails(c.Emails)
+	normalizePhones(c.Phones)
+	normalizeAddresses(c.Addresses)
+}
+
+// normalizeName trims a name and puts it in Unicode normalization form C.
+func normalizeName(s string) string { return norm.NFC.String(strings.TrimSpace(s)) }
+
+// Validate checks a normalized [Contact] and returns a [*ValidationError] listing every invalid field.
+//
+// Birthdays must not be after now nor older than [BirthdayMaxAge] years.
+// Each kind of detail has at most [DetailsMaxCount] items, one of them primary.
+func (c *Contact) Validate(now time.Time) error {
+	var e ValidationError
+	validateName(&e, "firstname", c.Firstname)
+	validateName(&e, "lastname", c.Lastname)
+	validateBirthday(&e, c.Birthday, now)
+	validateEmails(&e, c.Emails)
+	validatePhones(&e, c.Phones)
+	validateAddresses(&e, c.Addresses)
+
+	return e.err()
+}
+
+func validateBirthday(e *ValidationError, birthday, now time.Time) {
+	switch {
+	case birthday.IsZero():
+		e.add("birthday", nil, "required")
+	case birthday.After(now):
+		e.add("birthday", birthday, "must not be in the future")
+	case birthday.Before(now.AddDate(-BirthdayMaxAge, 0, 0)):
+		e.add("birthday", birthday, fmt.Sprintf("must not be older than %d years", BirthdayMaxAge))
+	}
+}
+
+func validateName(e *ValidationError, field, name string) {
+	switch {
+	case name == "":
+		e.add(field, name, "required")
+	case !utf8.ValidString(name):
+		e.add(field, name, "must be valid UTF-8")
+	case utf8.RuneCountInString(name) > NameMaxLength:
+		e.add(field, name, fmt.Sprintf("must not exceed %d characters", NameMaxLength))
+	case strings.ContainsFunc(name, unicode.IsControl):
+		e.add(field, name, "must not contain control characters")
+	}
+}
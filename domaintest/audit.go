@@ -0,0 +1,101 @@
+package domaintest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// TestAuditStore tests a [domain.AuditStore] implementation.
+//
+// The store is shared by every subtest and may already contain events.
+func TestAuditStore(t *testing.T, store domain.AuditStore) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		e := &domain.AuditEvent{
+			ContactID: domain.ContactID{UUID: uuid.New()},
+			Action:    domain.AuditUpdate,
+			Actor:     "alice",
+			RequestID: "req-1",
+			Time:      time.Now().Round(0),
+			Changes:   []domain.AuditChange{{Field: "birthday", Before: "1999-12-31", After: "2000-01-01"}},
+		}
+		if err := store.AuditAppend(t.Context(), e); err != nil {
+			t.Fatal("AuditAppend:", err)
+		}
+		if e.ID == 0 {
+			t.Error("AuditAppend set no ID")
+		}
+
+		page, err := store.AuditList(t.Context(), &domain.AuditQuery{ContactID: e.ContactID})
+		if err != nil {
+			t.Fatal("AuditList:", err)
+		}
+		if len(page.Events) != 1 {
+			t.Fatal("AuditList got", len(page.Events), "events, want 1")
+		}
+		got := page.Events[0]
+		if got.ID != e.ID || got.Action != e.Action || got.Actor != e.Actor || got.RequestID != e.RequestID ||
+			!got.Time.Equal(e.Time) || !slices.Equal(got.Changes, e.Changes) {
+			t.Errorf("AuditList got %+v, want %+v", got, e)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		id := domain.ContactID{UUID: uuid.New()}
+		var want []int64
+		for range 5 {
+			e := &domain.AuditEvent{ContactID: id, Action: domain.AuditUpdate, Time: time.Now()}
+			if err := store.AuditAppend(t.Context(), e); err != nil {
+				t.Fatal("AuditAppend:", err)
+			}
+			want = append([]int64{e.ID}, want...)
+		}
+		// another contact's events are not listed
+		err := store.AuditAppend(t.Context(), &domain.AuditEvent{ContactID: domain.ContactID{UUID: uuid.New()}})
+		if err != nil {
+			t.Fatal("AuditAppend:", err)
+		}
+
+		var got []int64
+		q := &domain.AuditQuery{ContactID: id, Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > len(want) {
+				t.Fatal("AuditList does not end")
+			}
+			page, err := store.AuditList(t.Context(), q)
+			if err != nil {
+				t.Fatal("AuditList:", err)
+			}
+			for _, e := range page.Events {
+				got = append(got, e.ID)
+			}
+			if page.Next == "" {
+				break
+			}
+			q.Cursor = page.Next
+		}
+		if !slices.Equal(got, want) {
+			t.Error("AuditList got", got, "want", want)
+		}
+	})
+
+	t.Run("Tenants", func(t *testing.T) {
+		e := &domain.AuditEvent{ContactID: domain.ContactID{UUID: uuid.New()}, Action: domain.AuditCreate}
+		if err := store.AuditAppend(domain.WithTenant(t.Context(), "acme"), e); err != nil {
+			t.Fatal("AuditAppend:", err)
+		}
+		for tenant, want := range map[domain.TenantID]int{"acme": 1, "globex": 0, "": 0} {
+			page, err := store.AuditList(domain.WithTenant(t.Context(), tenant), &domain.AuditQuery{ContactID: e.ContactID})
+			if err != nil {
+				t.Fatal("AuditList:", err)
+			}
+			if len(page.Events) != want {
+				t.Errorf("AuditList of tenant %q got %d events, want %d", tenant, len(page.Events), want)
+			}
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package domaintest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// testBatch tests ContactsBatch, whether atomic or not.
+func testBatch(t *testing.T, ctx context.Context, service domain.Service) {
+	want := NewContact()
+	id, err := service.ContactsCreate(ctx, want)
+	if err != nil {
+		t.Fatal("ContactsCreate:", err)
+	}
+
+	results, err := service.ContactsBatch(ctx, []domain.BatchAction{
+		{Op: domain.BatchCreate, Contact: NewContact()},
+		{Op: domain.BatchUpdate, ID: id, Contact: NewContact()},
+		{Op: domain.BatchDelete, ID: domain.ContactID{UUID: uuid.New()}},
+	})
+	if err != nil {
+		t.Fatal("ContactsBatch:", err)
+	}
+	if len(results) != 3 {
+		t.Fatal("ContactsBatch returned", len(results), "results, want 3")
+	}
+	if !errors.Is(results[2].Err, domain.ErrNotFound) {
+		t.Error("ContactsBatch deleting an unknown contact returned", results[2].Err, "want", domain.ErrNotFound)
+	}
+	switch atomic := errors.Is(results[0].Err, domain.ErrAborted); {
+	case atomic && !errors.Is(results[1].Err, domain.ErrAborted):
+		t.Error("atomic ContactsBatch applied an update:", results[1].Err)
+	case atomic:
+		if got, err := service.ContactsRead(ctx, id); err != nil || !equal(got, want) || got.Version != 1 {
+			t.Error("aborted ContactsBatch altered a stored contact")
+		}
+	case results[0].Err != nil || results[1].Err != nil:
+		t.Error("best-effort ContactsBatch returned", results[0].Err, "and", results[1].Err, "want no errors")
+	case results[0].ID == (domain.ContactID{}) || results[1].ID != id:
+		t.Error("ContactsBatch returned IDs", results[0].ID, "and", results[1].ID)
+	}
+
+	want = NewContact()
+	results, err = service.ContactsBatch(ctx, []domain.BatchAction{
+		{Op: domain.BatchCreate, Contact: want},
+		{Op: domain.BatchDelete, ID: id},
+	})
+	if err != nil {
+		t.Fatal("ContactsBatch:", err)
+	}
+	for n, r := range results {
+		if r.Err != nil {
+			t.Error("ContactsBatch action", n, "failed:", r.Err)
+		}
+	}
+	if got, err := service.ContactsRead(ctx, results[0].ID); err != nil || !equal(got, want) {
+		t.Error("ContactsRead of a contact created by ContactsBatch returned", got, err)
+	}
+	if _, err := service.ContactsRead(ctx, id); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsRead of a contact deleted by ContactsBatch returned", err, "want", domain.ErrNotFound)
+	}
+
+	invalid := []domain.BatchAction{{Op: domain.BatchCreate, Contact: &domain.Contact{}}}
+	results, err = service.ContactsBatch(ctx, invalid)
+	if err != nil || len(results) != 1 || !errors.Is(results[0].Err, domain.ErrInvalid) {
+		t.Error("ContactsBatch creating an invalid contact returned", results, err)
+	}
+
+	_, err = service.ContactsBatch(ctx, make([]domain.BatchAction, domain.ContactsBatchMaxActions+1))
+	if !errors.Is(err, domain.ErrInvalid) {
+		t.Error("ContactsBatch over the limit returned", err, "want", domain.ErrInvalid)
+	}
+}
@@ -0,0 +1,74 @@
+package domaintest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// TestForbidden tests a [domain.Service] fails with [domain.ErrForbidden] exactly for the methods named
+// by forbidden, e.g. "ContactsDelete", when called with a context.
+//
+// Other methods may fail for other reasons, e.g. [domain.ErrNotFound].
+func TestForbidden(t *testing.T, ctx context.Context, service domain.Service, forbidden ...string) {
+	id := domain.ContactID{UUID: uuid.New()}
+	for _, call := range []struct {
+		method string
+		call   func() error
+	}{
+		{"ContactsCreate", func() error {
+			_, err := service.ContactsCreate(ctx, NewContact())
+			return err
+		}},
+		{"ContactsRead", func() error {
+			_, err := service.ContactsRead(ctx, id)
+			return err
+		}},
+		{"ContactsUpdate", func() error {
+			_, err := service.ContactsUpdate(ctx, id, NewContact())
+			return err
+		}},
+		{"ContactsDelete", func() error {
+			return service.ContactsDelete(ctx, id, 0)
+		}},
+		{"ContactsRestore", func() error {
+			return service.ContactsRestore(ctx, id)
+		}},
+		{"ContactsPurge", func() error {
+			return service.ContactsPurge(ctx, id)
+		}},
+		{"ContactsPatch", func() error {
+			_, err := service.ContactsPatch(ctx, id, &domain.ContactPatch{})
+			return err
+		}},
+		{"ContactsList", func() error {
+			_, err := service.ContactsList(ctx, &domain.ContactsQuery{})
+			return err
+		}},
+		{"ContactsBatch", func() error {
+			_, err := service.ContactsBatch(ctx, []domain.BatchAction{{Op: domain.BatchCreate, Contact: NewContact()}})
+			return err
+		}},
+		{"ContactsHistory", func() error {
+			_, err := service.ContactsHistory(ctx, &domain.AuditQuery{ContactID: id})
+			return err
+		}},
+		{"ContactsEvents", func() error {
+			_, err := service.ContactsEvents(ctx, &domain.EventsQuery{})
+			return err
+		}},
+	} {
+		t.Run(call.method, func(t *testing.T) {
+			err := call.call()
+			want := slices.Contains(forbidden, call.method)
+			if got := errors.Is(err, domain.ErrForbidden); got != want {
+				t.Errorf("got %v, want forbidden %t", err, want)
+			}
+		})
+	}
+}
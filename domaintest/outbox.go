@@ -0,0 +1,84 @@
+package domaintest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// testOutbox checks an [domain.OutboxStore] keeps events until deleted, and drops those of aborted atomic calls.
+func (s suite) testOutbox(t *testing.T) {
+	store := s.store.(domain.OutboxStore) //nolint: forcetypeassert // checked by TestStore
+	ctx := s.context(t)
+
+	// the outbox may hold events of other subtests, only ours are looked for
+	fetch := func() []string {
+		t.Helper()
+		events, err := store.OutboxFetch(ctx, 1<<20)
+		if err != nil {
+			t.Fatal("OutboxFetch:", err)
+		}
+		var ids []string
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	event := func(typ domain.EventType) *domain.Event {
+		c := NewContact()
+		c.ID = domain.ContactID{UUID: uuid.New()}
+		return &domain.Event{ID: uuid.NewString(), Type: typ, ContactID: c.ID, Contact: c}
+	}
+
+	created, deleted := event(domain.ContactCreated), event(domain.ContactDeleted)
+	deleted.Contact = nil
+	if err := store.OutboxAppend(ctx, created, deleted); err != nil {
+		t.Fatal("OutboxAppend:", err)
+	}
+	ids := fetch()
+	if n := slices.Index(ids, created.ID); n < 0 || n+1 >= len(ids) || ids[n+1] != deleted.ID {
+		t.Error("OutboxFetch got", ids, "want", created.ID, "then", deleted.ID)
+	}
+
+	events, err := store.OutboxFetch(ctx, 1<<20)
+	if err != nil {
+		t.Fatal("OutboxFetch:", err)
+	}
+	for _, e := range events {
+		if e.ID == created.ID && (e.Type != created.Type || e.ContactID != created.ContactID ||
+			e.Contact == nil || !equal(e.Contact, created.Contact)) {
+			t.Errorf("OutboxFetch got %+v, want %+v", e, created)
+		}
+	}
+
+	if err := store.OutboxDelete(ctx, created.ID, deleted.ID); err != nil {
+		t.Fatal("OutboxDelete:", err)
+	}
+	if ids := fetch(); slices.Contains(ids, created.ID) || slices.Contains(ids, deleted.ID) {
+		t.Error("OutboxFetch got deleted events")
+	}
+
+	atomic, ok := s.store.(domain.AtomicStore)
+	if !ok {
+		return
+	}
+	aborted := event(domain.ContactUpdated)
+	errAbort := errors.New("abort")
+	err = atomic.ContactsAtomic(ctx, func(tx domain.Store) error {
+		err := tx.(domain.OutboxStore).OutboxAppend(ctx, aborted) //nolint: forcetypeassert // atomic outbox
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatal("ContactsAtomic returned", err, "want", errAbort)
+	}
+	if slices.Contains(fetch(), aborted.ID) {
+		t.Error("OutboxFetch got an event of an aborted ContactsAtomic")
+	}
+}
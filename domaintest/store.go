@@ -2,25 +2,679 @@
 package domaintest
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/rlibaert/service-example-go/domain"
 )
 
+// Capability is an optional feature exercised by the conformance suites.
+type Capability int
+
+const (
+	// Concurrency runs operations from concurrent goroutines, best run with -race.
+	Concurrency Capability = iota
+	// TimeZones checks birthdays keep their time zone offset.
+	TimeZones
+	// Listing checks ContactsList filtering, sorting and pagination.
+	Listing
+	// Atomicity checks ContactsAtomic applies all calls or none, if the store is a [domain.AtomicStore].
+	Atomicity
+)
+
+// Options configures the conformance suites.
+type Options struct {
+	skip        map[Capability]bool
+	withContext func(context.Context) context.Context
+}
+
+// OptSkip returns an option opting out of capabilities an implementation does not support.
+func OptSkip(caps ...Capability) func(*Options) {
+	return func(o *Options) {
+		for _, c := range caps {
+			o.skip[c] = true
+		}
+	}
+}
+
+// OptContext returns an option deriving the context of every call, e.g. to authenticate them.
+func OptContext(f func(context.Context) context.Context) func(*Options) {
+	return func(o *Options) { o.withContext = f }
+}
+
+func newOptions(opts []func(*Options)) Options {
+	o := Options{skip: map[Capability]bool{}, withContext: func(ctx context.Context) context.Context { return ctx }}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // TestStore tests a [domain.Store] implementation.
-func TestStore(t *testing.T, store domain.Store) {
-	// extremely basic test
-	id, _ := store.ContactsSet(t.Context(), &domain.Contact{})
-	_, _ = store.ContactsGet(t.Context(), id)
-	_ = store.ContactsReset(t.Context(), id, &domain.Contact{})
-	_ = store.ContactsDel(t.Context(), id)
+//
+// The store is shared by every subtest and may already contain contacts.
+func TestStore(t *testing.T, store domain.Store, opts ...func(*Options)) {
+	suite := suite{store, newOptions(opts)}
+	t.Run("RoundTrip", suite.testRoundTrip)
+	t.Run("NotFound", suite.testNotFound)
+	t.Run("Deleted", suite.testDeleted)
+	t.Run("UniqueIDs", suite.testUniqueIDs)
+	t.Run("Isolation", suite.testIsolation)
+	t.Run("Versions", suite.testVersions)
+	t.Run("Modify", suite.testModify)
+	t.Run("Tenants", suite.testTenants)
+	t.Run("Trash", suite.testTrash)
+	suite.run(t, Listing, "List", suite.testList)
+	suite.run(t, TimeZones, "TimeZones", suite.testTimeZones)
+	suite.run(t, Concurrency, "Concurrency", suite.testConcurrency)
+	if _, ok := store.(domain.AtomicStore); ok {
+		suite.run(t, Atomicity, "Atomic", suite.testAtomic)
+	}
+	if _, ok := store.(domain.OutboxStore); ok {
+		t.Run("Outbox", suite.testOutbox)
+	}
 }
 
 // TestService tests a [domain.Service] implementation.
-func TestService(t *testing.T, service domain.Service) {
-	// extremely basic test
-	id, _ := service.ContactsCreate(t.Context(), &domain.Contact{})
-	_, _ = service.ContactsRead(t.Context(), id)
-	_ = service.ContactsUpdate(t.Context(), id, &domain.Contact{})
-	_ = service.ContactsDelete(t.Context(), id)
+//
+// The service is shared by every subtest and may already contain contacts.
+func TestService(t *testing.T, service domain.Service, opts ...func(*Options)) {
+	TestStore(t, serviceStore{service}, opts...)
+
+	o := newOptions(opts)
+	t.Run("Batch", func(t *testing.T) { testBatch(t, o.withContext(t.Context()), service) })
+}
+
+// serviceStore adapts a [domain.Service] to a [domain.Store] to share the conformance suite.
+type serviceStore struct{ domain.Service }
+
+func (s serviceStore) ContactsSet(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	return s.ContactsCreate(ctx, c)
+}
+
+func (s serviceStore) ContactsGet(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	return s.ContactsRead(ctx, id)
+}
+
+func (s serviceStore) ContactsReset(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
+	_, err := s.ContactsUpdate(ctx, id, c)
+	return err
+}
+
+func (s serviceStore) ContactsDel(ctx context.Context, id domain.ContactID, version int) error {
+	return s.ContactsDelete(ctx, id, version)
+}
+
+func (s serviceStore) ContactsUndel(ctx context.Context, id domain.ContactID) error {
+	return s.ContactsRestore(ctx, id)
+}
+
+// ContactsPurgeBefore is not exposed by services, whose purges are tenant-scoped.
+func (serviceStore) ContactsPurgeBefore(context.Context, time.Time) (int, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (s serviceStore) ContactsModify(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	return s.ContactsPatch(ctx, id, p)
+}
+
+type suite struct {
+	store domain.Store
+	Options
+}
+
+func (s suite) context(t *testing.T) context.Context { return s.withContext(t.Context()) }
+
+func (s suite) run(t *testing.T, c Capability, name string, f func(*testing.T)) {
+	t.Run(name, func(t *testing.T) {
+		if s.skip[c] {
+			t.Skip("capability opted out")
+		}
+		f(t)
+	})
+}
+
+// NewContact returns a valid [domain.Contact] with unique names.
+func NewContact() *domain.Contact {
+	suffix := uuid.NewString()[:8]
+	return &domain.Contact{
+		Firstname: "john" + suffix,
+		Lastname:  "smith" + suffix,
+		Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		Emails:    []domain.Email{{Address: "john" + suffix + "@example.com", Label: domain.LabelHome, Primary: true}},
+		Phones:    []domain.Phone{{Number: "+33123456789", Label: domain.LabelWork}},
+		Addresses: []domain.Address{{
+			Street:     "1 rue de Rivoli",
+			Locality:   "Paris",
+			PostalCode: "75001",
+			Country:    "FR",
+			Label:      domain.LabelHome,
+			Primary:    true,
+		}},
+	}
+}
+
+// equal reports whether two [domain.Contact] hold the same data, IDs excluded.
+func equal(a, b *domain.Contact) bool {
+	return a.Firstname == b.Firstname &&
+		a.Lastname == b.Lastname &&
+		a.Birthday.Equal(b.Birthday) &&
+		slices.Equal(a.Emails, b.Emails) &&
+		slices.Equal(a.Phones, b.Phones) &&
+		slices.Equal(a.Addresses, b.Addresses)
+}
+
+func (s suite) mustSet(t *testing.T, c *domain.Contact) domain.ContactID {
+	t.Helper()
+	id, err := s.store.ContactsSet(s.context(t), c)
+	if err != nil {
+		t.Fatal("ContactsSet:", err)
+	}
+	return id
+}
+
+func (s suite) mustGet(t *testing.T, id domain.ContactID) *domain.Contact {
+	t.Helper()
+	c, err := s.store.ContactsGet(s.context(t), id)
+	if err != nil {
+		t.Fatal("ContactsGet:", err)
+	}
+	return c
+}
+
+func (s suite) testRoundTrip(t *testing.T) {
+	want := NewContact()
+	want.Firstname = "Zoë " + want.Firstname
+	id := s.mustSet(t, want)
+	if id == (domain.ContactID{}) {
+		t.Error("ContactsSet returned the zero ID")
+	}
+
+	got := s.mustGet(t, id)
+	if got.ID != id {
+		t.Error("ContactsGet returned ID", got.ID, "want", id)
+	}
+	if !equal(got, want) {
+		t.Errorf("ContactsGet returned %+v, want %+v", got, want)
+	}
+
+	want = NewContact()
+	want.Birthday = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+	err := s.store.ContactsReset(s.context(t), id, want)
+	if err != nil {
+		t.Fatal("ContactsReset:", err)
+	}
+
+	got = s.mustGet(t, id)
+	if got.ID != id || !equal(got, want) {
+		t.Errorf("ContactsGet after ContactsReset returned %+v, want %+v", got, want)
+	}
+}
+
+func (s suite) testNotFound(t *testing.T) {
+	id := domain.ContactID{UUID: uuid.New()}
+
+	_, err := s.store.ContactsGet(s.context(t), id)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsGet returned", err, "want", domain.ErrNotFound)
+	}
+	err = s.store.ContactsReset(s.context(t), id, NewContact())
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsReset returned", err, "want", domain.ErrNotFound)
+	}
+	err = s.store.ContactsDel(s.context(t), id, 0)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsDel returned", err, "want", domain.ErrNotFound)
+	}
+}
+
+func (s suite) testDeleted(t *testing.T) {
+	id := s.mustSet(t, NewContact())
+
+	err := s.store.ContactsDel(s.context(t), id, 0)
+	if err != nil {
+		t.Fatal("ContactsDel:", err)
+	}
+
+	_, err = s.store.ContactsGet(s.context(t), id)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsGet returned", err, "want", domain.ErrNotFound)
+	}
+	err = s.store.ContactsReset(s.context(t), id, NewContact())
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsReset returned", err, "want", domain.ErrNotFound)
+	}
+	err = s.store.ContactsDel(s.context(t), id, 0)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("second ContactsDel returned", err, "want", domain.ErrNotFound)
+	}
+}
+
+func (s suite) testTenants(t *testing.T) {
+	tenant := domain.TenantID("tenant-" + uuid.NewString())
+	owner := domain.WithTenant(s.context(t), tenant)
+	other := domain.WithTenant(s.context(t), tenant+"-other")
+
+	c := NewContact()
+	id, err := s.store.ContactsSet(owner, c)
+	if err != nil {
+		t.Fatal("ContactsSet:", err)
+	}
+
+	for name, ctx := range map[string]context.Context{"other": other, "default": s.context(t)} {
+		_, err = s.store.ContactsGet(ctx, id)
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Error(name, "tenant ContactsGet returned", err, "want", domain.ErrNotFound)
+		}
+		err = s.store.ContactsReset(ctx, id, NewContact())
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Error(name, "tenant ContactsReset returned", err, "want", domain.ErrNotFound)
+		}
+		_, err = s.store.ContactsModify(ctx, id, &domain.ContactPatch{})
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Error(name, "tenant ContactsModify returned", err, "want", domain.ErrNotFound)
+		}
+		err = s.store.ContactsDel(ctx, id, 0)
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Error(name, "tenant ContactsDel returned", err, "want", domain.ErrNotFound)
+		}
+		page, err := s.store.ContactsList(ctx, &domain.ContactsQuery{NamePrefix: c.Firstname})
+		if err != nil {
+			t.Fatal(name, "tenant ContactsList:", err)
+		}
+		if len(page.Contacts) != 0 {
+			t.Error(name, "tenant ContactsList returned", len(page.Contacts), "contacts, want none")
+		}
+	}
+
+	got, err := s.store.ContactsGet(owner, id)
+	if err != nil {
+		t.Fatal("owner tenant ContactsGet:", err)
+	}
+	if !equal(got, c) {
+		t.Error("owner tenant ContactsGet returned", got, "want", c)
+	}
+	page, err := s.store.ContactsList(owner, &domain.ContactsQuery{NamePrefix: c.Firstname})
+	if err != nil {
+		t.Fatal("owner tenant ContactsList:", err)
+	}
+	if len(page.Contacts) != 1 || page.Contacts[0].ID != id {
+		t.Error("owner tenant ContactsList returned", page.Contacts, "want", id)
+	}
+}
+
+func (s suite) testTrash(t *testing.T) {
+	c := NewContact()
+	id := s.mustSet(t, c)
+	version := s.mustGet(t, id).Version
+	err := s.store.ContactsDel(s.context(t), id, 0)
+	if err != nil {
+		t.Fatal("ContactsDel:", err)
+	}
+
+	trashed := func() *domain.Contact {
+		t.Helper()
+		page, err := s.store.ContactsList(s.context(t), &domain.ContactsQuery{NamePrefix: c.Firstname, Trashed: true})
+		if err != nil {
+			t.Fatal("ContactsList of the trash:", err)
+		}
+		if len(page.Contacts) > 1 || len(page.Contacts) == 1 && page.Contacts[0].ID != id {
+			t.Fatal("ContactsList of the trash returned", page.Contacts)
+		}
+		if len(page.Contacts) == 0 {
+			return nil
+		}
+		return page.Contacts[0]
+	}
+	if got := trashed(); got == nil || !equal(got, c) || got.DeletedAt.IsZero() {
+		t.Errorf("ContactsList of the trash returned %+v, want %+v with a deletion time", got, c)
+	}
+	page, err := s.store.ContactsList(s.context(t), &domain.ContactsQuery{NamePrefix: c.Firstname})
+	if err != nil || len(page.Contacts) != 0 {
+		t.Error("ContactsList returned", page, err, "want no trashed contact")
+	}
+
+	err = s.store.ContactsUndel(s.context(t), id)
+	if err != nil {
+		t.Fatal("ContactsUndel:", err)
+	}
+	if got := s.mustGet(t, id); !equal(got, c) || !got.DeletedAt.IsZero() || got.Version != version+1 {
+		t.Errorf("ContactsGet after ContactsUndel returned %+v, want %+v at version %d", got, c, version+1)
+	}
+	if trashed() != nil {
+		t.Error("ContactsList of the trash returned a restored contact")
+	}
+	err = s.store.ContactsUndel(s.context(t), id)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsUndel of a contact out of the trash returned", err, "want", domain.ErrNotFound)
+	}
+	err = s.store.ContactsPurge(s.context(t), id)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsPurge of a contact out of the trash returned", err, "want", domain.ErrNotFound)
+	}
+
+	err = s.store.ContactsDel(s.context(t), id, 0)
+	if err == nil {
+		err = s.store.ContactsPurge(s.context(t), id)
+	}
+	if err != nil {
+		t.Fatal("ContactsDel and ContactsPurge:", err)
+	}
+	if trashed() != nil {
+		t.Error("ContactsList of the trash returned a purged contact")
+	}
+	err = s.store.ContactsUndel(s.context(t), id)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsUndel of a purged contact returned", err, "want", domain.ErrNotFound)
+	}
+
+	// deletion times are kept by the store, whatever the contacts written
+	deleted := NewContact()
+	deleted.DeletedAt = time.Now()
+	id = s.mustSet(t, deleted)
+	if got := s.mustGet(t, id); !got.DeletedAt.IsZero() {
+		t.Error("ContactsSet of a contact with a deletion time moved it to the trash:", got.DeletedAt)
+	}
+	err = s.store.ContactsReset(s.context(t), id, deleted)
+	if err != nil {
+		t.Fatal("ContactsReset:", err)
+	}
+	if got := s.mustGet(t, id); !got.DeletedAt.IsZero() {
+		t.Error("ContactsReset with a deletion time moved the contact to the trash:", got.DeletedAt)
+	}
+
+	id = s.mustSet(t, c)
+	err = s.store.ContactsDel(s.context(t), id, 0)
+	if err != nil {
+		t.Fatal("ContactsDel:", err)
+	}
+	n, err := s.store.ContactsPurgeBefore(s.context(t), time.Now().Add(-time.Hour))
+	if errors.Is(err, errors.ErrUnsupported) {
+		return
+	}
+	if err != nil || trashed() == nil {
+		t.Fatal("ContactsPurgeBefore of past deletions purged", n, "contacts:", err)
+	}
+	n, err = s.store.ContactsPurgeBefore(s.context(t), time.Now().Add(time.Second))
+	if err != nil || n < 1 || trashed() != nil {
+		t.Error("ContactsPurgeBefore purged", n, "contacts:", err)
+	}
+}
+
+func (s suite) testUniqueIDs(t *testing.T) {
+	const n = 100
+	ids := map[domain.ContactID]bool{}
+	for range n {
+		ids[s.mustSet(t, NewContact())] = true
+	}
+	if len(ids) != n {
+		t.Error("ContactsSet returned", n-len(ids), "duplicate IDs")
+	}
+}
+
+func (s suite) testIsolation(t *testing.T) {
+	mutate := func(c *domain.Contact) {
+		c.Firstname = "mutated"
+		c.Emails[0].Address = "mutated@example.com"
+		c.Phones[0].Number = "+33987654321"
+		c.Addresses[0].Locality = "mutated"
+	}
+
+	c := NewContact()
+	want := c.Clone()
+	id := s.mustSet(t, c)
+
+	mutate(c)
+	if got := s.mustGet(t, id); !equal(got, want) {
+		t.Error("mutating a set contact altered the stored one")
+	}
+
+	mutate(s.mustGet(t, id))
+	if got := s.mustGet(t, id); !equal(got, want) {
+		t.Error("mutating a retrieved contact altered the stored one")
+	}
+
+	c = NewContact()
+	want = c.Clone()
+	err := s.store.ContactsReset(s.context(t), id, c)
+	if err != nil {
+		t.Fatal("ContactsReset:", err)
+	}
+
+	mutate(c)
+	if got := s.mustGet(t, id); !equal(got, want) {
+		t.Error("mutating a reset contact altered the stored one")
+	}
+}
+
+func (s suite) testVersions(t *testing.T) {
+	id := s.mustSet(t, NewContact())
+	c := s.mustGet(t, id)
+	if c.Version != 1 {
+		t.Error("ContactsGet returned version", c.Version, "want 1")
+	}
+
+	err := s.store.ContactsReset(s.context(t), id, c)
+	if err != nil {
+		t.Fatal("ContactsReset:", err)
+	}
+	if got := s.mustGet(t, id); got.Version != 2 {
+		t.Error("ContactsGet after ContactsReset returned version", got.Version, "want 2")
+	}
+
+	err = s.store.ContactsReset(s.context(t), id, c)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Error("ContactsReset with a stale version returned", err, "want", domain.ErrConflict)
+	}
+	err = s.store.ContactsDel(s.context(t), id, c.Version)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Error("ContactsDel with a stale version returned", err, "want", domain.ErrConflict)
+	}
+	err = s.store.ContactsDel(s.context(t), id, c.Version+1)
+	if err != nil {
+		t.Error("ContactsDel with the current version:", err)
+	}
+}
+
+func (s suite) testModify(t *testing.T) {
+	want := NewContact()
+	id := s.mustSet(t, want)
+
+	_, err := s.store.ContactsModify(s.context(t), domain.ContactID{UUID: uuid.New()}, &domain.ContactPatch{})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsModify returned", err, "want", domain.ErrNotFound)
+	}
+
+	aborted := "aborted"
+	_, err = s.store.ContactsModify(s.context(t), id, &domain.ContactPatch{Version: 2, Firstname: &aborted})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Error("ContactsModify with a stale version returned", err, "want", domain.ErrConflict)
+	}
+	if got := s.mustGet(t, id); !equal(got, want) || got.Version != 1 {
+		t.Error("conflicting ContactsModify altered the stored contact")
+	}
+
+	want.Lastname += "modified"
+	want.Phones = nil
+	got, err := s.store.ContactsModify(s.context(t), id, &domain.ContactPatch{
+		Version:  1,
+		Lastname: &want.Lastname,
+		Phones:   &[]domain.Phone{},
+	})
+	if err != nil {
+		t.Fatal("ContactsModify:", err)
+	}
+	if got.ID != id || got.Version != 2 || !equal(got, want) {
+		t.Errorf("ContactsModify returned %+v, want %+v", got, want)
+	}
+	if got := s.mustGet(t, id); got.Version != 2 || !equal(got, want) {
+		t.Errorf("ContactsGet after ContactsModify returned %+v, want %+v", got, want)
+	}
+}
+
+func (s suite) testAtomic(t *testing.T) {
+	store := s.store.(domain.AtomicStore) //nolint: forcetypeassert // checked by TestStore
+	want := NewContact()
+	id := s.mustSet(t, want)
+
+	var created domain.ContactID
+	errAbort := errors.New("abort")
+	err := store.ContactsAtomic(s.context(t), func(tx domain.Store) error {
+		var err error
+		created, err = tx.ContactsSet(s.context(t), NewContact())
+		if err != nil {
+			return err
+		}
+		err = tx.ContactsReset(s.context(t), id, NewContact())
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ContactsGet(s.context(t), created); err != nil {
+			t.Error("ContactsGet of a contact created in the same call:", err)
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatal("ContactsAtomic returned", err, "want", errAbort)
+	}
+	if got := s.mustGet(t, id); !equal(got, want) || got.Version != 1 {
+		t.Error("aborted ContactsAtomic altered a stored contact")
+	}
+	if _, err := s.store.ContactsGet(s.context(t), created); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsGet of a contact created by an aborted ContactsAtomic returned", err)
+	}
+
+	want = NewContact()
+	err = store.ContactsAtomic(s.context(t), func(tx domain.Store) error {
+		var err error
+		created, err = tx.ContactsSet(s.context(t), want)
+		if err != nil {
+			return err
+		}
+		return tx.ContactsDel(s.context(t), id, 0)
+	})
+	if err != nil {
+		t.Fatal("ContactsAtomic:", err)
+	}
+	if got := s.mustGet(t, created); !equal(got, want) {
+		t.Error("ContactsGet after ContactsAtomic returned", got, "want", want)
+	}
+	if _, err := s.store.ContactsGet(s.context(t), id); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("ContactsGet of a contact deleted by ContactsAtomic returned", err)
+	}
+}
+
+func (s suite) testTimeZones(t *testing.T) {
+	want := NewContact()
+	want.Birthday = time.Date(1999, time.December, 31, 23, 0, 0, 0, time.FixedZone("", -5*60*60))
+	got := s.mustGet(t, s.mustSet(t, want))
+
+	_, gotOffset := got.Birthday.Zone()
+	_, wantOffset := want.Birthday.Zone()
+	if !got.Birthday.Equal(want.Birthday) || gotOffset != wantOffset {
+		t.Error("ContactsGet returned birthday", got.Birthday, "want", want.Birthday)
+	}
+}
+
+func (s suite) testList(t *testing.T) {
+	// contacts share a unique prefix to ignore those already stored
+	prefix := "list" + uuid.NewString()[:8]
+	var want []*domain.Contact
+	for n := range 5 {
+		c := NewContact()
+		c.Lastname = fmt.Sprint(prefix, 4-n)
+		c.Birthday = time.Date(1990+n, time.January, 1, 0, 0, 0, 0, time.UTC)
+		c.ID = s.mustSet(t, c)
+		want = append(want, c)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query domain.ContactsQuery
+		want  []*domain.Contact
+	}{
+		{"lastname", domain.ContactsQuery{Sort: domain.ContactsSortLastname}, []*domain.Contact{
+			want[4], want[3], want[2], want[1], want[0],
+		}},
+		{"birthday", domain.ContactsQuery{Sort: domain.ContactsSortBirthday}, want},
+		{"born", domain.ContactsQuery{
+			Sort:       domain.ContactsSortBirthday,
+			BornAfter:  want[0].Birthday,
+			BornBefore: want[4].Birthday,
+		}, want[1:4]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := tc.query
+			q.NamePrefix = prefix
+			q.Limit = 2
+
+			var got []*domain.Contact
+			for {
+				page, err := s.store.ContactsList(s.context(t), &q)
+				if err != nil {
+					t.Fatal("ContactsList:", err)
+				}
+				if len(page.Contacts) > q.Limit {
+					t.Fatal("ContactsList returned", len(page.Contacts), "contacts over limit", q.Limit)
+				}
+				got = append(got, page.Contacts...)
+				if page.Next == "" {
+					break
+				}
+				q.Cursor = page.Next
+			}
+
+			if !slices.EqualFunc(got, tc.want, func(a, b *domain.Contact) bool { return a.ID == b.ID && equal(a, b) }) {
+				t.Error("ContactsList returned", len(got), "contacts not matching the", len(tc.want), "expected")
+			}
+		})
+	}
+}
+
+func (s suite) testConcurrency(t *testing.T) {
+	const workers, ops = 8, 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*ops)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ops {
+				id, err := s.store.ContactsSet(s.context(t), NewContact())
+				if err == nil {
+					_, err = s.store.ContactsGet(s.context(t), id)
+				}
+				if err == nil {
+					err = s.store.ContactsReset(s.context(t), id, NewContact())
+				}
+				if err == nil && !s.skip[Listing] {
+					_, err = s.store.ContactsList(s.context(t), &domain.ContactsQuery{Limit: 1})
+				}
+				if err == nil {
+					err = s.store.ContactsDel(s.context(t), id, 0)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
 }
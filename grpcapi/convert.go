@@ -0,0 +1,127 @@
+package grpcapi
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/grpcapi/contactsv1"
+)
+
+// invalid returns a [domain.ErrInvalid] error of a field.
+func invalid(field string, value any, err error) error {
+	return errors.Join(domain.ErrInvalid, &domain.FieldError{Field: field, Value: value, Err: err})
+}
+
+func contactID(s string) (domain.ContactID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return domain.ContactID{}, invalid("id", s, err)
+	}
+	return domain.ContactID{UUID: id}, nil
+}
+
+// date parses an optional YYYY-MM-DD date, zero if empty.
+func date(field, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, invalid(field, s, errors.New("invalid format, want YYYY-MM-DD"))
+	}
+	return t, nil
+}
+
+func contactMessage(c *domain.Contact) *contactsv1.Contact {
+	m := &contactsv1.Contact{
+		Id:        c.ID.String(),
+		Version:   int64(c.Version),
+		Firstname: c.Firstname,
+		Lastname:  c.Lastname,
+		Birthday:  c.Birthday.Format(time.DateOnly),
+	}
+	for _, e := range c.Emails {
+		m.Emails = append(m.Emails, &contactsv1.Email{Address: e.Address, Label: string(e.Label), Primary: e.Primary})
+	}
+	for _, p := range c.Phones {
+		m.Phones = append(m.Phones, &contactsv1.Phone{Number: p.Number, Label: string(p.Label), Primary: p.Primary})
+	}
+	for _, a := range c.Addresses {
+		m.Addresses = append(m.Addresses, &contactsv1.Address{
+			Street:     a.Street,
+			Locality:   a.Locality,
+			Region:     a.Region,
+			PostalCode: a.PostalCode,
+			Country:    a.Country,
+			Label:      string(a.Label),
+			Primary:    a.Primary,
+		})
+	}
+	return m
+}
+
+// contact returns the [domain.Contact] described by a message, ID excluded.
+func contact(m *contactsv1.Contact) (*domain.Contact, error) {
+	if m == nil {
+		return nil, invalid("contact", nil, errors.New("missing contact"))
+	}
+	birthday, err := date("birthday", m.GetBirthday())
+	if err != nil {
+		return nil, err
+	}
+
+	c := &domain.Contact{
+		Version:   int(m.GetVersion()),
+		Firstname: m.GetFirstname(),
+		Lastname:  m.GetLastname(),
+		Birthday:  birthday,
+	}
+	for _, e := range m.GetEmails() {
+		c.Emails = append(c.Emails, domain.Email{
+			Address: e.GetAddress(),
+			Label:   domain.Label(e.GetLabel()),
+			Primary: e.GetPrimary(),
+		})
+	}
+	for _, p := range m.GetPhones() {
+		c.Phones = append(c.Phones, domain.Phone{
+			Number:  p.GetNumber(),
+			Label:   domain.Label(p.GetLabel()),
+			Primary: p.GetPrimary(),
+		})
+	}
+	for _, a := range m.GetAddresses() {
+		c.Addresses = append(c.Addresses, domain.Address{
+			Street:     a.GetStreet(),
+			Locality:   a.GetLocality(),
+			Region:     a.GetRegion(),
+			PostalCode: a.GetPostalCode(),
+			Country:    a.GetCountry(),
+			Label:      domain.Label(a.GetLabel()),
+			Primary:    a.GetPrimary(),
+		})
+	}
+	return c, nil
+}
+
+func query(req *contactsv1.ListContactsRequest) (*domain.ContactsQuery, error) {
+	bornAfter, err := date("born_after", req.GetBornAfter())
+	if err != nil {
+		return nil, err
+	}
+	bornBefore, err := date("born_before", req.GetBornBefore())
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ContactsQuery{
+		Sort:       domain.ContactsSort(req.GetSort()),
+		NamePrefix: req.GetNamePrefix(),
+		BornAfter:  bornAfter,
+		BornBefore: bornBefore,
+		Limit:      int(req.GetLimit()),
+		Cursor:     req.GetCursor(),
+	}, nil
+}
@@ -0,0 +1,190 @@
+// Package grpcapi provide primitives to expose [domain] with a gRPC interface.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/grpcapi/contactsv1"
+)
+
+// NewServer returns a [grpc.Server] exposing a [domain.Service] along with the standard health and reflection
+// services, and the [health.Server] to report its health with, serving until shut down.
+func NewServer(service domain.Service, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(opts...)
+	contactsv1.RegisterContactsServer(server, &ContactsServer{Service: service})
+	h := health.NewServer()
+	healthpb.RegisterHealthServer(server, h)
+	reflection.Register(server)
+	return server, h
+}
+
+// ContactsServer exposes a [domain.Service] as a [contactsv1.ContactsServer].
+type ContactsServer struct {
+	contactsv1.UnimplementedContactsServer
+
+	Service domain.Service
+}
+
+func (s *ContactsServer) CreateContact(
+	ctx context.Context,
+	req *contactsv1.CreateContactRequest,
+) (*contactsv1.CreateContactResponse, error) {
+	c, err := contact(req.GetContact())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	id, err := s.Service.ContactsCreate(ctx, c)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &contactsv1.CreateContactResponse{Id: id.String()}, nil
+}
+
+func (s *ContactsServer) ReadContact(
+	ctx context.Context,
+	req *contactsv1.ReadContactRequest,
+) (*contactsv1.Contact, error) {
+	id, err := contactID(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	c, err := s.Service.ContactsRead(ctx, id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return contactMessage(c), nil
+}
+
+func (s *ContactsServer) UpdateContact(
+	ctx context.Context,
+	req *contactsv1.UpdateContactRequest,
+) (*contactsv1.UpdateContactResponse, error) {
+	id, err := contactID(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	c, err := contact(req.GetContact())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	_, err = s.Service.ContactsUpdate(ctx, id, c)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &contactsv1.UpdateContactResponse{}, nil
+}
+
+func (s *ContactsServer) DeleteContact(
+	ctx context.Context,
+	req *contactsv1.DeleteContactRequest,
+) (*contactsv1.DeleteContactResponse, error) {
+	id, err := contactID(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	err = s.Service.ContactsDelete(ctx, id, int(req.GetVersion()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &contactsv1.DeleteContactResponse{}, nil
+}
+
+func (s *ContactsServer) ListContacts(
+	ctx context.Context,
+	req *contactsv1.ListContactsRequest,
+) (*contactsv1.ListContactsResponse, error) {
+	q, err := query(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	page, err := s.Service.ContactsList(ctx, q)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &contactsv1.ListContactsResponse{
+		Contacts: make([]*contactsv1.Contact, len(page.Contacts)),
+		Next:     page.Next,
+	}
+	for n, c := range page.Contacts {
+		resp.Contacts[n] = contactMessage(c)
+	}
+	return resp, nil
+}
+
+// statusCodes maps [domain] errors to gRPC status codes.
+var statusCodes = []struct { //nolint: gochecknoglobals // read-only table
+	err  error
+	code codes.Code
+}{
+	{domain.ErrNotFound, codes.NotFound},
+	{domain.ErrInvalid, codes.InvalidArgument},
+	{domain.ErrConflict, codes.Aborted},
+	{domain.ErrForbidden, codes.PermissionDenied},
+	{domain.ErrAborted, codes.Aborted},
+	{errors.ErrUnsupported, codes.Unimplemented},
+}
+
+// statusError translates an error returned by a [domain.Service] into a gRPC status error,
+// describing field errors as [errdetails.BadRequest] field violations.
+//
+// Errors already carrying a status are returned as is,
+// others unknown to [statusCodes] are hidden behind a [codes.Internal] status.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	for _, c := range statusCodes {
+		if !errors.Is(err, c.err) {
+			continue
+		}
+		st := status.New(c.code, err.Error())
+		if violations := fieldViolations(err); len(violations) > 0 {
+			withDetails, derr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+			if derr == nil {
+				st = withDetails
+			}
+		}
+		return st.Err()
+	}
+
+	return status.Error(codes.Internal, "unexpected error occurred")
+}
+
+// fieldViolations walks an error tree to collect every [domain.FieldError] as a field violation.
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	switch err := err.(type) { //nolint: errorlint // walking the tree ourselves
+	case *domain.FieldError:
+		return []*errdetails.BadRequest_FieldViolation{{Field: err.Field, Description: err.Err.Error()}}
+	case interface{ Unwrap() error }:
+		return fieldViolations(err.Unwrap())
+	case interface{ Unwrap() []error }:
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, err := range err.Unwrap() {
+			violations = append(violations, fieldViolations(err)...)
+		}
+		return violations
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,254 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/grpcapi"
+	"github.com/rlibaert/service-example-go/grpcapi/contactsv1"
+	"github.com/rlibaert/service-example-go/router"
+	"github.com/rlibaert/service-example-go/stores"
+)
+
+// dial serves a [domain.Service] and returns a client connection to it.
+func dial(t *testing.T, service domain.Service, opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+	server, health := grpcapi.NewServer(service, opts...)
+	health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis) //nolint: errcheck // stopped with the test
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func code(err error) codes.Code { return status.Code(err) }
+
+func TestContacts(t *testing.T) {
+	conn := dial(t, &domain.ServiceStore{Store: stores.MustNewMock()})
+	client := contactsv1.NewContactsClient(conn)
+	ctx := t.Context()
+
+	created, err := client.CreateContact(ctx, &contactsv1.CreateContactRequest{Contact: &contactsv1.Contact{
+		Firstname: "john",
+		Lastname:  "smith",
+		Birthday:  "1999-12-31",
+		Emails:    []*contactsv1.Email{{Address: "john@example.com", Label: "work"}},
+	}})
+	if err != nil {
+		t.Fatal("CreateContact:", err)
+	}
+
+	c, err := client.ReadContact(ctx, &contactsv1.ReadContactRequest{Id: created.GetId()})
+	if err != nil {
+		t.Fatal("ReadContact:", err)
+	}
+	if c.GetFirstname() != "john" || c.GetBirthday() != "1999-12-31" || c.GetVersion() != 1 ||
+		len(c.GetEmails()) != 1 || c.GetEmails()[0].GetLabel() != "work" {
+		t.Errorf("ReadContact got %v", c)
+	}
+
+	c.Firstname = "jane"
+	_, err = client.UpdateContact(ctx, &contactsv1.UpdateContactRequest{Id: c.GetId(), Contact: c})
+	if err != nil {
+		t.Fatal("UpdateContact:", err)
+	}
+	_, err = client.UpdateContact(ctx, &contactsv1.UpdateContactRequest{Id: c.GetId(), Contact: c})
+	if code(err) != codes.Aborted {
+		t.Error("UpdateContact with a stale version returned", err)
+	}
+
+	_, err = client.CreateContact(ctx, &contactsv1.CreateContactRequest{Contact: &contactsv1.Contact{
+		Firstname: "jim",
+		Lastname:  "smith",
+		Birthday:  "1999-12-31",
+	}})
+	if err != nil {
+		t.Fatal("CreateContact:", err)
+	}
+	var names []string
+	for req := (&contactsv1.ListContactsRequest{Sort: "firstname", Limit: 1}); ; {
+		page, err := client.ListContacts(ctx, req)
+		if err != nil {
+			t.Fatal("ListContacts:", err)
+		}
+		for _, c := range page.GetContacts() {
+			names = append(names, c.GetFirstname())
+		}
+		if page.GetNext() == "" {
+			break
+		}
+		req.Cursor = page.GetNext()
+	}
+	if want := []string{"jane", "jim"}; !slices.Equal(names, want) {
+		t.Error("ListContacts got", names, "want", want)
+	}
+
+	_, err = client.DeleteContact(ctx, &contactsv1.DeleteContactRequest{Id: c.GetId()})
+	if err != nil {
+		t.Fatal("DeleteContact:", err)
+	}
+	_, err = client.ReadContact(ctx, &contactsv1.ReadContactRequest{Id: c.GetId()})
+	if code(err) != codes.NotFound {
+		t.Error("ReadContact of a deleted contact returned", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	conn := dial(t, &domain.ServiceStore{Store: stores.MustNewMock()})
+	client := contactsv1.NewContactsClient(conn)
+	ctx := t.Context()
+
+	for name, tt := range map[string]struct {
+		call   func() error
+		fields []string
+	}{
+		"malformed id": {func() error {
+			_, err := client.ReadContact(ctx, &contactsv1.ReadContactRequest{Id: "42"})
+			return err
+		}, []string{"id"}},
+		"missing contact": {func() error {
+			_, err := client.CreateContact(ctx, &contactsv1.CreateContactRequest{})
+			return err
+		}, []string{"contact"}},
+		"invalid contact": {func() error {
+			_, err := client.CreateContact(ctx, &contactsv1.CreateContactRequest{Contact: &contactsv1.Contact{
+				Firstname: "john",
+				Lastname:  "smith",
+				Birthday:  "1999-12-31",
+				Phones:    []*contactsv1.Phone{{Number: "not a number"}},
+			}})
+			return err
+		}, []string{"phones[0].number"}},
+		"invalid query": {func() error {
+			_, err := client.ListContacts(ctx, &contactsv1.ListContactsRequest{Sort: "age", BornAfter: "yesterday"})
+			return err
+		}, []string{"born_after"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			st := status.Convert(tt.call())
+			if st.Code() != codes.InvalidArgument {
+				t.Fatal("got", st.Code(), st.Message())
+			}
+			var fields []string
+			for _, d := range st.Details() {
+				if br, ok := d.(*errdetails.BadRequest); ok {
+					for _, v := range br.GetFieldViolations() {
+						fields = append(fields, v.GetField())
+					}
+				}
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Error("got field violations", fields, "want", tt.fields)
+			}
+		})
+	}
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	conn := dial(t, &domain.ServiceStore{Store: stores.MustNewMock()})
+
+	resp, err := healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Error("Check got", resp, err)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var services []string
+	for _, s := range info.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	if !slices.Contains(services, contactsv1.Contacts_ServiceDesc.ServiceName) {
+		t.Error("reflection lists", services)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	store := stores.MustNewMock()
+	conn := dial(t, &domain.ServiceStore{Store: store}, grpc.ChainUnaryInterceptor(
+		grpcapi.SkipHealth(grpcapi.AuthInterceptor(map[string]router.Authenticator{
+			"apikey": router.APIKeyAuthenticator("X-API-Key", map[string]*router.Principal{
+				"secret":  {Subject: "ci"},
+				"acme":    {Subject: "alice", Tenant: "acme"},
+				"initech": {Subject: "bob", Tenant: "initech"},
+			}),
+		})),
+		grpcapi.SkipHealth(grpcapi.TenantInterceptor("x-tenant-id")),
+	))
+	client := contactsv1.NewContactsClient(conn)
+	list := func(ctx context.Context) (int, error) {
+		page, err := client.ListContacts(ctx, &contactsv1.ListContactsRequest{})
+		return len(page.GetContacts()), err
+	}
+
+	if _, err := list(t.Context()); code(err) != codes.Unauthenticated {
+		t.Error("ListContacts without credentials returned", err)
+	}
+	// probes check the health without credentials, whatever their metadata
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "x-tenant-id", "acme")
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Error("Check without credentials got", resp, err)
+	}
+	if _, err := list(metadata.AppendToOutgoingContext(t.Context(), "x-api-key", "wrong")); code(err) !=
+		codes.Unauthenticated {
+		t.Error("ListContacts with a wrong key returned", err)
+	}
+
+	_, err = store.ContactsSet(domain.WithTenant(t.Context(), "acme"), &domain.Contact{Firstname: "john"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		md   []string
+		want int
+	}{
+		{"no tenant", []string{"x-api-key", "secret"}, 0},
+		{"tenant", []string{"x-api-key", "acme"}, 1},
+		{"principal tenant", []string{"x-api-key", "initech", "x-tenant-id", "acme"}, 0},
+	} {
+		n, err := list(metadata.AppendToOutgoingContext(t.Context(), tt.md...))
+		if err != nil || n != tt.want {
+			t.Errorf("ListContacts with %s got %d contacts, %v, want %d", tt.name, n, err, tt.want)
+		}
+	}
+	ctx = metadata.AppendToOutgoingContext(t.Context(), "x-api-key", "secret", "x-tenant-id", "acme")
+	if _, err := list(ctx); code(err) != codes.PermissionDenied {
+		t.Error("ListContacts choosing a tenant returned", err)
+	}
+}
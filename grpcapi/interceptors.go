@@ -0,0 +1,104 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/router"
+)
+
+// AuthInterceptor returns a [grpc.UnaryServerInterceptor] authenticating calls like [router.OptAuth] does requests,
+// with bearer tokens in the "authorization" metadata and API keys in the metadata named after their header.
+// Calls without valid credentials fail with [codes.Unauthenticated], others carry their [router.Principal].
+func AuthInterceptor(authenticators map[string]router.Authenticator) grpc.UnaryServerInterceptor {
+	names := slices.Sorted(maps.Keys(authenticators))
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var errs []error
+		for _, name := range names {
+			a := authenticators[name]
+			credentials := metadataCredentials(md, a)
+			if credentials == "" {
+				continue
+			}
+			p, err := a.Authenticate(ctx, credentials)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			principal := *p
+			principal.Scheme = name
+			return handler(router.WithPrincipal(ctx, &principal), req)
+		}
+
+		if len(errs) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "credentials required")
+		}
+		return nil, status.Error(codes.Unauthenticated, errors.Join(errs...).Error())
+	}
+}
+
+// metadataCredentials returns the credentials of an [router.Authenticator] in metadata, empty if none.
+func metadataCredentials(md metadata.MD, a router.Authenticator) string {
+	switch a.Scheme.Type {
+	case "http":
+		for _, v := range md.Get("authorization") {
+			if scheme, credentials, ok := strings.Cut(v, " "); ok && strings.EqualFold(scheme, a.Scheme.Scheme) {
+				return credentials
+			}
+		}
+	case "apiKey":
+		if v := md.Get(a.Scheme.Name); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// TenantInterceptor returns a [grpc.UnaryServerInterceptor] scoping calls to the tenant of their [router.Principal],
+// or else to that of a metadata header unless empty, e.g. "x-tenant-id".
+//
+// Calls of a principal without tenant fail with [codes.PermissionDenied] if the header sets one,
+// for principals not to choose their tenant.
+func TenantInterceptor(header string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var tenant string
+		p := router.PrincipalFrom(ctx)
+		if p != nil {
+			tenant = p.Tenant
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok && tenant == "" && header != "" {
+			if v := md.Get(header); len(v) > 0 && v[0] != "" {
+				if p != nil {
+					return nil, status.Errorf(codes.PermissionDenied, "tenant %q not granted to the principal", v[0])
+				}
+				tenant = v[0]
+			}
+		}
+		ctx = router.WithTenant(ctx, tenant)
+		return handler(domain.WithTenant(ctx, domain.TenantID(tenant)), req)
+	}
+}
+
+// SkipHealth wraps a [grpc.UnaryServerInterceptor] not to intercept the calls to the health service,
+// e.g. for probes not to authenticate.
+func SkipHealth(interceptor grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	prefix := "/" + healthpb.Health_ServiceDesc.ServiceName + "/"
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, prefix) {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/rlibaert/service-example-go/cli/api"
+	"github.com/rlibaert/service-example-go/cli/logger"
+	"github.com/rlibaert/service-example-go/contactcsv"
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/restapi"
+)
+
+// importCSVCommand returns the command importing contacts from a CSV file straight into the store.
+//
+// It prints the report of the import as JSON and fails if any row could not be imported.
+func importCSVCommand() *cobra.Command {
+	var (
+		file    string
+		columns []string
+		tenant  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import-csv",
+		Short: "Import contacts from a CSV file into the store",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, options *Options) {
+			logger := logger.New(&options.Logger)
+			if options.StoreDSN == "" {
+				logger.Warn("importing into the in-memory store, set --store-dsn to keep the contacts")
+			}
+
+			ctx := api.WithLogger(cmd.Context(), logger)
+			report, err := importCSV(ctx, options, file, columns, domain.TenantID(tenant))
+			if report != nil {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				_ = enc.Encode(report)
+			}
+			switch {
+			case err != nil:
+				logger.Error("could not import contacts", "err", err)
+				os.Exit(1)
+			case report.Failed > 0:
+				logger.Error("some contacts could not be imported", "created", report.Created, "failed", report.Failed)
+				os.Exit(1)
+			default:
+				logger.Info("contacts imported", "created", report.Created)
+			}
+		}),
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", "CSV file to import, - for stdin")
+	cmd.Flags().StringArrayVar(&columns, "column", nil,
+		"column mapping as header=field, e.g. 'Work Email=email:work'; columns named after fields by default")
+	cmd.Flags().StringVar(&tenant, "tenant", "", "tenant of the imported contacts")
+	return cmd
+}
+
+func importCSV(
+	ctx context.Context,
+	options *Options,
+	file string,
+	columns []string,
+	tenant domain.TenantID,
+) (*restapi.ImportReportModel, error) {
+	var mapping contactcsv.Mapping
+	if len(columns) > 0 {
+		var err error
+		mapping, err = contactcsv.ParseMapping(columns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := io.Reader(os.Stdin)
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	store, err := api.NewStore(ctx, &options.StoreOptions)
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// the operator importing straight into the store is trusted, as no authenticator could tell who they are
+	service := api.NewService(store, api.NewEventLog(&options.RouterOptions), noop.Tracer{}, &api.Auth{})
+	return restapi.ImportContacts(domain.WithTenant(ctx, tenant), service, contactcsv.NewDecoder(r, mapping))
+}
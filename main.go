@@ -2,13 +2,23 @@ package main
 
 import (
 	"context"
+	"io"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2/humacli"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
 
 	"github.com/rlibaert/service-example-go/cli/api"
 	"github.com/rlibaert/service-example-go/cli/logger"
+	"github.com/rlibaert/service-example-go/cli/tracer"
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/router"
+	"github.com/rlibaert/service-example-go/wrappers"
 )
 
 // Information set at build time.
@@ -20,21 +30,112 @@ var (
 )
 
 type Options struct {
+	api.StoreOptions
+	api.WebhooksOptions
+	api.AuthOptions
 	api.RouterOptions
 	api.ServerOptions
+	api.ClientOptions
 
 	Logger logger.Options
+	Tracer tracer.Options
 }
 
 func main() {
 	cli := humacli.New(func(hooks humacli.Hooks, options *Options) {
 		logger := logger.New(&options.Logger)
-		router := api.NewRouter(&options.RouterOptions, title, version, revision, created, logger)
-		server := api.NewServer(&options.ServerOptions, router, logger)
+
+		// the server is set up on start only, so that other commands do not open its resources
+		var (
+			store          domain.Store
+			tracerProvider *sdktrace.TracerProvider
+			tracerOutput   io.Closer
+			health         = &router.Health{}
+			events         *wrappers.EventLog
+			server         *http.Server
+			grpcServer     *grpc.Server
+			grpcHealth     *grpchealth.Server
+			started        = make(chan struct{})
+			stopPurge      = func() {}
+			purged         = make(chan struct{})
+			stopDispatch   = func() {}
+			dispatched     = make(chan struct{})
+		)
 
 		hooks.OnStart(func() {
+			var err error
+			store, err = api.NewStore(context.Background(), &options.StoreOptions)
+			if err != nil {
+				logger.Error("could not open the store", "err", err)
+				os.Exit(1)
+			}
+			tracerProvider, tracerOutput, err = tracer.New(context.Background(), &options.Tracer, title, version)
+			if err != nil {
+				logger.Error("could not create the tracer", "err", err)
+				os.Exit(1)
+			}
+			auth, err := api.NewAuth(context.Background(), &options.AuthOptions)
+			if err != nil {
+				logger.Error("could not configure authentication", "err", err)
+				os.Exit(1)
+			}
+			webhooksStore := api.NewWebhooksStore(store)
+			events = api.NewEventLog(&options.RouterOptions)
+			router := api.NewRouter(&options.RouterOptions, title, version, revision, created, logger,
+				store, webhooksStore, events, health, tracerProvider, auth)
+			server = api.NewServer(&options.ServerOptions, router, logger)
+
+			// the gRPC API is served on its own port alongside the HTTP one
+			if options.GRPCPort != "" {
+				lis, err := net.Listen("tcp", options.Host+":"+options.GRPCPort)
+				if err != nil {
+					logger.Error("could not listen for gRPC", "err", err)
+					os.Exit(1)
+				}
+				grpcServer, grpcHealth = api.NewGRPCServer(&options.RouterOptions, logger,
+					store, events, tracerProvider, auth)
+				go func() {
+					err := grpcServer.Serve(lis)
+					if err != nil {
+						logger.Error("gRPC server failure", "err", err)
+					}
+				}()
+			}
+
+			// deleted contacts are purged past their retention until shutdown, logs being their only trace
+			if options.TrashRetention > 0 {
+				var ctx context.Context
+				ctx, stopPurge = context.WithCancel(context.Background())
+				go func() {
+					defer close(purged)
+					domain.PurgeTrash(ctx, store, options.TrashRetention, time.Hour, func(n int, err error) {
+						if err != nil {
+							logger.Warn("could not purge the trash", "err", err)
+						} else if n > 0 {
+							logger.Info("purged the trash", "contacts", n)
+						}
+					})
+				}()
+			} else {
+				close(purged)
+			}
+
+			// contact events are delivered to webhooks until shutdown
+			dispatcher := api.NewDispatcher(&options.WebhooksOptions, store, webhooksStore, logger)
+			if dispatcher != nil {
+				var ctx context.Context
+				ctx, stopDispatch = context.WithCancel(context.Background())
+				go func() {
+					defer close(dispatched)
+					dispatcher.Run(ctx, options.WebhooksInterval)
+				}()
+			} else {
+				close(dispatched)
+			}
+			close(started)
+
 			logger.Info("starting", "title", title, "version", version, "revision", revision, "created", created)
-			err := server.ListenAndServe()
+			err = server.ListenAndServe()
 			if err != http.ErrServerClosed {
 				logger.Error("server failure", "err", err)
 			} else {
@@ -43,13 +144,54 @@ func main() {
 		})
 
 		hooks.OnStop(func() {
+			<-started
+
+			// fail readiness first to drain traffic
+			health.Shutdown()
+			if grpcHealth != nil {
+				grpcHealth.Shutdown()
+			}
+			time.Sleep(options.ShutdownDelay)
+
+			// end event streams, which the server would wait for
+			events.Close()
+
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			defer cancel()
 			err := server.Shutdown(ctx)
 			if err != nil {
 				logger.Warn("could not shutdown the server", "err", err)
 			}
+			if grpcServer != nil {
+				grpcServer.GracefulStop()
+			}
+
+			stopPurge()
+			<-purged
+			stopDispatch()
+			<-dispatched
+
+			if closer, ok := store.(io.Closer); ok {
+				err = closer.Close()
+				if err != nil {
+					logger.Warn("could not close the store", "err", err)
+				}
+			}
+
+			err = tracerProvider.Shutdown(ctx)
+			if err != nil {
+				logger.Warn("could not flush the traces", "err", err)
+			}
+			err = tracerOutput.Close()
+			if err != nil {
+				logger.Warn("could not close the traces file", "err", err)
+			}
 		})
 	})
+	var contacts contactsFlags
+	cli.Root().AddCommand(importCSVCommand(), contactsCommand(&contacts), openapiCommand())
 	cli.Run()
+	if contacts.code != 0 {
+		os.Exit(contacts.code)
+	}
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+
+	"github.com/rlibaert/service-example-go/cli/api"
+	"github.com/rlibaert/service-example-go/cli/logger"
+	"github.com/rlibaert/service-example-go/openapidiff"
+)
+
+// openapiCommand returns the command printing the OpenAPI document of the API without starting the server,
+// or the changes from a previous one breaking its clients.
+func openapiCommand() *cobra.Command {
+	var (
+		downgrade bool
+		output    string
+		previous  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Print the OpenAPI document of the API without starting the server",
+		Long: "Print the OpenAPI document of the API the server would serve with the same options.\n\n" +
+			"With --diff, print the changes from a previous document breaking its clients instead, " +
+			"and fail if there are any: operations removed, and schemas narrowed for requests or widened " +
+			"for responses. Documents are compared in the OpenAPI version of the previous one.",
+		Args: cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, options *Options) {
+			logger := logger.New(&options.Logger)
+			spec := api.NewOpenAPI(&options.RouterOptions, &options.AuthOptions, title, version)
+
+			if previous != "" {
+				changes, err := breakingChanges(spec, previous)
+				if err != nil {
+					logger.Error("could not compare OpenAPI documents", "err", err)
+					os.Exit(1)
+				}
+				for _, c := range changes {
+					fmt.Fprintln(cmd.OutOrStdout(), c)
+				}
+				if len(changes) > 0 {
+					logger.Error("breaking changes to the OpenAPI document",
+						"previous", previous, "changes", len(changes))
+					os.Exit(1)
+				}
+				return
+			}
+
+			b, err := marshalOpenAPI(spec, downgrade, output)
+			if err == nil {
+				_, err = cmd.OutOrStdout().Write(b)
+			}
+			if err != nil {
+				logger.Error("could not print the OpenAPI document", "err", err)
+				os.Exit(1)
+			}
+		}),
+	}
+	cmd.Flags().BoolVar(&downgrade, "downgrade", false, "print the document as OpenAPI 3.0 instead of 3.1")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "output format: yaml or json")
+	cmd.Flags().StringVar(&previous, "diff", "", "previous JSON or YAML document to check for breaking changes")
+	return cmd
+}
+
+// marshalOpenAPI returns an OpenAPI document as YAML or indented JSON, downgraded to OpenAPI 3.0 or not.
+func marshalOpenAPI(spec *huma.OpenAPI, downgrade bool, output string) ([]byte, error) {
+	switch {
+	case output == "yaml" && downgrade:
+		return spec.DowngradeYAML()
+	case output == "yaml":
+		return spec.YAML()
+	case output != "json":
+		return nil, fmt.Errorf("unknown output format %q, want yaml or json", output)
+	}
+
+	marshal := spec.MarshalJSON
+	if downgrade {
+		marshal = spec.Downgrade
+	}
+	b, err := marshal()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = json.Indent(&buf, b, "", "  ")
+	buf.WriteByte('\n')
+	return buf.Bytes(), err
+}
+
+// breakingChanges returns the changes from the OpenAPI document of a file breaking its clients,
+// the document being compared in the OpenAPI version of the file.
+func breakingChanges(spec *huma.OpenAPI, file string) ([]openapidiff.Change, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	before, err := openapidiff.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	b, err = marshalOpenAPI(spec, strings.HasPrefix(before.Version(), "3.0"), "yaml")
+	if err != nil {
+		return nil, err
+	}
+	after, err := openapidiff.Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	return openapidiff.Breaking(before, after), nil
+}
@@ -0,0 +1,481 @@
+// Package openapidiff finds the changes between two versions of an OpenAPI document breaking their clients.
+package openapidiff
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an OpenAPI 3.0 or 3.1 document, as decoded from JSON or YAML.
+type Document map[string]any
+
+// Parse parses a JSON or YAML OpenAPI document.
+func Parse(b []byte) (Document, error) {
+	var v any
+	err := yaml.Unmarshal(b, &v)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := normalize(v).(map[string]any)
+	if !ok {
+		return nil, errors.New("not an OpenAPI document: not an object")
+	}
+	if _, ok := doc["openapi"].(string); !ok {
+		return nil, errors.New("not an OpenAPI document: no openapi version")
+	}
+	return doc, nil
+}
+
+// normalize converts the mappings of a decoded YAML value keyed by other than strings, e.g. response statuses.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []any:
+		for n, e := range v {
+			v[n] = normalize(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Version returns the OpenAPI version of the document, e.g. 3.1.0.
+func (d Document) Version() string {
+	v, _ := d["openapi"].(string)
+	return v
+}
+
+// Change is a change of an operation breaking its clients.
+type Change struct {
+	Operation string // method & path, e.g. GET /contacts/{id}
+	Location  string // e.g. query.limit or response.200.body.emails[].address, empty for the operation itself
+	Message   string
+}
+
+func (c Change) String() string {
+	if c.Location == "" {
+		return c.Operation + ": " + c.Message
+	}
+	return c.Operation + " " + c.Location + ": " + c.Message
+}
+
+// Breaking returns the changes from a document to another breaking the clients of the first,
+// sorted by operation and location:
+//   - operations removed,
+//   - requests accepting less: parameters & bodies newly required, media types removed, schemas narrowed,
+//     e.g. by a type, an enum value or a property no longer accepted, or a bound tightened,
+//   - responses guaranteeing less: statuses & media types removed, schemas widened,
+//     e.g. by a property removed or no longer required, or a new type or enum value.
+//
+// Documents are best compared in the same OpenAPI version, nullable schemas being described differently.
+func Breaking(before, after Document) []Change {
+	d := &differ{before: before, after: after, seen: map[[2]string]bool{}}
+	ops := operations(after)
+	for key, b := range operations(before) {
+		a, ok := ops[key]
+		if !ok {
+			d.changes = append(d.changes, Change{Operation: b.name, Message: "operation removed"})
+			continue
+		}
+		d.operation(b, a)
+	}
+
+	slices.SortFunc(d.changes, func(x, y Change) int {
+		return cmp.Or(cmp.Compare(x.Operation, y.Operation), cmp.Compare(x.Location, y.Location),
+			cmp.Compare(x.Message, y.Message))
+	})
+	return d.changes
+}
+
+// methods are the methods of the operations of path items.
+var methods = []string{ //nolint: gochecknoglobals // read-only table
+	"get", "put", "post", "delete", "options", "head", "patch", "trace",
+}
+
+// operation is an operation of a document.
+type operation struct {
+	name   string // method & path
+	op     map[string]any
+	params []any // of the path item then of the operation
+}
+
+// operations returns the operations of a document by method & path, the names of path parameters left out.
+func operations(doc Document) map[string]*operation {
+	ops := map[string]*operation{}
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		item, _ := item.(map[string]any)
+		params, _ := item["parameters"].([]any)
+		for _, method := range methods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			opParams, _ := op["parameters"].([]any)
+			ops[strings.ToUpper(method)+" "+unnamed(path)] = &operation{
+				name:   strings.ToUpper(method) + " " + path,
+				op:     op,
+				params: append(slices.Clip(params), opParams...),
+			}
+		}
+	}
+	return ops
+}
+
+// unnamed returns a path without the names of its parameters, e.g. /contacts/{} for /contacts/{id}.
+func unnamed(path string) string {
+	var sb strings.Builder
+	for {
+		before, after, ok := strings.Cut(path, "{")
+		sb.WriteString(before)
+		if !ok {
+			return sb.String()
+		}
+		sb.WriteString("{}")
+		_, path, _ = strings.Cut(after, "}")
+	}
+}
+
+// differ accumulates the breaking changes between two documents.
+type differ struct {
+	before, after Document
+	changes       []Change
+	seen          map[[2]string]bool // pairs of schema references being compared, for recursive schemas
+	name          string             // of the operation being compared
+}
+
+func (d *differ) add(location, format string, args ...any) {
+	d.changes = append(d.changes, Change{Operation: d.name, Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) operation(before, after *operation) {
+	d.name = before.name
+
+	bparams, aparams := parameters(d.before, before.params), parameters(d.after, after.params)
+	for key, ap := range aparams {
+		bp, ok := bparams[key]
+		switch {
+		case !ok && boolean(ap, "required"):
+			d.add(key, "required parameter added")
+		case !ok:
+		case boolean(ap, "required") && !boolean(bp, "required"):
+			d.add(key, "parameter required")
+		default:
+			d.schema(key, true, object(bp, "schema"), object(ap, "schema"))
+		}
+	}
+
+	bbody := resolve(d.before, object(before.op, "requestBody"))
+	abody := resolve(d.after, object(after.op, "requestBody"))
+	if abody != nil && boolean(abody, "required") && (bbody == nil || !boolean(bbody, "required")) {
+		d.add("body", "request body required")
+	}
+	if bbody != nil && abody != nil {
+		d.content("body", true, object(bbody, "content"), object(abody, "content"))
+	}
+
+	aresponses := object(after.op, "responses")
+	for status, br := range object(before.op, "responses") {
+		if !strings.HasPrefix(status, "2") {
+			continue // errors are not relied upon
+		}
+		location := "response." + status
+		ar, ok := aresponses[status].(map[string]any)
+		if !ok {
+			d.add(location, "response removed")
+			continue
+		}
+		br, _ := br.(map[string]any)
+		d.content(location+".body", false,
+			object(resolve(d.before, br), "content"), object(resolve(d.after, ar), "content"))
+	}
+}
+
+// parameters returns the parameters of an operation but those of its path by location & name, e.g. query.limit.
+func parameters(doc Document, params []any) map[string]map[string]any {
+	m := map[string]map[string]any{}
+	for _, p := range params {
+		p, _ := p.(map[string]any)
+		p = resolve(doc, p)
+		in, _ := p["in"].(string)
+		name, _ := p["name"].(string)
+		switch in {
+		case "path":
+			continue // part of the operation
+		case "header":
+			name = strings.ToLower(name)
+		}
+		m[in+"."+name] = p
+	}
+	return m
+}
+
+// content compares the media types of request or response bodies.
+func (d *differ) content(location string, request bool, before, after map[string]any) {
+	for mediatype, bm := range before {
+		am, ok := after[mediatype].(map[string]any)
+		switch {
+		case ok:
+			bm, _ := bm.(map[string]any)
+			d.schema(location, request, object(bm, "schema"), object(am, "schema"))
+		case request:
+			d.add(location, "media type %s no longer accepted", mediatype)
+		default:
+			d.add(location, "media type %s no longer returned", mediatype)
+		}
+	}
+}
+
+// schema compares schemas at a location: request ones must accept any value the first accepted,
+// response ones must not return values the first did not.
+func (d *differ) schema(location string, request bool, before, after map[string]any) {
+	bref, _ := before["$ref"].(string)
+	aref, _ := after["$ref"].(string)
+	if bref != "" && aref != "" {
+		pair := [2]string{bref, aref}
+		if d.seen[pair] {
+			return // already being compared up the location
+		}
+		d.seen[pair] = true
+		defer delete(d.seen, pair)
+	}
+	before, after = resolve(d.before, before), resolve(d.after, after)
+	if before == nil || after == nil {
+		return
+	}
+
+	if request {
+		d.narrowed(location, before, after)
+	} else {
+		d.widened(location, before, after)
+	}
+
+	bprops, aprops := object(before, "properties"), object(after, "properties")
+	for _, name := range slices.Sorted(maps.Keys(bprops)) {
+		bp, _ := bprops[name].(map[string]any)
+		ap, ok := aprops[name].(map[string]any)
+		switch {
+		case ok:
+			d.schema(join(location, name), request, bp, ap)
+		case !request:
+			d.add(join(location, name), "property removed")
+		case after["additionalProperties"] == false:
+			d.add(join(location, name), "property no longer accepted")
+		}
+	}
+
+	bitems, aitems := object(before, "items"), object(after, "items")
+	if bitems != nil && aitems != nil {
+		d.schema(location+"[]", request, bitems, aitems)
+	}
+	badditional, aadditional := object(before, "additionalProperties"), object(after, "additionalProperties")
+	if badditional != nil && aadditional != nil {
+		d.schema(join(location, "*"), request, badditional, aadditional)
+	}
+}
+
+// bounds are the keywords bounding values, and those making them exclusive.
+var bounds = []struct { //nolint: gochecknoglobals // read-only table
+	keyword, exclusive string
+	lower              bool
+}{
+	{"minimum", "exclusiveMinimum", true},
+	{"maximum", "exclusiveMaximum", false},
+	{"minLength", "", true},
+	{"maxLength", "", false},
+	{"minItems", "", true},
+	{"maxItems", "", false},
+	{"minProperties", "", true},
+	{"maxProperties", "", false},
+}
+
+// narrowed adds the constraints of a request schema rejecting values the first accepted.
+func (d *differ) narrowed(location string, before, after map[string]any) {
+	btypes, atypes := types(before), types(after)
+	switch removed := missing(btypes, atypes); {
+	case len(atypes) > 0 && len(btypes) == 0:
+		d.add(location, "type restricted to %s", strings.Join(atypes, ", "))
+	case len(atypes) > 0 && len(removed) > 0:
+		d.add(location, "type %s no longer accepted", strings.Join(removed, ", "))
+	}
+
+	benum, aenum := values(before, "enum"), values(after, "enum")
+	switch removed := missing(benum, aenum); {
+	case len(aenum) > 0 && len(benum) == 0:
+		d.add(location, "enum %s added", strings.Join(aenum, ", "))
+	case len(aenum) > 0 && len(removed) > 0:
+		d.add(location, "enum value %s no longer accepted", strings.Join(removed, ", "))
+	}
+
+	for _, bound := range bounds {
+		bv, bexclusive, bok := limit(before, bound.keyword, bound.exclusive)
+		av, aexclusive, aok := limit(after, bound.keyword, bound.exclusive)
+		tighter := av > bv
+		if !bound.lower {
+			tighter = av < bv
+		}
+		if aok && (!bok || tighter || av == bv && aexclusive && !bexclusive) {
+			d.add(location, "%s tightened", bound.keyword)
+		}
+	}
+
+	for _, keyword := range []string{"pattern", "format"} {
+		bv, _ := before[keyword].(string)
+		av, _ := after[keyword].(string)
+		if av != "" && av != bv {
+			d.add(location, "%s changed to %s", keyword, av)
+		}
+	}
+
+	for _, name := range missing(values(after, "required"), values(before, "required")) {
+		d.add(join(location, name), "property required")
+	}
+
+	if before["additionalProperties"] != false && after["additionalProperties"] == false {
+		d.add(location, "additional properties no longer accepted")
+	}
+}
+
+// widened adds the values of a response schema the first did not return.
+func (d *differ) widened(location string, before, after map[string]any) {
+	btypes, atypes := types(before), types(after)
+	if added := missing(atypes, btypes); len(btypes) > 0 && len(added) > 0 {
+		d.add(location, "type %s returned", strings.Join(added, ", "))
+	}
+
+	benum, aenum := values(before, "enum"), values(after, "enum")
+	if added := missing(aenum, benum); len(benum) > 0 && len(added) > 0 {
+		d.add(location, "enum value %s returned", strings.Join(added, ", "))
+	}
+
+	aprops := object(after, "properties")
+	for _, name := range missing(values(before, "required"), values(after, "required")) {
+		if _, ok := aprops[name]; ok {
+			d.add(join(location, name), "property no longer required")
+		}
+	}
+}
+
+// maxRefs bounds the chains of references resolved.
+const maxRefs = 32
+
+// resolve returns the object a local reference of a document points to, the object itself if not a reference,
+// or nil if the reference cannot be resolved.
+func resolve(doc Document, v map[string]any) map[string]any {
+	for range maxRefs {
+		ref, ok := v["$ref"].(string)
+		if !ok {
+			return v
+		}
+		pointer, ok := strings.CutPrefix(ref, "#/")
+		if !ok {
+			return nil
+		}
+		target := map[string]any(doc)
+		for _, token := range strings.Split(pointer, "/") {
+			target, _ = target[strings.NewReplacer("~1", "/", "~0", "~").Replace(token)].(map[string]any)
+		}
+		v = target
+	}
+	return nil
+}
+
+// join returns the location of a property of a schema at a location.
+func join(location, name string) string {
+	if location == "" {
+		return name
+	}
+	return location + "." + name
+}
+
+func object(v map[string]any, key string) map[string]any {
+	o, _ := v[key].(map[string]any)
+	return o
+}
+
+func boolean(v map[string]any, key string) bool {
+	b, _ := v[key].(bool)
+	return b
+}
+
+// values returns the string representations of the values of an array.
+func values(v map[string]any, key string) []string {
+	a, _ := v[key].([]any)
+	s := make([]string, len(a))
+	for n, e := range a {
+		s[n] = fmt.Sprint(e)
+	}
+	return s
+}
+
+// types returns the types of a schema, null included if nullable, integer included if number is.
+func types(schema map[string]any) []string {
+	var ts []string
+	if t, ok := schema["type"].(string); ok {
+		ts = append(ts, t)
+	} else {
+		ts = values(schema, "type")
+	}
+	if boolean(schema, "nullable") {
+		ts = append(ts, "null")
+	}
+	if slices.Contains(ts, "number") && !slices.Contains(ts, "integer") {
+		ts = append(ts, "integer")
+	}
+	return ts
+}
+
+// missing returns the values of a missing from b.
+func missing(a, b []string) []string {
+	var m []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			m = append(m, v)
+		}
+	}
+	return m
+}
+
+// limit returns the value of a bound of a schema, whether it is exclusive as a 3.0 boolean or a 3.1 number,
+// and whether it is set.
+func limit(schema map[string]any, keyword, exclusive string) (float64, bool, bool) {
+	v, ok := number(schema[keyword])
+	if exclusive == "" {
+		return v, false, ok
+	}
+	if boolean(schema, exclusive) {
+		return v, true, ok
+	}
+	if e, eok := number(schema[exclusive]); eok && !ok {
+		return e, true, true
+	}
+	return v, false, ok
+}
+
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
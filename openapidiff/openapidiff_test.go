@@ -0,0 +1,142 @@
+package openapidiff_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rlibaert/service-example-go/openapidiff"
+	"github.com/rlibaert/service-example-go/restapi"
+	"github.com/rlibaert/service-example-go/router"
+)
+
+const base = `
+openapi: 3.1.0
+paths:
+  /contacts:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, minimum: 1, maximum: 100}}
+        - {name: sort, in: query, schema: {type: string, enum: [lastname, firstname]}}
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [items]
+                properties:
+                  items: {type: array, items: {$ref: "#/components/schemas/Contact"}}
+                  next: {type: string}
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Contact"}
+      responses:
+        "200": {description: OK}
+  /contacts/{id}:
+    delete:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "204": {description: No Content}
+components:
+  schemas:
+    Contact:
+      type: object
+      additionalProperties: false
+      required: [firstname]
+      properties:
+        firstname: {type: string, maxLength: 50}
+        birthday: {type: [string, "null"], format: date}
+        status: {type: string, enum: [active, archived]}
+        manager: {$ref: "#/components/schemas/Contact"}
+`
+
+func TestBreaking(t *testing.T) {
+	before, err := openapidiff.Parse([]byte(base))
+	if err != nil {
+		t.Fatal("Parse:", err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		from    string
+		to      string
+		changes []string
+	}{
+		{"unchanged", "", "", nil},
+		{"path parameter renamed", "/contacts/{id}:", "/contacts/{contactID}:", nil},
+		{"bound loosened", "maximum: 100", "maximum: 200", nil},
+		{"enum widened in request", "[lastname, firstname]", "[lastname, firstname, birthday]", nil},
+		{"operation removed", "    delete:", "    put:", []string{"DELETE /contacts/{id}: operation removed"}},
+		{"bound tightened", "maximum: 100", "maximum: 50", []string{"GET /contacts query.limit: maximum tightened"}},
+		{"enum narrowed", "[lastname, firstname]", "[lastname]",
+			[]string{"GET /contacts query.sort: enum value firstname no longer accepted"}},
+		{"parameter required", "{name: limit, in: query,", "{name: limit, in: query, required: true,",
+			[]string{"GET /contacts query.limit: parameter required"}},
+		{"property required", "required: [firstname]", "required: [firstname, birthday]",
+			[]string{"POST /contacts body.birthday: property required"}},
+		{"property removed", "        status: {type: string, enum: [active, archived]}\n", "", []string{
+			"GET /contacts response.200.body.items[].status: property removed",
+			"POST /contacts body.status: property no longer accepted",
+		}},
+		{"nullable dropped", `{type: [string, "null"], format: date}`, "{type: string, format: date}",
+			[]string{"POST /contacts body.birthday: type null no longer accepted"}},
+		{"enum widened in response", "[active, archived]", "[active, archived, deleted]",
+			[]string{"GET /contacts response.200.body.items[].status: enum value deleted returned"}},
+		{"response no longer required", "required: [items]", "required: []",
+			[]string{"GET /contacts response.200.body.items: property no longer required"}},
+		{"recursive schema narrowed", "maxLength: 50", "maxLength: 20",
+			[]string{"POST /contacts body.firstname: maxLength tightened"}}, // not again for body.manager
+	} {
+		after, err := openapidiff.Parse([]byte(strings.Replace(base, tc.from, tc.to, 1)))
+		if err != nil {
+			t.Fatal(tc.name, "Parse:", err)
+		}
+		var changes []string
+		for _, c := range openapidiff.Breaking(before, after) {
+			changes = append(changes, c.String())
+		}
+		if !slices.Equal(changes, tc.changes) {
+			t.Errorf("%s: got changes %q, want %q", tc.name, changes, tc.changes)
+		}
+	}
+}
+
+func TestBreakingOpenAPI(t *testing.T) {
+	spec := router.OpenAPI("test", "dev",
+		router.OptAutoRegister(restapi.ServiceRegisterer{}),
+		router.OptAutoRegister(restapi.WebhooksRegisterer{}),
+	)
+	for _, version := range []struct {
+		name    string
+		marshal func() ([]byte, error)
+	}{
+		{"3.1", spec.YAML},
+		{"3.0", spec.DowngradeYAML},
+		{"3.0 JSON", spec.Downgrade},
+	} {
+		b, err := version.marshal()
+		if err != nil {
+			t.Fatal(version.name, err)
+		}
+		doc, err := openapidiff.Parse(b)
+		if err != nil {
+			t.Fatal(version.name, "Parse:", err)
+		}
+		if changes := openapidiff.Breaking(doc, doc); len(changes) > 0 {
+			t.Error(version.name, "document breaks itself:", changes)
+		}
+
+		trimmed, _ := openapidiff.Parse(b)
+		paths, _ := trimmed["paths"].(map[string]any)
+		delete(paths, "/contacts/{id}")
+		changes := openapidiff.Breaking(doc, trimmed)
+		if len(changes) != 4 || changes[0].String() != "DELETE /contacts/{id}: operation removed" {
+			t.Error(version.name, "got changes", changes, "for the removal of /contacts/{id}")
+		}
+	}
+}
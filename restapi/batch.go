@@ -0,0 +1,129 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// BatchActionModel is an action of a batch.
+type BatchActionModel struct {
+	Op      string            `json:"op"                enum:"create,update,delete"`
+	ID      *domain.ContactID `json:"id,omitempty"      doc:"ID of the updated or deleted contact"`
+	Version int               `json:"version,omitempty" doc:"version of the contact, checked unless zero" minimum:"0"`
+	Contact *ContactModel     `json:"contact,omitempty" doc:"created or updated contact"`
+}
+
+// BatchInputModel is a batch of actions, applied in order.
+type BatchInputModel struct {
+	Actions []BatchActionModel `json:"actions" minItems:"1" maxItems:"1000"`
+}
+
+// BatchItemModel is the outcome of an action of a batch.
+type BatchItemModel struct {
+	Status int                 `json:"status"           doc:"HTTP status of the action" example:"201"`
+	ID     *domain.ContactID   `json:"id,omitempty"     doc:"ID of the created, updated or deleted contact"`
+	Detail string              `json:"detail,omitempty" doc:"reason of the failure"`
+	Errors []*huma.ErrorDetail `json:"errors,omitempty" doc:"failure details"`
+}
+
+// BatchReportModel reports the outcome of each action of a batch, in order.
+type BatchReportModel struct {
+	Succeeded int              `json:"succeeded" doc:"number of actions applied"`
+	Failed    int              `json:"failed"    doc:"number of actions not applied"`
+	Items     []BatchItemModel `json:"items"`
+}
+
+// batchStatuses are the statuses of applied actions.
+var batchStatuses = map[domain.BatchOp]int{ //nolint: gochecknoglobals // read-only table
+	domain.BatchCreate: http.StatusCreated,
+	domain.BatchUpdate: http.StatusNoContent,
+	domain.BatchDelete: http.StatusNoContent,
+}
+
+// actions returns the [domain.BatchAction] described by the model, or the details of its malformed actions.
+func (m *BatchInputModel) actions() ([]domain.BatchAction, []error) {
+	var (
+		actions = make([]domain.BatchAction, len(m.Actions))
+		errs    []error
+	)
+	for n, a := range m.Actions {
+		loc := fmt.Sprintf("body.actions[%d]", n)
+		action := domain.BatchAction{Op: domain.BatchOp(a.Op), Version: a.Version}
+
+		if action.Op != domain.BatchCreate {
+			if a.ID == nil {
+				errs = append(errs, &huma.ErrorDetail{Message: "required to " + a.Op, Location: loc + ".id"})
+			} else {
+				action.ID = *a.ID
+			}
+		}
+		if action.Op != domain.BatchDelete {
+			if a.Contact == nil {
+				errs = append(errs, &huma.ErrorDetail{Message: "required to " + a.Op, Location: loc + ".contact"})
+			} else {
+				c, err := a.Contact.Contact()
+				if err != nil {
+					errs = append(errs, &huma.ErrorDetail{
+						Message:  "invalid format for birthday",
+						Location: loc + ".contact.birthday",
+						Value:    a.Contact.Birthday,
+					})
+				} else {
+					c.Version = a.Version
+					action.Contact = c
+				}
+			}
+		}
+		actions[n] = action
+	}
+	return actions, errs
+}
+
+func (reg ServiceRegisterer) RegisterContactsBatch(api huma.API) {
+	type input struct {
+		Body BatchInputModel
+	}
+	type output struct {
+		Body BatchReportModel
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		actions, errs := i.Body.actions()
+		if len(errs) > 0 {
+			return nil, huma.Error422UnprocessableEntity("invalid actions", errs...)
+		}
+
+		results, err := reg.Service.ContactsBatch(ctx, actions)
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		report := BatchReportModel{Items: make([]BatchItemModel, len(results))}
+		for n, r := range results {
+			item := &report.Items[n]
+			// aborted creations were rolled back, their IDs pointing to no contact
+			if r.ID != (domain.ContactID{}) && !errors.Is(r.Err, domain.ErrAborted) {
+				item.ID = &r.ID
+			}
+			if r.Err == nil {
+				report.Succeeded++
+				item.Status = batchStatuses[actions[n].Op]
+				continue
+			}
+			report.Failed++
+			item.Status, item.Detail, item.Errors = outcome(fmt.Sprintf("body.actions[%d].contact", n), r.Err)
+		}
+		return &output{Body: report}, nil
+	}
+
+	huma.Post(api, "/contacts:batch", handler, func(op *huma.Operation) {
+		op.Description = "Applies actions in order, atomically if the store supports it, " +
+			"actions not applied because another failed getting a 424 status."
+	}, withErrors(http.StatusForbidden, http.StatusUnprocessableEntity))
+}
@@ -0,0 +1,84 @@
+package restapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// etag returns the entity tag of a [domain.Contact] version.
+func etag(version int) string { return `"` + strconv.Itoa(version) + `"` }
+
+// Preconditions are the conditional request headers matching [etag] values.
+type Preconditions struct {
+	IfMatch     []string `header:"If-Match"      doc:"succeeds if the contact ETag matches one of the values, * matches any"`
+	IfNoneMatch []string `header:"If-None-Match" doc:"succeeds if the contact ETag matches none of the values, * matches any"`
+}
+
+func (p *Preconditions) isSet() bool { return len(p.IfMatch) > 0 || len(p.IfNoneMatch) > 0 }
+
+// matches reports whether an entity tag matches any of the values, using the weak comparison,
+// or the strong one if set: weak values never match then.
+//
+// Values are trimmed as huma splits lists on commas only.
+func matches(values []string, etag string, strong bool) bool {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "*" || v == etag || !strong && strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// check evaluates the preconditions against a [domain.Contact] version.
+//
+// Failed reads answer [http.StatusNotModified] with the ETag, and failed writes [http.StatusPreconditionFailed].
+func (p *Preconditions) check(version int, write bool) error {
+	tag := etag(version)
+	switch {
+	case len(p.IfMatch) > 0 && !matches(p.IfMatch, tag, true):
+		return huma.Error412PreconditionFailed("If-Match precondition failed, found ETag "+tag,
+			&huma.ErrorDetail{Location: "header.If-Match", Value: p.IfMatch})
+	case matches(p.IfNoneMatch, tag, false) && write:
+		return huma.Error412PreconditionFailed("If-None-Match precondition failed, found ETag "+tag,
+			&huma.ErrorDetail{Location: "header.If-None-Match", Value: p.IfNoneMatch})
+	case matches(p.IfNoneMatch, tag, false):
+		return huma.ErrorWithHeaders(huma.Status304NotModified(), http.Header{"ETag": {tag}})
+	default:
+		return nil
+	}
+}
+
+// version evaluates the preconditions of a write against the current [domain.Contact] and returns the
+// version the write must be conditioned on, zero if unconditional.
+//
+// If required is set, writes without If-Match fail with [http.StatusPreconditionRequired].
+func (p *Preconditions) version(read func() (*domain.Contact, error), required bool) (int, error) {
+	if required && len(p.IfMatch) == 0 {
+		return 0, huma.NewError(http.StatusPreconditionRequired, "If-Match header is required")
+	}
+	if !p.isSet() {
+		return 0, nil
+	}
+
+	c, err := read()
+	if err != nil {
+		return 0, problem(err)
+	}
+	return c.Version, p.check(c.Version, true)
+}
+
+// withNotModified returns an operation handler declaring the [http.StatusNotModified] response.
+func withNotModified(op *huma.Operation) {
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	op.Responses[strconv.Itoa(http.StatusNotModified)] = &huma.Response{
+		Description: http.StatusText(http.StatusNotModified),
+	}
+}
@@ -0,0 +1,77 @@
+package restapi
+
+import "github.com/rlibaert/service-example-go/domain"
+
+type EmailModel struct {
+	Address string `json:"address"           example:"john@example.com" format:"email"`
+	Label   string `json:"label,omitempty"   enum:"home,work,other"     doc:"defaults to other"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type PhoneModel struct {
+	Number  string `json:"number"            example:"+33123456789" doc:"international phone number, normalized to E.164"`
+	Label   string `json:"label,omitempty"   enum:"home,work,other" doc:"defaults to other"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type AddressModel struct {
+	Street     string `json:"street,omitempty"      example:"1 rue de Rivoli"`
+	Locality   string `json:"locality"              example:"Paris"`
+	Region     string `json:"region,omitempty"      example:"Île-de-France"`
+	PostalCode string `json:"postal_code,omitempty" example:"75001"`
+	Country    string `json:"country"               example:"FR" minLength:"2" maxLength:"2" doc:"ISO 3166-1 alpha-2 code"`
+	Label      string `json:"label,omitempty"       enum:"home,work,other" doc:"defaults to other"`
+	Primary    bool   `json:"primary,omitempty"`
+}
+
+// convert converts a slice element-wise, nil if empty.
+func convert[T, U any](s []T, f func(T) U) []U {
+	if len(s) == 0 {
+		return nil
+	}
+	out := make([]U, len(s))
+	for n, v := range s {
+		out[n] = f(v)
+	}
+	return out
+}
+
+func emailModel(e domain.Email) EmailModel {
+	return EmailModel{Address: e.Address, Label: string(e.Label), Primary: e.Primary}
+}
+
+func (m EmailModel) email() domain.Email {
+	return domain.Email{Address: m.Address, Label: domain.Label(m.Label), Primary: m.Primary}
+}
+
+func phoneModel(p domain.Phone) PhoneModel {
+	return PhoneModel{Number: p.Number, Label: string(p.Label), Primary: p.Primary}
+}
+
+func (m PhoneModel) phone() domain.Phone {
+	return domain.Phone{Number: m.Number, Label: domain.Label(m.Label), Primary: m.Primary}
+}
+
+func addressModel(a domain.Address) AddressModel {
+	return AddressModel{
+		Street:     a.Street,
+		Locality:   a.Locality,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Label:      string(a.Label),
+		Primary:    a.Primary,
+	}
+}
+
+func (m AddressModel) address() domain.Address {
+	return domain.Address{
+		Street:     m.Street,
+		Locality:   m.Locality,
+		Region:     m.Region,
+		PostalCode: m.PostalCode,
+		Country:    m.Country,
+		Label:      domain.Label(m.Label),
+		Primary:    m.Primary,
+	}
+}
@@ -0,0 +1,90 @@
+package restapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// problems maps [domain] errors to HTTP response statuses.
+var problems = []struct { //nolint: gochecknoglobals // read-only table
+	err    error
+	status int
+}{
+	{domain.ErrNotFound, http.StatusNotFound},
+	{domain.ErrInvalid, http.StatusUnprocessableEntity},
+	{domain.ErrConflict, http.StatusPreconditionFailed},
+	{domain.ErrForbidden, http.StatusForbidden},
+	{domain.ErrAborted, http.StatusFailedDependency},
+	{domain.ErrEventsLost, http.StatusGone},
+	{errors.ErrUnsupported, http.StatusNotImplemented},
+}
+
+// problem translates an error returned by a [domain.Service] into a [huma.StatusError]
+// rendered as an RFC 9457 problem details response, locating field errors in the request body.
+//
+// Errors already carrying a [huma.StatusError] are returned as is,
+// others unknown to [problems] are hidden behind an [http.StatusInternalServerError].
+func problem(err error) error { return problemIn("body", err) }
+
+// problemIn is [problem] locating field errors in a request part, e.g. "body" or "query".
+func problemIn(in string, err error) error {
+	var se huma.StatusError
+	if err == nil || errors.As(err, &se) {
+		return err
+	}
+
+	for _, p := range problems {
+		if errors.Is(err, p.err) {
+			return huma.NewError(p.status, err.Error(), details(in, err)...)
+		}
+	}
+
+	return huma.Error500InternalServerError("unexpected error occurred")
+}
+
+// outcome returns the status, detail and error details of an error translated by [problemIn],
+// e.g. to report the failure of an item of a bulk operation.
+func outcome(in string, err error) (int, string, []*huma.ErrorDetail) {
+	err = problemIn(in, err)
+	status := http.StatusInternalServerError
+	var se huma.StatusError
+	if errors.As(err, &se) {
+		status = se.GetStatus()
+	}
+	var em *huma.ErrorModel
+	if errors.As(err, &em) {
+		return status, em.Detail, em.Errors
+	}
+	return status, "", nil
+}
+
+// details walks an error tree to collect every [domain.FieldError] as a [huma.ErrorDetail].
+func details(in string, err error) []error {
+	switch err := err.(type) { //nolint: errorlint // walking the tree ourselves
+	case *domain.FieldError:
+		return []error{&huma.ErrorDetail{
+			Message:  err.Err.Error(),
+			Location: in + "." + err.Field,
+			Value:    err.Value,
+		}}
+	case interface{ Unwrap() error }:
+		return details(in, err.Unwrap())
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, err := range err.Unwrap() {
+			errs = append(errs, details(in, err)...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
+// withErrors returns an operation handler declaring the error responses an operation may return.
+func withErrors(statuses ...int) func(*huma.Operation) {
+	return func(op *huma.Operation) { op.Errors = append(op.Errors, statuses...) }
+}
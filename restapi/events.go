@@ -0,0 +1,121 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// EventModel is the payload of an event notifying a change to a contact.
+type EventModel struct {
+	ID        string           `json:"id"                doc:"unique, for consumers to ignore repeated deliveries"`
+	Type      string           `json:"type"              enum:"contact.created,contact.updated,contact.deleted"`
+	ContactID domain.ContactID `json:"contact_id"`
+	Contact   *ContactModel    `json:"contact,omitempty" doc:"state after the change, absent once deleted"`
+	Time      time.Time        `json:"time"`
+}
+
+// NewEventModel returns the [EventModel] of a [domain.Event].
+func NewEventModel(e *domain.Event) EventModel {
+	m := EventModel{ID: e.ID, Type: string(e.Type), ContactID: e.ContactID, Time: e.Time}
+	if e.Contact != nil {
+		c := NewContactModel(e.Contact)
+		m.Contact = &c
+	}
+	return m
+}
+
+// DefaultEventsHeartbeat is the default [ServiceRegisterer.EventsHeartbeat].
+const DefaultEventsHeartbeat = 15 * time.Second
+
+// EventStreamMediaType is the media type of Server-Sent Events streams.
+const EventStreamMediaType = "text/event-stream"
+
+func (reg ServiceRegisterer) RegisterContactsEvents(api huma.API) {
+	type input struct {
+		LastEventID string           `header:"Last-Event-ID" doc:"ID of the last event received, to resume after it if retained"`
+		ContactID   domain.ContactID `query:"contact_id"     doc:"only stream the events of a contact"`
+	}
+	type output struct {
+		ContentType  string `header:"Content-Type"`
+		CacheControl string `header:"Cache-Control"`
+		Body         func(huma.Context)
+	}
+
+	heartbeat := reg.EventsHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultEventsHeartbeat
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		q := &domain.EventsQuery{LastID: i.LastEventID, ContactID: i.ContactID}
+		events, err := reg.Service.ContactsEvents(ctx, q)
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		return &output{
+			ContentType:  EventStreamMediaType,
+			CacheControl: "no-cache",
+			Body: func(hctx huma.Context) {
+				w := hctx.BodyWriter()
+				flush := func() error { return nil }
+				if rw, ok := w.(http.ResponseWriter); ok {
+					flush = http.NewResponseController(rw).Flush
+				}
+				if flush() != nil {
+					return
+				}
+
+				ticker := time.NewTicker(heartbeat)
+				defer ticker.Stop()
+				for {
+					var err error
+					select {
+					case e, ok := <-events:
+						if !ok {
+							// the stream ended, e.g. on shutdown, clients reconnect with their Last-Event-ID
+							return
+						}
+						var b []byte
+						b, err = json.Marshal(NewEventModel(e))
+						if err == nil {
+							_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", e.ID, b)
+						}
+					case <-ticker.C:
+						_, err = io.WriteString(w, ": heartbeat\n\n")
+					}
+					if err != nil || flush() != nil {
+						return
+					}
+				}
+			},
+		}, nil
+	}
+
+	registry := api.OpenAPI().Components.Schemas
+	huma.Get(api, "/contacts/events", handler,
+		func(op *huma.Operation) {
+			op.Description = "Streams the events of contacts as Server-Sent Events, " +
+				"each with its ID and an EventModel as data, and heartbeat comments."
+			op.Responses = map[string]*huma.Response{
+				strconv.Itoa(http.StatusOK): {
+					Description: "stream of events of contacts",
+					Content: map[string]*huma.MediaType{
+						EventStreamMediaType: {Schema: registry.Schema(reflect.TypeFor[EventModel](), true, "")},
+					},
+				},
+			}
+		},
+		withErrors(http.StatusForbidden, http.StatusGone, http.StatusNotImplemented),
+	)
+}
@@ -0,0 +1,110 @@
+package restapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/contactcsv"
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/vcard"
+)
+
+// ContactEncoder writes contacts to a file, e.g. a [vcard.Encoder] or a [contactcsv.Encoder].
+type ContactEncoder interface {
+	Encode(c *domain.Contact) error
+}
+
+// exportFormats are the file formats contacts export to, by name.
+var exportFormats = map[string]struct { //nolint: gochecknoglobals // read-only table
+	mediatype string
+	filename  string
+	encoder   func(io.Writer) ContactEncoder
+}{
+	"vcard": {vcard.MediaType, "contacts.vcf", func(w io.Writer) ContactEncoder {
+		return vcard.NewEncoder(w)
+	}},
+	"csv": {contactcsv.MediaType, "contacts.csv", func(w io.Writer) ContactEncoder {
+		return contactcsv.NewEncoder(w)
+	}},
+}
+
+// exportErrorTrailer is the trailer reporting the failure of an export past its first page,
+// once its status is sent.
+const exportErrorTrailer = "Export-Error"
+
+// textContent is the OpenAPI description of text files of some media types.
+func textContent(mediatypes ...string) map[string]*huma.MediaType {
+	content := map[string]*huma.MediaType{}
+	for _, mt := range mediatypes {
+		content[mt] = &huma.MediaType{Schema: &huma.Schema{Type: huma.TypeString}}
+	}
+	return content
+}
+
+func (reg ServiceRegisterer) RegisterContactsExport(api huma.API) {
+	type input struct {
+		Format string `query:"format" doc:"file format" default:"vcard" enum:"vcard,csv"`
+	}
+	type output struct {
+		ContentType        string `header:"Content-Type"`
+		ContentDisposition string `header:"Content-Disposition"`
+		Body               func(huma.Context)
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		format := exportFormats[i.Format] // validated against the enum
+
+		q := domain.ContactsQuery{Limit: domain.ContactsListMaxLimit}
+		page, err := reg.Service.ContactsList(ctx, &q)
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		return &output{
+			ContentType:        format.mediatype + "; charset=utf-8",
+			ContentDisposition: `attachment; filename="` + format.filename + `"`,
+			Body: func(hctx huma.Context) {
+				hctx.SetHeader("Trailer", exportErrorTrailer)
+				enc := format.encoder(hctx.BodyWriter())
+				flusher, _ := enc.(interface{ Flush() error })
+				for {
+					for _, c := range page.Contacts {
+						if enc.Encode(c) != nil {
+							return
+						}
+					}
+					if flusher != nil && flusher.Flush() != nil {
+						return
+					}
+					if page.Next == "" {
+						return
+					}
+					// failures past the first page truncate the stream, the status being already sent
+					q.Cursor = page.Next
+					page, err = reg.Service.ContactsList(ctx, &q)
+					if err != nil {
+						hctx.SetHeader(exportErrorTrailer, problem(err).Error())
+						return
+					}
+				}
+			},
+		}, nil
+	}
+
+	huma.Get(api, "/contacts:export", handler,
+		func(op *huma.Operation) {
+			op.Responses = map[string]*huma.Response{
+				strconv.Itoa(http.StatusOK): {
+					Description: "every contact, as a vCard file or as a CSV file holding their primary details, " +
+						"truncated if the " + exportErrorTrailer + " trailer is set",
+					Content: textContent(vcard.MediaType, contactcsv.MediaType),
+				},
+			}
+		},
+		withErrors(http.StatusForbidden, http.StatusUnprocessableEntity),
+	)
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// AuditEventModel is a recorded change to a contact.
+type AuditEventModel struct {
+	Action    string             `json:"action"               enum:"create,update,delete,restore,purge"`
+	Actor     string             `json:"actor,omitempty"      doc:"subject of the caller, absent if anonymous"`
+	RequestID string             `json:"request_id,omitempty" doc:"X-Request-Id of the request"`
+	Time      time.Time          `json:"time"`
+	Changes   []AuditChangeModel `json:"changes,omitempty"`
+}
+
+// AuditChangeModel is the change of a field of a contact, details being formatted as JSON arrays.
+type AuditChangeModel struct {
+	Field  string `json:"field"            example:"birthday"`
+	Before string `json:"before,omitempty" example:"1999-12-31"`
+	After  string `json:"after,omitempty"  example:"2000-01-01"`
+}
+
+func auditChangeModel(c domain.AuditChange) AuditChangeModel { return AuditChangeModel(c) }
+
+type HistoryPageModel struct {
+	Items []AuditEventModel `json:"items"`
+	Next  string            `json:"next,omitempty" doc:"cursor to the next page, absent on the last page"`
+}
+
+// historyInput is the input of [ServiceRegisterer.RegisterContactsHistory].
+type historyInput struct {
+	ContactID domain.ContactID `path:"id"`
+	Cursor    string           `query:"cursor" doc:"cursor from a previous listing"`
+	Limit     int              `query:"limit"  doc:"maximum number of events"       default:"20" minimum:"1" maximum:"100"`
+
+	url url.URL
+}
+
+func (i *historyInput) Resolve(ctx huma.Context) []error {
+	i.url = ctx.URL()
+	return nil
+}
+
+func (reg ServiceRegisterer) RegisterContactsHistory(api huma.API) {
+	type output struct {
+		Link string `header:"Link"`
+		Body HistoryPageModel
+	}
+
+	handler := func(ctx context.Context, i *historyInput) (*output, error) {
+		page, err := reg.Service.ContactsHistory(ctx, &domain.AuditQuery{
+			ContactID: i.ContactID,
+			Limit:     i.Limit,
+			Cursor:    i.Cursor,
+		})
+		if err != nil {
+			return nil, problemIn("query", err)
+		}
+
+		o := &output{Body: HistoryPageModel{Items: make([]AuditEventModel, len(page.Events)), Next: page.Next}}
+		for n, e := range page.Events {
+			o.Body.Items[n] = AuditEventModel{
+				Action:    string(e.Action),
+				Actor:     e.Actor,
+				RequestID: e.RequestID,
+				Time:      e.Time,
+				Changes:   convert(e.Changes, auditChangeModel),
+			}
+		}
+		if page.Next != "" {
+			o.Link = nextLink(i.url, page.Next)
+		}
+		return o, nil
+	}
+
+	huma.Get(api, "/contacts/{id}/history", handler, func(op *huma.Operation) {
+		op.Description = "Lists the changes to a contact, most recent first, even once deleted."
+	}, withErrors(http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusNotImplemented))
+}
@@ -0,0 +1,147 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/contactcsv"
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/vcard"
+)
+
+// ImportReportMaxItems is the maximum number of failures an [ImportReportModel] lists, others being counted only.
+const ImportReportMaxItems = 1000
+
+// ImportItemModel is the failure of the import of a contact.
+type ImportItemModel struct {
+	Index  int                 `json:"index"            doc:"1-based position of the contact in the imported document"`
+	Status int                 `json:"status"           doc:"HTTP status of the failure" example:"422"`
+	Detail string              `json:"detail,omitempty" doc:"reason of the failure"`
+	Errors []*huma.ErrorDetail `json:"errors,omitempty" doc:"failure details"`
+}
+
+// ImportReportModel reports the outcome of the import of the contacts of a document.
+type ImportReportModel struct {
+	Created int               `json:"created" doc:"number of contacts created"`
+	Failed  int               `json:"failed"  doc:"number of contacts not created"`
+	Items   []ImportItemModel `json:"items"   doc:"contacts not created, the first ones only" maxItems:"1000"`
+}
+
+// add reports the outcome of the import of a contact, err being a [huma.StatusError] or a [domain] error.
+func (r *ImportReportModel) add(index int, err error) {
+	if err == nil {
+		r.Created++
+		return
+	}
+
+	r.Failed++
+	if len(r.Items) < ImportReportMaxItems {
+		item := ImportItemModel{Index: index}
+		item.Status, item.Detail, item.Errors = outcome("body", err)
+		r.Items = append(r.Items, item)
+	}
+}
+
+// ContactDecoder reads contacts from a file, e.g. a [vcard.Decoder] or a [contactcsv.Decoder].
+type ContactDecoder interface {
+	Decode() (*domain.Contact, error)
+}
+
+// ImportContacts creates a contact of each one decoded, e.g. by a [vcard.Decoder] or a [contactcsv.Decoder],
+// and reports the failures, up to [ImportReportMaxItems]. Invalid entries are reported while other decoding errors
+// end the import, the contacts already created being kept.
+func ImportContacts(ctx context.Context, service domain.Service, dec ContactDecoder) (*ImportReportModel, error) {
+	report := &ImportReportModel{Items: []ImportItemModel{}}
+	for index := 1; ; index++ {
+		c, err := dec.Decode()
+		var (
+			verr *vcard.Error
+			cerr *contactcsv.Error
+		)
+		switch {
+		case errors.Is(err, io.EOF):
+			return report, nil
+		case errors.As(err, &verr):
+			report.add(index, huma.Error400BadRequest(fmt.Sprintf("line %d: %v", verr.Line, verr.Err)))
+		case errors.As(err, &cerr) && cerr.Column != "":
+			report.add(index, huma.Error400BadRequest(fmt.Sprintf("line %d: %v", cerr.Line, cerr.Err),
+				&huma.ErrorDetail{Message: cerr.Err.Error(), Location: "body." + cerr.Column}))
+		case errors.As(err, &cerr):
+			report.add(index, huma.Error400BadRequest(fmt.Sprintf("line %d: %v", cerr.Line, cerr.Err)))
+		case err != nil:
+			return report, err
+		default:
+			c.ID = domain.ContactID{}
+			_, err := service.ContactsCreate(ctx, c)
+			report.add(index, err)
+		}
+	}
+}
+
+// contactsImportInput is the input of [ServiceRegisterer.RegisterContactsImport].
+//
+// The body is read by the handler to import contacts as they are decoded.
+type contactsImportInput struct {
+	Columns []string `query:"column,explode" doc:"CSV column mapping as header=field, e.g. 'Work Email=email:work'"`
+
+	contentType string
+	body        io.Reader
+}
+
+func (i *contactsImportInput) Resolve(ctx huma.Context) []error {
+	i.contentType = ctx.Header("Content-Type")
+	i.body = ctx.BodyReader()
+	return nil
+}
+
+func (reg ServiceRegisterer) RegisterContactsImport(api huma.API) {
+	type output struct {
+		Body ImportReportModel
+	}
+
+	handler := func(ctx context.Context, i *contactsImportInput) (*output, error) {
+		var dec ContactDecoder
+		mediatype, _, _ := mime.ParseMediaType(i.contentType)
+		switch mediatype {
+		case vcard.MediaType:
+			dec = vcard.NewDecoder(i.body)
+		case contactcsv.MediaType:
+			var mapping contactcsv.Mapping
+			if len(i.Columns) > 0 {
+				var err error
+				mapping, err = contactcsv.ParseMapping(i.Columns)
+				if err != nil {
+					return nil, huma.Error422UnprocessableEntity(err.Error(),
+						&huma.ErrorDetail{Location: "query.column", Value: i.Columns})
+				}
+			}
+			dec = contactcsv.NewDecoder(i.body, mapping)
+		default:
+			return nil, huma.Error415UnsupportedMediaType("content type should be one of " +
+				vcard.MediaType + " or " + contactcsv.MediaType)
+		}
+
+		report, err := ImportContacts(ctx, reg.Service, dec)
+		if err != nil {
+			return nil, huma.Error400BadRequest(
+				fmt.Sprintf("could not read file after %d contacts created", report.Created), err)
+		}
+		return &output{Body: *report}, nil
+	}
+
+	huma.Post(api, "/contacts:import", handler,
+		func(op *huma.Operation) {
+			op.RequestBody = &huma.RequestBody{
+				Required: true,
+				Content:  textContent(vcard.MediaType, contactcsv.MediaType),
+			}
+		},
+		withErrors(http.StatusBadRequest, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity),
+	)
+}
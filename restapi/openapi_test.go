@@ -3,28 +3,21 @@ package restapi_test
 import (
 	"bytes"
 	_ "embed"
-	"net/http"
 	"os"
 	"testing"
 
-	"github.com/danielgtaylor/huma/v2"
 	"github.com/rlibaert/service-example-go/restapi"
+	"github.com/rlibaert/service-example-go/router"
 )
 
-// nopAdapter is a no-op implementation of [huma.Adapter].
-type nopAdapter struct{}
-
-func (nopAdapter) Handle(*huma.Operation, func(huma.Context))   {}
-func (nopAdapter) ServeHTTP(http.ResponseWriter, *http.Request) {}
-
 //go:embed openapi.yaml
 var openapi []byte
 
 func TestOpenAPI(t *testing.T) {
-	api := huma.NewAPI(huma.DefaultConfig("test", "dev"), nopAdapter{})
-	huma.AutoRegister(api, restapi.ServiceRegisterer{})
-
-	b, err := api.OpenAPI().YAML()
+	b, err := router.OpenAPI("test", "dev",
+		router.OptAutoRegister(restapi.ServiceRegisterer{}),
+		router.OptAutoRegister(restapi.WebhooksRegisterer{}),
+	).YAML()
 	if err != nil {
 		t.Error(err)
 	}
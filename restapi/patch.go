@@ -0,0 +1,288 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Patch media types accepted by [ServiceRegisterer.RegisterContactsPatch].
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// maxPatchBytes limits the size of patch documents.
+const maxPatchBytes = 1 << 16
+
+// ContactPatchModel is a JSON Merge Patch (RFC 7396) document of a [ContactModel].
+type ContactPatchModel struct {
+	Firstname string `json:"firstname,omitempty" example:"john"`
+	Lastname  string `json:"lastname,omitempty"  example:"smith"`
+	Birthday  string `json:"birthday,omitempty"  example:"1999-12-31" format:"date"`
+
+	Emails    []EmailModel   `json:"emails,omitempty"    maxItems:"10" doc:"replaces every email, null removes them"`
+	Phones    []PhoneModel   `json:"phones,omitempty"    maxItems:"10" doc:"replaces every phone, null removes them"`
+	Addresses []AddressModel `json:"addresses,omitempty" maxItems:"10" doc:"replaces every address, null removes them"`
+}
+
+// JSONPatchOperationModel is an operation of a JSON Patch (RFC 6902) document.
+type JSONPatchOperationModel struct {
+	Op    string `json:"op"              enum:"add,remove,replace,move,copy,test"`
+	From  string `json:"from,omitempty"  doc:"JSON Pointer to the source of a move or copy"`
+	Path  string `json:"path"            doc:"JSON Pointer to the target"`
+	Value any    `json:"value,omitempty" doc:"value to add, replace or test"`
+}
+
+// contactsPatchInput is the input of [ServiceRegisterer.RegisterContactsPatch].
+//
+// The body is read by the handler as huma only decodes bodies of a single media type.
+type contactsPatchInput struct {
+	ContactID domain.ContactID `path:"id"`
+	Preconditions
+
+	contentType string
+	body        io.Reader
+}
+
+func (i *contactsPatchInput) Resolve(ctx huma.Context) []error {
+	i.contentType = ctx.Header("Content-Type")
+	i.body = ctx.BodyReader()
+	return nil
+}
+
+// read reads the patch document and returns it with its media type.
+func (i *contactsPatchInput) read() (string, []byte, error) {
+	b, err := io.ReadAll(io.LimitReader(i.body, maxPatchBytes+1))
+	if err != nil {
+		return "", nil, huma.Error400BadRequest("could not read patch", err)
+	}
+	if len(b) > maxPatchBytes {
+		return "", nil, huma.NewError(http.StatusRequestEntityTooLarge, "patch is too large")
+	}
+
+	mediatype, _, _ := mime.ParseMediaType(i.contentType)
+	switch mediatype {
+	case mergePatchType, jsonPatchType:
+		return mediatype, b, nil
+	default:
+		return "", nil, huma.Error415UnsupportedMediaType("content type should be one of " +
+			mergePatchType + " or " + jsonPatchType)
+	}
+}
+
+// NewMergePatch returns the JSON Merge Patch document of a [domain.ContactPatch], version excluded.
+func NewMergePatch(p *domain.ContactPatch) map[string]any {
+	var c domain.Contact
+	p.Apply(&c)
+	m := NewContactModel(&c)
+
+	doc := map[string]any{}
+	if p.Firstname != nil {
+		doc["firstname"] = m.Firstname
+	}
+	if p.Lastname != nil {
+		doc["lastname"] = m.Lastname
+	}
+	if p.Birthday != nil {
+		doc["birthday"] = m.Birthday
+		if p.Birthday.IsZero() {
+			doc["birthday"] = nil
+		}
+	}
+	if p.Emails != nil {
+		doc["emails"] = m.Emails // null if empty, removing them
+	}
+	if p.Phones != nil {
+		doc["phones"] = m.Phones
+	}
+	if p.Addresses != nil {
+		doc["addresses"] = m.Addresses
+	}
+	return doc
+}
+
+// patch returns the [domain.ContactPatch] setting the fields of the model a merge patch document has.
+func (m *ContactModel) patch(fields map[string]any) (*domain.ContactPatch, error) {
+	p := &domain.ContactPatch{}
+	if _, ok := fields["firstname"]; ok {
+		p.Firstname = &m.Firstname
+	}
+	if _, ok := fields["lastname"]; ok {
+		p.Lastname = &m.Lastname
+	}
+	if _, ok := fields["birthday"]; ok {
+		var birthday time.Time // zero if removed
+		if m.Birthday != "" {
+			var err error
+			birthday, err = time.Parse(time.DateOnly, m.Birthday)
+			if err != nil {
+				return nil, huma.Error422UnprocessableEntity("invalid format for birthday", err)
+			}
+		}
+		p.Birthday = &birthday
+	}
+	if _, ok := fields["emails"]; ok {
+		emails := convert(m.Emails, EmailModel.email)
+		p.Emails = &emails
+	}
+	if _, ok := fields["phones"]; ok {
+		phones := convert(m.Phones, PhoneModel.phone)
+		p.Phones = &phones
+	}
+	if _, ok := fields["addresses"]; ok {
+		addresses := convert(m.Addresses, AddressModel.address)
+		p.Addresses = &addresses
+	}
+	return p, nil
+}
+
+// patchAttempts bounds the attempts of a JSON patch, conditioned on the version of the contact it applies to.
+const patchAttempts = 3
+
+func (reg ServiceRegisterer) RegisterContactsPatch(api huma.API) {
+	type output struct {
+		ETag string `header:"ETag"`
+		Body ContactModel
+	}
+
+	registry := api.OpenAPI().Components.Schemas
+	schema := registry.Schema(reflect.TypeFor[ContactPatchModel](), true, "")
+
+	// mergePatch validates a merge patch document and decodes it, null values removing fields.
+	mergePatch := func(b []byte) (*domain.ContactPatch, error) {
+		var doc map[string]any
+		err := json.Unmarshal(b, &doc)
+		if err != nil {
+			return nil, huma.Error400BadRequest("malformed merge patch", err)
+		}
+
+		values := map[string]any{}
+		for k, v := range doc {
+			if v != nil {
+				values[k] = v
+			}
+		}
+		pb := huma.NewPathBuffer(make([]byte, 0, 128), 0) //nolint: mnd // arbitrary
+		pb.Push("body")
+		res := &huma.ValidateResult{}
+		huma.Validate(registry, schema, pb, huma.ModeWriteToServer, values, res)
+		if len(res.Errors) > 0 {
+			return nil, huma.Error422UnprocessableEntity("validation failed", res.Errors...)
+		}
+
+		var m ContactModel
+		err = json.Unmarshal(b, &m)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity("validation failed", err)
+		}
+		return m.patch(doc)
+	}
+
+	// jsonPatch applies a JSON patch document to a contact and returns the changes as a [domain.ContactPatch]
+	// conditioned on its version.
+	jsonPatch := func(patch jsonpatch.Patch, c *domain.Contact) (*domain.ContactPatch, error) {
+		doc, err := json.Marshal(NewContactModel(c))
+		if err != nil {
+			return nil, err
+		}
+		patched, err := patch.Apply(doc)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity("could not apply patch", err)
+		}
+		changes, err := jsonpatch.CreateMergePatch(doc, patched)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity("patched contact is not a JSON object", err)
+		}
+
+		p, err := mergePatch(changes)
+		if err != nil {
+			return nil, err
+		}
+		p.Version = c.Version
+		return p, nil
+	}
+
+	handler := func(ctx context.Context, i *contactsPatchInput) (*output, error) {
+		if reg.RequirePreconditions && len(i.IfMatch) == 0 {
+			return nil, huma.NewError(http.StatusPreconditionRequired, "If-Match header is required")
+		}
+
+		mediatype, b, err := i.read()
+		if err != nil {
+			return nil, err
+		}
+		read := func() (*domain.Contact, error) { return reg.Service.ContactsRead(ctx, i.ContactID) }
+
+		var c *domain.Contact
+		if mediatype == mergePatchType {
+			p, err := mergePatch(b)
+			if err != nil {
+				return nil, err
+			}
+			p.Version, err = i.version(read, false)
+			if err != nil {
+				return nil, err
+			}
+			c, err = reg.Service.ContactsPatch(ctx, i.ContactID, p)
+			if err != nil {
+				return nil, problem(err)
+			}
+			return &output{ETag: etag(c.Version), Body: NewContactModel(c)}, nil
+		}
+
+		patch, err := jsonpatch.DecodePatch(b)
+		if err != nil {
+			return nil, huma.Error400BadRequest("malformed JSON patch", err)
+		}
+		// the patch applies to the contact read, starting over if it changed in between
+		var p *domain.ContactPatch
+		for attempt := 1; ; attempt++ {
+			c, err = read()
+			if err != nil {
+				return nil, problem(err)
+			}
+			err = i.check(c.Version, true)
+			if err != nil {
+				return nil, err
+			}
+			p, err = jsonPatch(patch, c)
+			if err != nil {
+				return nil, err
+			}
+			c, err = reg.Service.ContactsPatch(ctx, i.ContactID, p)
+			if !errors.Is(err, domain.ErrConflict) || attempt == patchAttempts {
+				break
+			}
+		}
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &output{ETag: etag(c.Version), Body: NewContactModel(c)}, nil
+	}
+
+	huma.Patch(api, "/contacts/{id}", handler,
+		func(op *huma.Operation) {
+			op.RequestBody = &huma.RequestBody{
+				Required: true,
+				Content: map[string]*huma.MediaType{
+					mergePatchType: {Schema: registry.Schema(reflect.TypeFor[ContactPatchModel](), true, "")},
+					jsonPatchType:  {Schema: registry.Schema(reflect.TypeFor[[]JSONPatchOperationModel](), true, "")},
+				},
+			}
+		},
+		withErrors(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed,
+			http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity,
+			http.StatusPreconditionRequired),
+	)
+}
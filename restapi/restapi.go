@@ -3,16 +3,29 @@ package restapi
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/negotiation"
 
 	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/vcard"
 )
 
 // ServiceRegisterer registers endpoints in a [huma.API] to expose a [domain.Service] with a REST interface.
 type ServiceRegisterer struct {
 	Service domain.Service
+
+	// RequirePreconditions rejects updates & deletes without an If-Match header.
+	RequirePreconditions bool
+
+	// EventsHeartbeat is the time between the comments keeping event streams alive,
+	// defaults to [DefaultEventsHeartbeat].
+	EventsHeartbeat time.Duration
 }
 
 type ContactIDModel struct {
@@ -25,6 +38,45 @@ type ContactModel struct {
 	Firstname string `json:"firstname" example:"john"`
 	Lastname  string `json:"lastname"  example:"smith"`
 	Birthday  string `json:"birthday"  example:"1999-12-31" format:"date"`
+
+	Emails    []EmailModel   `json:"emails,omitempty"    maxItems:"10"`
+	Phones    []PhoneModel   `json:"phones,omitempty"    maxItems:"10"`
+	Addresses []AddressModel `json:"addresses,omitempty" maxItems:"10"`
+}
+
+// NewContactModel returns the [ContactModel] of a [domain.Contact].
+func NewContactModel(c *domain.Contact) ContactModel {
+	return ContactModel{
+		ContactIDModel: ContactIDModel{c.ID},
+		Firstname:      c.Firstname,
+		Lastname:       c.Lastname,
+		Birthday:       c.Birthday.Format(time.DateOnly),
+		Emails:         convert(c.Emails, emailModel),
+		Phones:         convert(c.Phones, phoneModel),
+		Addresses:      convert(c.Addresses, addressModel),
+	}
+}
+
+// Contact returns the [domain.Contact] described by the model, ID excluded.
+func (m *ContactModel) Contact() (*domain.Contact, error) {
+	birthday, err := time.Parse(time.DateOnly, m.Birthday)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity("invalid format for birthday", err)
+	}
+
+	return &domain.Contact{
+		Firstname: m.Firstname,
+		Lastname:  m.Lastname,
+		Birthday:  birthday,
+		Emails:    convert(m.Emails, EmailModel.email),
+		Phones:    convert(m.Phones, PhoneModel.phone),
+		Addresses: convert(m.Addresses, AddressModel.address),
+	}, nil
+}
+
+type ContactsPageModel struct {
+	Items []ContactModel `json:"items"`
+	Next  string         `json:"next,omitempty" doc:"cursor to the next page, absent on the last page"`
 }
 
 func (reg ServiceRegisterer) RegisterContactsCreate(api huma.API) {
@@ -36,83 +88,206 @@ func (reg ServiceRegisterer) RegisterContactsCreate(api huma.API) {
 	}
 
 	handler := func(ctx context.Context, i *input) (*output, error) {
-		birthday, err := time.Parse(time.DateOnly, i.Body.Birthday)
+		c, err := i.Body.Contact()
 		if err != nil {
-			return nil, huma.Error422UnprocessableEntity("invalid format for birthday", err)
+			return nil, err
 		}
 
-		id, err := reg.Service.ContactsCreate(ctx, &domain.Contact{
-			Firstname: i.Body.Firstname,
-			Lastname:  i.Body.Lastname,
-			Birthday:  birthday,
-		})
+		id, err := reg.Service.ContactsCreate(ctx, c)
 		if err != nil {
-			return nil, err
+			return nil, problem(err)
 		}
 
 		return &output{Body: ContactIDModel{id}}, nil
 	}
 
-	huma.Post(api, "/contacts", handler)
+	huma.Post(api, "/contacts", handler, withErrors(http.StatusForbidden, http.StatusUnprocessableEntity))
 }
 
 func (reg ServiceRegisterer) RegisterContactsRead(api huma.API) {
 	type input struct {
 		ContactID domain.ContactID `path:"id"`
+		Preconditions
+		Accept string `header:"Accept" doc:"application/json or text/vcard"`
 	}
 	type output struct {
-		Body ContactModel
+		ETag        string `header:"ETag"`
+		Vary        string `header:"Vary"`
+		ContentType string `header:"Content-Type"`
+		Body        any
 	}
 
 	handler := func(ctx context.Context, input *input) (*output, error) {
 		c, err := reg.Service.ContactsRead(ctx, input.ContactID)
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		err = input.check(c.Version, false)
 		if err != nil {
 			return nil, err
 		}
 
-		return &output{Body: ContactModel{
-			ContactIDModel: ContactIDModel{c.ID},
-			Firstname:      c.Firstname,
-			Lastname:       c.Lastname,
-			Birthday:       c.Birthday.Format(time.DateOnly),
-		}}, nil
+		o := &output{ETag: etag(c.Version), Vary: "Accept", Body: NewContactModel(c)}
+		if negotiation.SelectQValueFast(input.Accept, []string{"application/json", vcard.MediaType}) == vcard.MediaType {
+			o.ContentType = vcard.MediaType + "; charset=utf-8"
+			o.Body, err = encodeVCard(c)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return o, nil
 	}
 
-	huma.Get(api, "/contacts/{id}", handler)
+	registry := api.OpenAPI().Components.Schemas
+	huma.Get(api, "/contacts/{id}", handler, withNotModified,
+		func(op *huma.Operation) {
+			content := textContent(vcard.MediaType)
+			content["application/json"] = &huma.MediaType{Schema: registry.Schema(reflect.TypeFor[ContactModel](), true, "")}
+			op.Responses[strconv.Itoa(http.StatusOK)] = &huma.Response{Description: http.StatusText(http.StatusOK), Content: content}
+		},
+		withErrors(http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnprocessableEntity))
 }
 
 func (reg ServiceRegisterer) RegisterContactsUpdate(api huma.API) {
 	type input struct {
 		ContactID domain.ContactID `path:"id"`
-		Body      ContactModel
+		Preconditions
+		Body ContactModel
+	}
+	type output struct {
+		ETag string `header:"ETag"`
 	}
-	type output struct{}
 
 	handler := func(ctx context.Context, i *input) (*output, error) {
-		birthday, err := time.Parse(time.DateOnly, i.Body.Birthday)
+		c, err := i.Body.Contact()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Version, err = i.version(func() (*domain.Contact, error) {
+			return reg.Service.ContactsRead(ctx, i.ContactID)
+		}, reg.RequirePreconditions)
+		if err != nil {
+			return nil, err
+		}
+
+		version, err := reg.Service.ContactsUpdate(ctx, i.ContactID, c)
 		if err != nil {
-			return nil, huma.Error422UnprocessableEntity("invalid format for birthday", err)
+			return nil, problem(err)
 		}
 
-		return nil, reg.Service.ContactsUpdate(ctx, i.ContactID, &domain.Contact{
-			Firstname: i.Body.Firstname,
-			Lastname:  i.Body.Lastname,
-			Birthday:  birthday,
-		})
+		return &output{ETag: etag(version)}, nil
 	}
 
-	huma.Put(api, "/contacts/{id}", handler)
+	huma.Put(api, "/contacts/{id}", handler, withErrors(http.StatusForbidden, http.StatusNotFound,
+		http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired))
 }
 
 func (reg ServiceRegisterer) RegisterContactsDelete(api huma.API) {
 	type input struct {
 		ContactID domain.ContactID `path:"id"`
+		Preconditions
 	}
 	type output struct{}
 
 	handler := func(ctx context.Context, input *input) (*output, error) {
-		return nil, reg.Service.ContactsDelete(ctx, input.ContactID)
+		version, err := input.version(func() (*domain.Contact, error) {
+			return reg.Service.ContactsRead(ctx, input.ContactID)
+		}, reg.RequirePreconditions)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, problem(reg.Service.ContactsDelete(ctx, input.ContactID, version))
+	}
+
+	huma.Delete(api, "/contacts/{id}", handler, withErrors(http.StatusForbidden, http.StatusNotFound,
+		http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired))
+}
+
+// contactsListInput is the input of [ServiceRegisterer.RegisterContactsList].
+type contactsListInput struct {
+	Cursor     string `query:"cursor"      doc:"cursor from a previous listing"`
+	Limit      int    `query:"limit"       doc:"maximum number of contacts"     default:"20"       minimum:"1" maximum:"100"`
+	Sort       string `query:"sort"        doc:"sort order"                     default:"lastname" enum:"lastname,firstname,birthday"`
+	Name       string `query:"name"        doc:"firstname or lastname prefix"`
+	BornAfter  string `query:"born_after"  doc:"exclusive birthday lower bound" format:"date"`
+	BornBefore string `query:"born_before" doc:"exclusive birthday upper bound" format:"date"`
+
+	url url.URL
+}
+
+func (i *contactsListInput) Resolve(ctx huma.Context) []error {
+	i.url = ctx.URL()
+	return nil
+}
+
+// link returns a Link header value to the page starting at a cursor.
+func (i *contactsListInput) link(cursor string) string { return nextLink(i.url, cursor) }
+
+// nextLink returns a Link header value to the page of a listing URL starting at a cursor.
+func nextLink(u url.URL, cursor string) string {
+	q := u.Query()
+	q.Set("cursor", cursor)
+	u.RawQuery = q.Encode()
+	return "<" + u.String() + `>; rel="next"`
+}
+
+// query returns the [domain.ContactsQuery] described by the input.
+func (i *contactsListInput) query() (*domain.ContactsQuery, error) {
+	q := &domain.ContactsQuery{
+		Sort:       domain.ContactsSort(i.Sort),
+		NamePrefix: i.Name,
+		Limit:      i.Limit,
+		Cursor:     i.Cursor,
+	}
+	for _, bound := range []struct {
+		name  string
+		value string
+		time  *time.Time
+	}{
+		{"born_after", i.BornAfter, &q.BornAfter},
+		{"born_before", i.BornBefore, &q.BornBefore},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, bound.value)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity("invalid format for "+bound.name, err)
+		}
+		*bound.time = t
+	}
+	return q, nil
+}
+
+func (reg ServiceRegisterer) RegisterContactsList(api huma.API) {
+	type output struct {
+		Link string `header:"Link"`
+		Body ContactsPageModel
+	}
+
+	handler := func(ctx context.Context, i *contactsListInput) (*output, error) {
+		q, err := i.query()
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := reg.Service.ContactsList(ctx, q)
+		if err != nil {
+			return nil, problemIn("query", err)
+		}
+
+		o := &output{Body: ContactsPageModel{Items: make([]ContactModel, len(page.Contacts)), Next: page.Next}}
+		for n, c := range page.Contacts {
+			o.Body.Items[n] = NewContactModel(c)
+		}
+		if page.Next != "" {
+			o.Link = i.link(page.Next)
+		}
+		return o, nil
 	}
 
-	huma.Delete(api, "/contacts/{id}", handler)
+	huma.Get(api, "/contacts", handler, withErrors(http.StatusForbidden, http.StatusUnprocessableEntity))
 }
@@ -0,0 +1,732 @@
+package restapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/restapi"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/webhooks"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+func TestErrors(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock()
+	restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: store}}.RegisterContactsRead(api)
+
+	id, _ := store.ContactsSet(t.Context(), &domain.Contact{})
+	_ = store.ContactsDel(t.Context(), id, 0)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{"/contacts/" + id.String(), http.StatusNotFound},
+		{"/contacts/not-an-id", http.StatusUnprocessableEntity},
+	} {
+		resp := api.Get(tc.path)
+		if resp.Code != tc.status {
+			t.Error("GET", tc.path, "got", resp.Code, "want", tc.status)
+		}
+		if ct := resp.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Error("GET", tc.path, "got content type", ct)
+		}
+	}
+}
+
+func TestValidation(t *testing.T) {
+	_, api := humatest.New(t)
+	restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: stores.MustNewMock()}}.RegisterContactsCreate(api)
+
+	resp := api.Post("/contacts", map[string]any{"firstname": " ", "lastname": "smith", "birthday": "2999-01-01"})
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatal("POST got", resp.Code)
+	}
+
+	var problem struct {
+		Errors []struct{ Location string }
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	var locations []string
+	for _, e := range problem.Errors {
+		locations = append(locations, e.Location)
+	}
+	if want := []string{"body.firstname", "body.birthday"}; !slices.Equal(locations, want) {
+		t.Error("got locations", locations, "want", want)
+	}
+}
+
+func TestContactsList(t *testing.T) {
+	_, api := humatest.New(t)
+	restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: stores.MustNewMock(
+		&domain.Contact{Firstname: "john", Lastname: "smith", Birthday: time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		&domain.Contact{Firstname: "jane", Lastname: "doe", Birthday: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+		&domain.Contact{Firstname: "bob", Lastname: "smith", Birthday: time.Date(1980, 6, 15, 0, 0, 0, 0, time.UTC)},
+	)}}.RegisterContactsList(api)
+
+	link := regexp.MustCompile(`^<(.+)>; rel="next"$`)
+	var names []string
+	for path := "/contacts?limit=1&sort=birthday&born_after=1985-01-01"; path != ""; {
+		resp := api.Get(path)
+		if resp.Code != http.StatusOK {
+			t.Fatal("GET", path, "got", resp.Code)
+		}
+		var page restapi.ContactsPageModel
+		if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range page.Items {
+			names = append(names, c.Firstname)
+		}
+
+		path = ""
+		if m := link.FindStringSubmatch(resp.Header().Get("Link")); m != nil {
+			path = m[1]
+		}
+	}
+
+	if want := []string{"jane", "john"}; !slices.Equal(names, want) {
+		t.Error("got", names, "want", want)
+	}
+}
+
+func TestConditional(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock()
+	reg := restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: store}}
+	reg.RegisterContactsRead(api)
+	reg.RegisterContactsUpdate(api)
+	reg.RegisterContactsDelete(api)
+
+	id, _ := store.ContactsSet(t.Context(), &domain.Contact{})
+	path := "/contacts/" + id.String()
+	body := map[string]any{"firstname": "john", "lastname": "smith", "birthday": "1999-12-31"}
+
+	resp := api.Get(path)
+	etag := resp.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatal("GET got ETag", etag)
+	}
+
+	if resp := api.Get(path, "If-None-Match: "+etag); resp.Header().Get("ETag") != etag {
+		t.Error("GET not modified got ETag", resp.Header().Get("ETag"), "want", etag)
+	}
+
+	for n, tc := range []struct {
+		resp   *httptest.ResponseRecorder
+		status int
+	}{
+		{api.Get(path, "If-None-Match: "+etag), http.StatusNotModified},
+		{api.Get(path, `If-None-Match: "0"`), http.StatusOK},
+		{api.Get(path, `If-None-Match: "0", `+etag), http.StatusNotModified},
+		{api.Put(path, `If-Match: "0", "2"`, body), http.StatusPreconditionFailed},
+		{api.Put(path, `If-Match: "0"`, body), http.StatusPreconditionFailed},
+		{api.Put(path, "If-None-Match: *", body), http.StatusPreconditionFailed},
+		{api.Put(path, "If-Match: W/"+etag, body), http.StatusPreconditionFailed},
+		{api.Put(path, `If-Match: "0", `+etag, body), http.StatusNoContent},
+		{api.Get(path, `If-None-Match: W/"2"`), http.StatusNotModified},
+		{api.Delete(path, "If-Match: "+etag), http.StatusPreconditionFailed},
+		{api.Delete(path, `If-Match: "2"`), http.StatusNoContent},
+	} {
+		if tc.resp.Code != tc.status {
+			t.Error("request", n, "got", tc.resp.Code, "want", tc.status)
+		}
+	}
+
+	id, _ = store.ContactsSet(t.Context(), &domain.Contact{})
+	if etag := api.Put("/contacts/"+id.String(), body).Header().Get("ETag"); etag != `"2"` {
+		t.Error("PUT got ETag", etag, "want", `"2"`)
+	}
+
+	_, api = humatest.New(t)
+	reg.RequirePreconditions = true
+	reg.RegisterContactsDelete(api)
+	if resp := api.Delete(path); resp.Code != http.StatusPreconditionRequired {
+		t.Error("DELETE without If-Match got", resp.Code, "want", http.StatusPreconditionRequired)
+	}
+}
+
+func TestContactsPatch(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock()
+	restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: store}}.RegisterContactsPatch(api)
+
+	id, _ := store.ContactsSet(t.Context(), &domain.Contact{
+		Firstname: "john",
+		Lastname:  "smith",
+		Birthday:  time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	path := "/contacts/" + id.String()
+
+	for n, tc := range []struct {
+		contentType string
+		body        string
+		status      int
+		want        restapi.ContactModel
+	}{
+		{"application/merge-patch+json", `{"birthday":"2000-01-01"}`, http.StatusOK,
+			restapi.ContactModel{Firstname: "john", Lastname: "smith", Birthday: "2000-01-01"}},
+		{"application/json-patch+json", `[{"op":"replace","path":"/firstname","value":"jane"}]`, http.StatusOK,
+			restapi.ContactModel{Firstname: "jane", Lastname: "smith", Birthday: "2000-01-01"}},
+		{"application/merge-patch+json", `{"birthday":"01/01/2000"}`, http.StatusUnprocessableEntity,
+			restapi.ContactModel{}},
+		{"application/merge-patch+json", `{"firstname":null}`, http.StatusUnprocessableEntity,
+			restapi.ContactModel{}},
+		{"application/merge-patch+json", `{"birthday":null}`, http.StatusUnprocessableEntity,
+			restapi.ContactModel{}},
+		{"application/merge-patch+json", `{"nickname":"jj"}`, http.StatusUnprocessableEntity,
+			restapi.ContactModel{}},
+		{"application/json-patch+json", `[{"op":"add","path":"/emails","value":[{"address":"Jane@Example.COM"}]}]`,
+			http.StatusOK, restapi.ContactModel{Firstname: "jane", Lastname: "smith", Birthday: "2000-01-01",
+				Emails: []restapi.EmailModel{{Address: "Jane@example.com", Label: "other"}}}},
+		{"application/merge-patch+json", `{"emails":null}`, http.StatusOK,
+			restapi.ContactModel{Firstname: "jane", Lastname: "smith", Birthday: "2000-01-01"}},
+		{"application/merge-patch+json", `{"phones":[{"number":"0123"}]}`, http.StatusUnprocessableEntity,
+			restapi.ContactModel{}},
+		{"application/json-patch+json", `{}`, http.StatusBadRequest, restapi.ContactModel{}},
+		{"application/json", `{}`, http.StatusUnsupportedMediaType, restapi.ContactModel{}},
+	} {
+		resp := api.Patch(path, "Content-Type: "+tc.contentType, strings.NewReader(tc.body))
+		if resp.Code != tc.status {
+			t.Error("patch", n, "got", resp.Code, "want", tc.status)
+			continue
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+
+		var got restapi.ContactModel
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		got.ID = domain.ContactID{}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Error("patch", n, "got", got, "want", tc.want)
+		}
+	}
+
+	if c, _ := store.ContactsGet(t.Context(), id); c.Version != 5 {
+		t.Error("failed patches altered the contact, got version", c.Version)
+	}
+}
+
+func TestVCard(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock()
+	reg := restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: store}}
+	reg.RegisterContactsRead(api)
+	reg.RegisterContactsExport(api)
+	reg.RegisterContactsImport(api)
+
+	id, _ := store.ContactsSet(t.Context(), &domain.Contact{
+		Firstname: "john",
+		Lastname:  "smith",
+		Birthday:  time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+
+	t.Run("read", func(t *testing.T) {
+		resp := api.Get("/contacts/"+id.String(), "Accept: text/vcard")
+		if resp.Code != http.StatusOK || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/vcard") {
+			t.Fatal("GET got", resp.Code, resp.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(resp.Body.String(), "UID:urn:uuid:"+id.String()+"\r\nFN:john smith\r\n") {
+			t.Error("GET got", resp.Body.String())
+		}
+
+		resp = api.Get("/contacts/"+id.String(), "Accept: text/vcard;q=0.5, application/json")
+		if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+			t.Error("GET preferring JSON got content type", ct)
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		resp := api.Post("/contacts:import", "Content-Type: text/vcard", strings.NewReader(""+
+			"BEGIN:VCARD\r\nVERSION:4.0\r\nN:Doe;Jane;;;\r\nBDAY:19900101\r\nEND:VCARD\r\n"+
+			"BEGIN:VCARD\r\nVERSION:4.0\r\nN:Doe;John;;;\r\nEND:VCARD\r\n"+
+			"BEGIN:VCARD\r\nVERSION:5.0\r\nEND:VCARD\r\n",
+		))
+		if resp.Code != http.StatusOK {
+			t.Fatal("POST got", resp.Code, resp.Body.String())
+		}
+		var report restapi.ImportReportModel
+		if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		var statuses []int
+		for _, item := range report.Items {
+			statuses = append(statuses, item.Status)
+		}
+		want := []int{http.StatusUnprocessableEntity, http.StatusBadRequest}
+		if report.Created != 1 || report.Failed != 2 || !slices.Equal(statuses, want) {
+			t.Errorf("POST got %+v", report)
+		}
+
+		resp = api.Post("/contacts:import", "Content-Type: application/json", strings.NewReader("{}"))
+		if resp.Code != http.StatusUnsupportedMediaType {
+			t.Error("POST JSON got", resp.Code)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		for range domain.ContactsListMaxLimit {
+			_, _ = store.ContactsSet(t.Context(), &domain.Contact{Firstname: "bob", Lastname: "smith"})
+		}
+		resp := api.Get("/contacts:export")
+		if resp.Code != http.StatusOK {
+			t.Fatal("GET got", resp.Code)
+		}
+		if n := strings.Count(resp.Body.String(), "BEGIN:VCARD"); n != domain.ContactsListMaxLimit+2 {
+			t.Error("GET got", n, "cards, want", domain.ContactsListMaxLimit+2)
+		}
+		if trailer := resp.Result().Trailer.Get("Export-Error"); trailer != "" {
+			t.Error("GET got trailer", trailer)
+		}
+
+		_, api := humatest.New(t)
+		reg := restapi.ServiceRegisterer{Service: truncatedService{reg.Service}}
+		reg.RegisterContactsExport(api)
+		resp = api.Get("/contacts:export")
+		if resp.Code != http.StatusOK {
+			t.Fatal("GET got", resp.Code)
+		}
+		if n := strings.Count(resp.Body.String(), "BEGIN:VCARD"); n != domain.ContactsListMaxLimit {
+			t.Error("GET got", n, "cards, want", domain.ContactsListMaxLimit)
+		}
+		if trailer := resp.Result().Trailer.Get("Export-Error"); trailer == "" {
+			t.Error("GET got no trailer")
+		}
+	})
+}
+
+// truncatedService is a [domain.Service] failing to list contacts past their first page.
+type truncatedService struct{ domain.Service }
+
+func (s truncatedService) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	if q.Cursor != "" {
+		return nil, errors.New("unavailable")
+	}
+	return s.Service.ContactsList(ctx, q)
+}
+
+func TestCSV(t *testing.T) {
+	_, api := humatest.New(t)
+	reg := restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: stores.MustNewMock()}}
+	reg.RegisterContactsExport(api)
+	reg.RegisterContactsImport(api)
+
+	resp := api.Post("/contacts:import?column=Name%3Dfirstname&column=Surname%3Dlastname&column=Born%3Dbirthday",
+		"Content-Type: text/csv", strings.NewReader(""+
+			"Name,Surname,Born\n"+
+			"john,smith,1999-12-31\n"+
+			"jane,doe,31/12/1999\n"+
+			"bob,,1980-06-15\n"+
+			"\"john \"\"jr\"\"\",smith\n",
+		))
+	if resp.Code != http.StatusOK {
+		t.Fatal("POST got", resp.Code)
+	}
+	var report restapi.ImportReportModel
+	if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	var statuses []int
+	for _, item := range report.Items {
+		statuses = append(statuses, item.Status)
+	}
+	want := []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusUnprocessableEntity}
+	if !slices.Equal(statuses, want) || report.Items[0].Index != 2 ||
+		report.Items[0].Errors[0].Location != "body.Born" {
+		t.Errorf("POST got %+v", report)
+	}
+
+	body := "firstname\n" + strings.Repeat("bob\n", restapi.ImportReportMaxItems+1)
+	resp = api.Post("/contacts:import", "Content-Type: text/csv", strings.NewReader(body))
+	report = restapi.ImportReportModel{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed != restapi.ImportReportMaxItems+1 || len(report.Items) != restapi.ImportReportMaxItems {
+		t.Errorf("POST of too many failures got %d failed, %d items", report.Failed, len(report.Items))
+	}
+
+	resp = api.Post("/contacts:import?column=Name%3Dnickname", "Content-Type: text/csv", strings.NewReader(""))
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Error("POST with an unknown field got", resp.Code)
+	}
+	resp = api.Post("/contacts:import?column=Name%3Dfirstname", "Content-Type: text/csv", strings.NewReader("First\n"))
+	if resp.Code != http.StatusBadRequest {
+		t.Error("POST without a mapped column got", resp.Code)
+	}
+
+	resp = api.Get("/contacts:export?format=csv")
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Disposition") != `attachment; filename="contacts.csv"` {
+		t.Fatal("GET got", resp.Code, resp.Header())
+	}
+	if !strings.HasPrefix(resp.Body.String(), "id,firstname,lastname,birthday,") ||
+		!strings.Contains(resp.Body.String(), ",john,smith,1999-12-31,") {
+		t.Error("GET got", resp.Body.String())
+	}
+}
+
+func TestBatch(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock()
+	restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: store}}.RegisterContactsBatch(api)
+
+	id, _ := store.ContactsSet(t.Context(), &domain.Contact{Firstname: "john", Lastname: "smith"})
+	contact := map[string]any{"firstname": "jane", "lastname": "doe", "birthday": "1999-12-31"}
+
+	statuses := func(body map[string]any) []int {
+		t.Helper()
+		resp := api.Post("/contacts:batch", body)
+		if resp.Code != http.StatusOK {
+			t.Fatal("POST got", resp.Code, resp.Body.String())
+		}
+		var report restapi.BatchReportModel
+		if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		var statuses []int
+		for _, item := range report.Items {
+			statuses = append(statuses, item.Status)
+			if item.Status == http.StatusFailedDependency && item.ID != nil {
+				t.Error("POST got the ID of an aborted action", item.ID)
+			}
+		}
+		return statuses
+	}
+
+	got := statuses(map[string]any{"actions": []any{
+		map[string]any{"op": "create", "contact": contact},
+		map[string]any{"op": "update", "id": id, "version": 2, "contact": contact},
+	}})
+	if want := []int{http.StatusFailedDependency, http.StatusPreconditionFailed}; !slices.Equal(got, want) {
+		t.Error("POST with a stale version got", got, "want", want)
+	}
+
+	got = statuses(map[string]any{"actions": []any{
+		map[string]any{"op": "create", "contact": contact},
+		map[string]any{"op": "update", "id": id, "version": 1, "contact": contact},
+		map[string]any{"op": "delete", "id": id},
+	}})
+	if want := []int{http.StatusCreated, http.StatusNoContent, http.StatusNoContent}; !slices.Equal(got, want) {
+		t.Error("POST got", got, "want", want)
+	}
+
+	resp := api.Post("/contacts:batch", map[string]any{"actions": []any{
+		map[string]any{"op": "update", "contact": contact},
+		map[string]any{"op": "delete"},
+	}})
+	if resp.Code != http.StatusUnprocessableEntity ||
+		!strings.Contains(resp.Body.String(), `"location":"body.actions[0].id"`) ||
+		!strings.Contains(resp.Body.String(), `"location":"body.actions[1].id"`) {
+		t.Error("POST without IDs got", resp.Code, resp.Body.String())
+	}
+}
+
+func TestTrash(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock(
+		&domain.Contact{Firstname: "john", Lastname: "smith"},
+		&domain.Contact{Firstname: "jane", Lastname: "doe"},
+	)
+	reg := restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: store}}
+	reg.RegisterContactsList(api)
+	reg.RegisterContactsDelete(api)
+	reg.RegisterContactsTrash(api)
+	reg.RegisterContactsRestore(api)
+	reg.RegisterContactsPurge(api)
+
+	list := func(path string) []string {
+		t.Helper()
+		resp := api.Get(path)
+		if resp.Code != http.StatusOK {
+			t.Fatal("GET", path, "got", resp.Code, resp.Body.String())
+		}
+		var page restapi.TrashPageModel
+		if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, c := range page.Items {
+			if path == "/contacts/trash" && c.DeletedAt.IsZero() {
+				t.Error("GET", path, "got no deletion time for", c.ID)
+			}
+			ids = append(ids, c.ID.String())
+		}
+		return ids
+	}
+
+	page, _ := store.ContactsList(t.Context(), &domain.ContactsQuery{})
+	id := page.Contacts[0].ID.String()
+	if resp := api.Delete("/contacts/" + id); resp.Code != http.StatusNoContent {
+		t.Fatal("DELETE got", resp.Code)
+	}
+	if got := list("/contacts/trash"); !slices.Equal(got, []string{id}) {
+		t.Error("GET trash got", got, "want", id)
+	}
+	if got := list("/contacts"); slices.Contains(got, id) {
+		t.Error("GET got trashed contact", id)
+	}
+
+	if resp := api.Post("/contacts/trash/" + id + "/restore"); resp.Code != http.StatusNoContent {
+		t.Fatal("POST restore got", resp.Code)
+	}
+	if got := list("/contacts"); !slices.Contains(got, id) {
+		t.Error("GET got", got, "want restored contact", id)
+	}
+	if resp := api.Post("/contacts/trash/" + id + "/restore"); resp.Code != http.StatusNotFound {
+		t.Error("POST restore of a contact not in the trash got", resp.Code)
+	}
+
+	api.Delete("/contacts/" + id)
+	if resp := api.Delete("/contacts/trash/" + id); resp.Code != http.StatusNoContent {
+		t.Fatal("DELETE trash got", resp.Code)
+	}
+	if got := list("/contacts/trash"); len(got) != 0 {
+		t.Error("GET trash after purge got", got)
+	}
+	if resp := api.Post("/contacts/trash/" + id + "/restore"); resp.Code != http.StatusNotFound {
+		t.Error("POST restore of a purged contact got", resp.Code)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	_, api := humatest.New(t)
+	store := stores.MustNewMock()
+	service := &domain.ServiceStore{
+		Store: wrappers.StoreAuditor{Store: store, Actor: func(context.Context) string { return "alice" }},
+	}
+	restapi.ServiceRegisterer{Service: service}.RegisterContactsHistory(api)
+
+	id, err := service.ContactsCreate(t.Context(), &domain.Contact{
+		Firstname: "john",
+		Lastname:  "smith",
+		Birthday:  time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"jane", "jim"} {
+		_, _ = service.ContactsPatch(t.Context(), id, &domain.ContactPatch{Firstname: &name})
+	}
+
+	link := regexp.MustCompile(`^<(.+)>; rel="next"$`)
+	var afters []string
+	for path := "/contacts/" + id.String() + "/history?limit=2"; path != ""; {
+		resp := api.Get(path)
+		if resp.Code != http.StatusOK {
+			t.Fatal("GET", path, "got", resp.Code, resp.Body.String())
+		}
+		var page restapi.HistoryPageModel
+		if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range page.Items {
+			if e.Actor != "alice" || len(e.Changes) == 0 {
+				t.Errorf("GET %s got event %+v", path, e)
+				continue
+			}
+			afters = append(afters, e.Action+":"+e.Changes[0].After)
+		}
+
+		path = ""
+		if m := link.FindStringSubmatch(resp.Header().Get("Link")); m != nil {
+			path = m[1]
+		}
+	}
+	if want := []string{"update:jim", "update:jane", "create:john"}; !slices.Equal(afters, want) {
+		t.Error("got", afters, "want", want)
+	}
+
+	_, api = humatest.New(t)
+	service = &domain.ServiceStore{Store: struct{ domain.Store }{store}}
+	restapi.ServiceRegisterer{Service: service}.RegisterContactsHistory(api)
+	if resp := api.Get("/contacts/" + id.String() + "/history"); resp.Code != http.StatusNotImplemented {
+		t.Error("GET without history got", resp.Code)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	_, api := humatest.New(t)
+	store := &webhooks.Memory{}
+	huma.AutoRegister(api, restapi.WebhooksRegisterer{Store: store})
+
+	resp := api.Post("/webhooks", map[string]any{"url": "ftp://example.com", "types": []string{"contact.moved"}})
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Error("POST invalid webhook got", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Post("/webhooks", map[string]any{
+		"url":   "https://example.com/hooks",
+		"types": []string{"contact.created"},
+	})
+	if resp.Code != http.StatusOK {
+		t.Fatal("POST webhook got", resp.Code, resp.Body.String())
+	}
+	var created restapi.WebhookModel
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.Secret == "" || created.Created.IsZero() {
+		t.Errorf("POST webhook got %+v", created)
+	}
+
+	resp = api.Get("/webhooks/" + created.ID)
+	if resp.Code != http.StatusOK || strings.Contains(resp.Body.String(), created.Secret) {
+		t.Error("GET webhook got", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/webhooks")
+	var list restapi.WebhooksPageModel
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Items) != 1 {
+		t.Error("GET webhooks got", resp.Code, resp.Body.String())
+	}
+
+	err := store.DeliveriesAppend(t.Context(), &webhooks.Delivery{
+		ID:           "delivery",
+		Subscription: created.ID,
+		Event:        &domain.Event{ID: "event", Type: domain.ContactDeleted},
+		Attempts:     10,
+		LastError:    "status 503",
+		Dead:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = api.Get("/webhooks/" + created.ID + "/dead-letters")
+	var dead restapi.DeadLettersPageModel
+	if err := json.Unmarshal(resp.Body.Bytes(), &dead); err != nil || len(dead.Items) != 1 ||
+		dead.Items[0].Event.ID != "event" || dead.Items[0].LastError != "status 503" {
+		t.Error("GET dead letters got", resp.Code, resp.Body.String())
+	}
+	resp = api.Post("/webhooks/" + created.ID + "/dead-letters/redeliver")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), `"redelivered":1`) {
+		t.Error("POST redeliver got", resp.Code, resp.Body.String())
+	}
+
+	if resp = api.Delete("/webhooks/" + created.ID); resp.Code != http.StatusNoContent {
+		t.Error("DELETE webhook got", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get("/webhooks/" + created.ID + "/dead-letters"); resp.Code != http.StatusNotFound {
+		t.Error("GET dead letters of a deleted webhook got", resp.Code)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	handler, api := humatest.New(t)
+	log := &wrappers.EventLog{}
+	service := &domain.ServiceStore{Store: stores.MustNewMock(), Events: log}
+	restapi.ServiceRegisterer{Service: service, EventsHeartbeat: 10 * time.Millisecond}.RegisterContactsEvents(api)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	stream := func(lastEventID, query string) *bufio.Reader {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/contacts/events"+query, nil)
+		req.Header.Set("Last-Event-ID", lastEventID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != restapi.EventStreamMediaType {
+			t.Fatal("GET events got", resp.Status, resp.Header)
+		}
+		return bufio.NewReader(resp.Body)
+	}
+	// next reads the next event of a stream, skipping heartbeats, nil once the stream ends
+	next := func(r *bufio.Reader) (id string, e *restapi.EventModel, heartbeats int) {
+		t.Helper()
+		for {
+			line, err := r.ReadString('\n')
+			switch {
+			case err != nil:
+				return "", nil, heartbeats
+			case line == ": heartbeat\n":
+				heartbeats++
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+			case strings.HasPrefix(line, "data: "):
+				e = &restapi.EventModel{}
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), e); err != nil {
+					t.Fatal(err)
+				}
+			case line == "\n" && e != nil:
+				return id, e, heartbeats
+			}
+		}
+	}
+
+	first, err := service.ContactsCreate(t.Context(), domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := stream("", "")
+	second, err := service.ContactsCreate(t.Context(), domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := service.ContactsDelete(t.Context(), second, 0); err != nil {
+		t.Fatal(err)
+	}
+	id, e, _ := next(live)
+	if e == nil || e.Type != "contact.created" || e.ContactID != second || e.Contact == nil || id != e.ID {
+		t.Fatalf("got event %s %+v, want the creation of %s", id, e, second)
+	}
+	_, e, heartbeats := next(live)
+	if e == nil || e.Type != "contact.deleted" || e.Contact != nil || heartbeats == 0 {
+		t.Fatalf("got event %+v after %d heartbeats, want a deletion after heartbeats", e, heartbeats)
+	}
+
+	if resp := api.Get("/contacts/events", "Last-Event-ID: unknown"); resp.Code != http.StatusGone {
+		t.Error("GET events after an unknown one got", resp.Code, "want", http.StatusGone)
+	}
+
+	resumed := stream(id, "")
+	if _, e, _ := next(resumed); e == nil || e.Type != "contact.deleted" {
+		t.Fatalf("got event %+v after %s, want the deletion", e, id)
+	}
+
+	// the filtered stream skips the deletion of the other contact
+	filtered := stream(id, "?contact_id="+first.String())
+	if _, err := service.ContactsPatch(t.Context(), first, &domain.ContactPatch{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*bufio.Reader{live, resumed, filtered} {
+		if _, e, _ := next(r); e == nil || e.ContactID != first || e.Type != "contact.updated" {
+			t.Fatalf("got event %+v, want the update of %s", e, first)
+		}
+	}
+
+	log.Close()
+	for _, r := range []*bufio.Reader{live, filtered, resumed} {
+		if _, e, _ := next(r); e != nil {
+			t.Errorf("got event %+v, want the stream to end", e)
+		}
+	}
+
+	_, api = humatest.New(t)
+	restapi.ServiceRegisterer{Service: &domain.ServiceStore{Store: stores.MustNewMock()}}.RegisterContactsEvents(api)
+	if resp := api.Get("/contacts/events"); resp.Code != http.StatusNotImplemented {
+		t.Error("GET events without a log got", resp.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// TrashedContactModel is a contact in the trash.
+type TrashedContactModel struct {
+	ContactModel
+
+	DeletedAt time.Time `json:"deleted_at" readOnly:"true" doc:"time the contact was moved to the trash"`
+}
+
+type TrashPageModel struct {
+	Items []TrashedContactModel `json:"items"`
+	Next  string                `json:"next,omitempty" doc:"cursor to the next page, absent on the last page"`
+}
+
+func (reg ServiceRegisterer) RegisterContactsTrash(api huma.API) {
+	type output struct {
+		Link string `header:"Link"`
+		Body TrashPageModel
+	}
+
+	handler := func(ctx context.Context, i *contactsListInput) (*output, error) {
+		q, err := i.query()
+		if err != nil {
+			return nil, err
+		}
+		q.Trashed = true
+
+		page, err := reg.Service.ContactsList(ctx, q)
+		if err != nil {
+			return nil, problemIn("query", err)
+		}
+
+		o := &output{Body: TrashPageModel{Items: make([]TrashedContactModel, len(page.Contacts)), Next: page.Next}}
+		for n, c := range page.Contacts {
+			o.Body.Items[n] = TrashedContactModel{ContactModel: NewContactModel(c), DeletedAt: c.DeletedAt}
+		}
+		if page.Next != "" {
+			o.Link = i.link(page.Next)
+		}
+		return o, nil
+	}
+
+	huma.Get(api, "/contacts/trash", handler, withErrors(http.StatusForbidden, http.StatusUnprocessableEntity))
+}
+
+func (reg ServiceRegisterer) RegisterContactsRestore(api huma.API) {
+	type input struct {
+		ContactID domain.ContactID `path:"id"`
+	}
+	type output struct{}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		return nil, problem(reg.Service.ContactsRestore(ctx, i.ContactID))
+	}
+
+	huma.Post(api, "/contacts/trash/{id}/restore", handler,
+		withErrors(http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity))
+}
+
+func (reg ServiceRegisterer) RegisterContactsPurge(api huma.API) {
+	type input struct {
+		ContactID domain.ContactID `path:"id"`
+	}
+	type output struct{}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		return nil, problem(reg.Service.ContactsPurge(ctx, i.ContactID))
+	}
+
+	huma.Delete(api, "/contacts/trash/{id}", handler, func(op *huma.Operation) {
+		op.Description = "Permanently deletes a contact from the trash."
+	}, withErrors(http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity))
+}
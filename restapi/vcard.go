@@ -0,0 +1,15 @@
+package restapi
+
+import (
+	"bytes"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/vcard"
+)
+
+// encodeVCard encodes a [domain.Contact] as a vCard entry.
+func encodeVCard(c *domain.Contact) ([]byte, error) {
+	var b bytes.Buffer
+	err := vcard.NewEncoder(&b).Encode(c)
+	return b.Bytes(), err
+}
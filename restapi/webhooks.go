@@ -0,0 +1,195 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/webhooks"
+)
+
+// WebhooksRegisterer registers endpoints in a [huma.API] to manage the subscriptions of a [webhooks.Store].
+type WebhooksRegisterer struct {
+	Store webhooks.Store
+}
+
+// WebhookModel is a subscription of a URL to the events of contacts.
+type WebhookModel struct {
+	ID      string    `json:"id"               readOnly:"true"`
+	URL     string    `json:"url"              example:"https://example.com/hooks" format:"uri"`
+	Secret  string    `json:"secret,omitempty" doc:"signs payloads, generated if absent, returned on creation only"`
+	Types   []string  `json:"types,omitempty"  enum:"contact.created,contact.updated,contact.deleted" doc:"all if absent"`
+	Created time.Time `json:"created"          readOnly:"true"`
+}
+
+func webhookModel(s *webhooks.Subscription) WebhookModel {
+	return WebhookModel{
+		ID:      s.ID,
+		URL:     s.URL,
+		Types:   convert(s.Types, func(typ domain.EventType) string { return string(typ) }),
+		Created: s.Created,
+	}
+}
+
+type WebhooksPageModel struct {
+	Items []WebhookModel `json:"items"`
+}
+
+// DeadLetterModel is a delivery that ran out of attempts.
+type DeadLetterModel struct {
+	ID        string     `json:"id"`
+	Event     EventModel `json:"event"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error" example:"status 503"`
+}
+
+type DeadLettersPageModel struct {
+	Items []DeadLetterModel `json:"items"`
+}
+
+type RedeliveredModel struct {
+	Redelivered int `json:"redelivered" doc:"number of deliveries scheduled again"`
+}
+
+func (reg WebhooksRegisterer) RegisterWebhooksCreate(api huma.API) {
+	type input struct {
+		Body WebhookModel
+	}
+	type output struct {
+		Body WebhookModel
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		s := &webhooks.Subscription{
+			URL:     i.Body.URL,
+			Secret:  i.Body.Secret,
+			Types:   convert(i.Body.Types, func(typ string) domain.EventType { return domain.EventType(typ) }),
+			Created: time.Now(),
+		}
+		if s.Secret == "" {
+			s.Secret = rand.Text()
+		}
+		err := s.Validate()
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		err = reg.Store.WebhooksCreate(ctx, s)
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		o := &output{Body: webhookModel(s)}
+		o.Body.Secret = s.Secret
+		return o, nil
+	}
+
+	huma.Post(api, "/webhooks", handler, withErrors(http.StatusForbidden, http.StatusUnprocessableEntity))
+}
+
+func (reg WebhooksRegisterer) RegisterWebhooksList(api huma.API) {
+	type input struct{}
+	type output struct {
+		Body WebhooksPageModel
+	}
+
+	handler := func(ctx context.Context, _ *input) (*output, error) {
+		subscriptions, err := reg.Store.WebhooksList(ctx)
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &output{Body: WebhooksPageModel{Items: convert(subscriptions, webhookModel)}}, nil
+	}
+
+	huma.Get(api, "/webhooks", handler, withErrors(http.StatusForbidden))
+}
+
+func (reg WebhooksRegisterer) RegisterWebhooksRead(api huma.API) {
+	type input struct {
+		ID string `path:"id"`
+	}
+	type output struct {
+		Body WebhookModel
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		s, err := reg.Store.WebhooksGet(ctx, i.ID)
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &output{Body: webhookModel(s)}, nil
+	}
+
+	huma.Get(api, "/webhooks/{id}", handler, withErrors(http.StatusForbidden, http.StatusNotFound))
+}
+
+func (reg WebhooksRegisterer) RegisterWebhooksDelete(api huma.API) {
+	type input struct {
+		ID string `path:"id"`
+	}
+	type output struct{}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		return nil, problem(reg.Store.WebhooksDelete(ctx, i.ID))
+	}
+
+	huma.Delete(api, "/webhooks/{id}", handler, func(op *huma.Operation) {
+		op.Description = "Deletes a subscription and its pending deliveries."
+	}, withErrors(http.StatusForbidden, http.StatusNotFound))
+}
+
+func (reg WebhooksRegisterer) RegisterWebhooksDeadLetters(api huma.API) {
+	type input struct {
+		ID string `path:"id"`
+	}
+	type output struct {
+		Body DeadLettersPageModel
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		dead, err := reg.Store.DeliveriesDead(ctx, i.ID)
+		if err != nil {
+			return nil, problem(err)
+		}
+
+		o := &output{Body: DeadLettersPageModel{Items: make([]DeadLetterModel, len(dead))}}
+		for n, d := range dead {
+			o.Body.Items[n] = DeadLetterModel{
+				ID:        d.ID,
+				Event:     NewEventModel(d.Event),
+				Attempts:  d.Attempts,
+				LastError: d.LastError,
+			}
+		}
+		return o, nil
+	}
+
+	huma.Get(api, "/webhooks/{id}/dead-letters", handler, func(op *huma.Operation) {
+		op.Description = "Lists the deliveries of a subscription that ran out of attempts."
+	}, withErrors(http.StatusForbidden, http.StatusNotFound))
+}
+
+func (reg WebhooksRegisterer) RegisterWebhooksRedeliver(api huma.API) {
+	type input struct {
+		ID string `path:"id"`
+	}
+	type output struct {
+		Body RedeliveredModel
+	}
+
+	handler := func(ctx context.Context, i *input) (*output, error) {
+		n, err := reg.Store.DeliveriesRevive(ctx, i.ID, time.Now())
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &output{Body: RedeliveredModel{n}}, nil
+	}
+
+	huma.Post(api, "/webhooks/{id}/dead-letters/redeliver", handler, func(op *huma.Operation) {
+		op.Description = "Schedules the dead-lettered deliveries of a subscription again, with fresh attempts."
+	}, withErrors(http.StatusForbidden, http.StatusNotFound))
+}
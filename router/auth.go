@@ -0,0 +1,210 @@
+package router
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Subject string   `json:"subject"`          // e.g. the "sub" claim of a JWT or the owner of an API key
+	Scheme  string   `json:"-"`                // name of the security scheme it authenticated with
+	Roles   []string `json:"roles,omitempty"`  // roles and scopes granted to the caller
+	Tenant  string   `json:"tenant,omitempty"` // tenant the caller belongs to, if any
+}
+
+// WithPrincipal returns a context carrying a [Principal], e.g. for tests or background jobs.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	r := requestFrom(ctx).clone()
+	r.principal = p
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// PrincipalFrom returns the [Principal] of a context, nil if anonymous.
+func PrincipalFrom(ctx context.Context) *Principal { return requestFrom(ctx).principal }
+
+// Authenticator authenticates the credentials of a security scheme.
+type Authenticator struct {
+	Scheme       *huma.SecurityScheme
+	Authenticate func(ctx context.Context, credentials string) (*Principal, error)
+}
+
+// BearerAuthenticator returns an [Authenticator] of bearer tokens of a format, e.g. "JWT".
+func BearerAuthenticator(format string, authenticate func(context.Context, string) (*Principal, error)) Authenticator {
+	return Authenticator{
+		Scheme:       &huma.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: format},
+		Authenticate: authenticate,
+	}
+}
+
+// APIKeyAuthenticator returns an [Authenticator] of static API keys sent in a request header.
+func APIKeyAuthenticator(header string, keys map[string]*Principal) Authenticator {
+	return Authenticator{
+		Scheme: &huma.SecurityScheme{Type: "apiKey", In: "header", Name: header},
+		Authenticate: func(_ context.Context, credentials string) (*Principal, error) {
+			var found *Principal
+			for key, p := range keys {
+				if subtle.ConstantTimeCompare([]byte(key), []byte(credentials)) == 1 {
+					found = p
+				}
+			}
+			if found == nil {
+				return nil, errors.New("unknown API key")
+			}
+			return found, nil
+		},
+	}
+}
+
+// location returns the location of the credentials in a request.
+func (a Authenticator) location() string {
+	if a.Scheme.Type == "apiKey" {
+		return a.Scheme.In + "." + a.Scheme.Name
+	}
+	return "header.Authorization"
+}
+
+// credentials returns the credentials of a request, empty if none.
+func (a Authenticator) credentials(ctx huma.Context) string {
+	switch {
+	case a.Scheme.Type == "http" && strings.EqualFold(a.Scheme.Scheme, "bearer"):
+		scheme, token, _ := strings.Cut(ctx.Header("Authorization"), " ")
+		if !strings.EqualFold(scheme, "bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	case a.Scheme.Type == "apiKey" && a.Scheme.In == "header":
+		return ctx.Header(a.Scheme.Name)
+	default:
+		return ""
+	}
+}
+
+// OptAuth returns a [huma.API] option declaring security schemes and requiring any of them for its
+// operations, unless they declare an empty security requirement. Requirements combining schemes are not supported.
+//
+// Authenticated requests carry their [Principal], see [PrincipalFrom], others fail with [http.StatusUnauthorized].
+// It is best used with [OptGroup] to only secure the operations of the group.
+func OptAuth(authenticators map[string]Authenticator) func(huma.API) {
+	return func(api huma.API) {
+		components := api.OpenAPI().Components
+		if components.SecuritySchemes == nil {
+			components.SecuritySchemes = map[string]*huma.SecurityScheme{}
+		}
+		names := slices.Sorted(maps.Keys(authenticators))
+		requirements := make([]map[string][]string, len(names))
+		for n, name := range names {
+			components.SecuritySchemes[name] = authenticators[name].Scheme
+			requirements[n] = map[string][]string{name: {}}
+		}
+
+		unauthorized := errorResponse(api, http.StatusUnauthorized)
+		modifier := func(op *huma.Operation) {
+			if op.Security == nil {
+				op.Security = requirements
+			}
+			if len(op.Security) > 0 {
+				if op.Responses == nil {
+					op.Responses = map[string]*huma.Response{}
+				}
+				if _, ok := op.Responses[strconv.Itoa(http.StatusUnauthorized)]; !ok {
+					op.Responses[strconv.Itoa(http.StatusUnauthorized)] = unauthorized
+				}
+			}
+		}
+		if g, ok := api.(interface{ UseSimpleModifier(func(*huma.Operation)) }); ok {
+			g.UseSimpleModifier(modifier)
+		} else {
+			api.OpenAPI().Security = requirements
+		}
+
+		api.UseMiddleware(authMiddleware(api, authenticators, requirements))
+	}
+}
+
+func authMiddleware(
+	api huma.API,
+	authenticators map[string]Authenticator,
+	defaults []map[string][]string,
+) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		requirements := ctx.Operation().Security
+		if requirements == nil {
+			requirements = defaults
+		}
+		if len(requirements) == 0 {
+			next(ctx)
+			return
+		}
+
+		var (
+			errs      []error
+			challenge string
+		)
+		for _, requirement := range requirements {
+			for name := range requirement {
+				a, ok := authenticators[name]
+				if !ok {
+					continue
+				}
+				if a.Scheme.Type == "http" {
+					challenge = "Bearer"
+				}
+
+				credentials := a.credentials(ctx)
+				if credentials == "" {
+					continue
+				}
+				p, err := a.Authenticate(ctx.Context(), credentials)
+				if err != nil {
+					errs = append(errs, &huma.ErrorDetail{Message: err.Error(), Location: a.location()})
+					continue
+				}
+
+				principal := *p
+				principal.Scheme = name
+				ctx, r := withRequest(ctx)
+				r.principal = &principal
+				next(ctx)
+				return
+			}
+		}
+
+		if challenge != "" {
+			ctx.SetHeader("WWW-Authenticate", challenge)
+		}
+		msg := "authentication required"
+		if len(errs) > 0 {
+			msg = "invalid credentials"
+		}
+		huma.WriteErr(api, ctx, http.StatusUnauthorized, msg, errs...) //nolint: errcheck,gosec // nothing to do
+	}
+}
+
+// errorResponse returns the OpenAPI response of an error status, as defined by huma for [huma.Operation.Errors].
+func errorResponse(api huma.API, status int) *huma.Response {
+	example := huma.NewError(0, "")
+	contentType := "application/json"
+	if ctf, ok := example.(huma.ContentTypeFilter); ok {
+		contentType = ctf.ContentType(contentType)
+	}
+	typ := reflect.TypeOf(example)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return &huma.Response{
+		Description: http.StatusText(status),
+		Content: map[string]*huma.MediaType{
+			contentType: {Schema: api.OpenAPI().Components.Schemas.Schema(typ, true, "Error")},
+		},
+	}
+}
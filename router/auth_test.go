@@ -0,0 +1,196 @@
+package router_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/rlibaert/service-example-go/router"
+)
+
+func TestAuth(t *testing.T) {
+	now := time.Date(2025, time.November, 26, 19, 27, 42, 0, time.UTC)
+	hmacKey := jose.JSONWebKey{Key: []byte("0123456789abcdef0123456789abcdef"), KeyID: "hmac", Algorithm: "HS256"}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecJWK := jose.JSONWebKey{Key: ecKey, KeyID: "ec", Algorithm: "ES256"}
+
+	b, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{hmacKey, ecJWK.Public()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwks, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(key jose.JSONWebKey, claims any) string {
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.SignatureAlgorithm(key.Algorithm), Key: key},
+			(&jose.SignerOptions{}).WithType("JWT"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.Signed(signer).Claims(claims).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	claims := func(issuer, audience string, expiry time.Time) jwt.Claims {
+		return jwt.Claims{
+			Subject:  "alice",
+			Issuer:   issuer,
+			Audience: jwt.Audience{audience},
+			Expiry:   jwt.NewNumericDate(expiry),
+		}
+	}
+	valid := claims("issuer", "contacts", now.Add(time.Hour))
+
+	_, api := humatest.New(t)
+	var (
+		principal *router.Principal
+		logged    string
+	)
+	api.UseMiddleware(router.RequestsLogMiddleware(func(_ context.Context, r slog.Record) {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "principal" {
+				logged = a.Value.String()
+			}
+			return true
+		})
+	}))
+	router.OptGroup("/secure", router.OptAuth(map[string]router.Authenticator{
+		"bearer": router.BearerAuthenticator("JWT", (&router.JWTValidator{
+			Keys:     &router.JWKS{Location: jwks},
+			Issuer:   "issuer",
+			Audience: "contacts",
+			Now:      func() time.Time { return now },
+		}).Authenticate),
+		"apikey": router.APIKeyAuthenticator("X-API-Key", map[string]*router.Principal{
+			"secret": {Subject: "ci", Roles: []string{"contacts:read"}},
+		}),
+	}), func(api huma.API) {
+		handler := func(ctx context.Context, _ *struct{}) (*struct{}, error) {
+			principal = router.PrincipalFrom(ctx)
+			return nil, nil
+		}
+		huma.Get(api, "/private", handler)
+		huma.Get(api, "/public", handler, func(op *huma.Operation) { op.Security = []map[string][]string{} })
+	})(api)
+
+	for _, tt := range []struct {
+		name    string
+		path    string
+		headers []any
+		code    int
+		subject string
+		scheme  string
+	}{
+		{"anonymous", "/secure/private", nil, http.StatusUnauthorized, "", ""},
+		{"public", "/secure/public", nil, http.StatusNoContent, "", ""},
+		{"apikey", "/secure/private", []any{"X-API-Key: secret"}, http.StatusNoContent, "ci", "apikey"},
+		{"apikey unknown", "/secure/private", []any{"X-API-Key: nope"}, http.StatusUnauthorized, "", ""},
+		{"hmac", "/secure/private", []any{"Authorization: Bearer " + sign(hmacKey, valid)},
+			http.StatusNoContent, "alice", "bearer"},
+		{"ecdsa", "/secure/private", []any{"Authorization: Bearer " + sign(ecJWK, valid)},
+			http.StatusNoContent, "alice", "bearer"},
+		{"expired", "/secure/private", []any{
+			"Authorization: Bearer " + sign(hmacKey, claims("issuer", "contacts", now.Add(-time.Hour))),
+		}, http.StatusUnauthorized, "", ""},
+		{"issuer", "/secure/private", []any{
+			"Authorization: Bearer " + sign(hmacKey, claims("other", "contacts", now.Add(time.Hour))),
+		}, http.StatusUnauthorized, "", ""},
+		{"audience", "/secure/private", []any{
+			"Authorization: Bearer " + sign(hmacKey, claims("issuer", "other", now.Add(time.Hour))),
+		}, http.StatusUnauthorized, "", ""},
+		{"unsigned", "/secure/private", []any{"Authorization: Bearer not.a.jwt"}, http.StatusUnauthorized, "", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			principal = nil
+			resp := api.Get(tt.path, tt.headers...)
+			if resp.Code != tt.code {
+				t.Fatalf("got status %d, want %d: %s", resp.Code, tt.code, resp.Body)
+			}
+			if resp.Code == http.StatusUnauthorized && resp.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("got WWW-Authenticate %q", resp.Header().Get("WWW-Authenticate"))
+			}
+			var subject, scheme string
+			if principal != nil {
+				subject, scheme = principal.Subject, principal.Scheme
+			}
+			if subject != tt.subject || scheme != tt.scheme {
+				t.Errorf("got principal %q from %q, want %q from %q", subject, scheme, tt.subject, tt.scheme)
+			}
+			if logged != tt.subject {
+				t.Errorf("logged principal %q, want %q", logged, tt.subject)
+			}
+		})
+	}
+
+	t.Run("OpenAPI", func(t *testing.T) {
+		oapi := api.OpenAPI()
+		if len(oapi.Components.SecuritySchemes) != 2 {
+			t.Errorf("got security schemes %v", oapi.Components.SecuritySchemes)
+		}
+		private := oapi.Paths["/secure/private"].Get
+		if len(private.Security) != 2 || private.Responses["401"] == nil {
+			t.Errorf("got private security %v and responses %v", private.Security, private.Responses)
+		}
+		public := oapi.Paths["/secure/public"].Get
+		if len(public.Security) != 0 || public.Responses["401"] != nil {
+			t.Errorf("got public security %v and responses %v", public.Security, public.Responses)
+		}
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: []byte("a"), KeyID: "a"}}}
+	var loads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		loads++
+		json.NewEncoder(w).Encode(set) //nolint: errcheck,errchkjson // test
+	}))
+	defer server.Close()
+
+	jwks := &router.JWKS{Location: server.URL, RefreshInterval: time.Hour}
+	keys, err := jwks.Keys(t.Context(), "a")
+	if err != nil || len(keys) != 1 || loads != 1 {
+		t.Fatalf("got %d keys after %d loads: %v", len(keys), loads, err)
+	}
+
+	set.Keys = append(set.Keys, jose.JSONWebKey{Key: []byte("b"), KeyID: "b"})
+	_, err = jwks.Keys(t.Context(), "b")
+	if err == nil || loads != 1 {
+		t.Errorf("got %v after %d loads, want an unknown key without reloading", err, loads)
+	}
+
+	jwks.RefreshInterval = time.Nanosecond
+	keys, err = jwks.Keys(t.Context(), "")
+	if err != nil || len(keys) != 1 || loads != 1 {
+		t.Errorf("got %d keys after %d loads: %v", len(keys), loads, err)
+	}
+	keys, err = jwks.Keys(t.Context(), "b")
+	if err != nil || !slices.ContainsFunc(keys, func(k jose.JSONWebKey) bool { return k.KeyID == "b" }) || loads != 2 {
+		t.Errorf("got %d keys after %d loads: %v", len(keys), loads, err)
+	}
+}
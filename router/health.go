@@ -0,0 +1,124 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheckDefaultTimeout is the timeout of a [HealthCheck] without one.
+const HealthCheckDefaultTimeout = time.Second
+
+// HealthCheck is a named check contributing to the readiness of a service.
+type HealthCheck struct {
+	Name     string
+	Check    func(context.Context) error
+	Timeout  time.Duration // defaults to [HealthCheckDefaultTimeout]
+	Critical bool          // if set, failing the check fails the readiness
+}
+
+// Health is a registry of [HealthCheck] serving the readiness of a service.
+//
+// The zero value is ready to use and reports ready until a critical check fails or [Health.Shutdown] is called.
+type Health struct {
+	mu       sync.RWMutex
+	checks   []HealthCheck
+	shutdown atomic.Bool
+}
+
+// HealthReport is the readiness reported by [Health.ServeHTTP].
+type HealthReport struct {
+	Status string                        `json:"status"` // "pass", "fail" or "shutdown"
+	Checks map[string]*HealthCheckReport `json:"checks"`
+}
+
+// HealthCheckReport is the outcome of a [HealthCheck].
+type HealthCheckReport struct {
+	Status   string  `json:"status"` // "pass" or "fail"
+	Critical bool    `json:"critical"`
+	Latency  float64 `json:"latency_seconds"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Register adds checks to the registry.
+func (h *Health) Register(checks ...HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, checks...)
+}
+
+// Shutdown fails the readiness for good, so that traffic is drained before the server stops.
+func (h *Health) Shutdown() { h.shutdown.Store(true) }
+
+// Report runs every check concurrently and reports their outcome.
+func (h *Health) Report(ctx context.Context) *HealthReport {
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := &HealthReport{Status: "pass", Checks: make(map[string]*HealthCheckReport, len(checks))}
+	reports := make([]HealthCheckReport, len(checks))
+
+	var wg sync.WaitGroup
+	for n, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[n] = check.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for n, check := range checks {
+		report.Checks[check.Name] = &reports[n]
+		if reports[n].Status != "pass" && check.Critical {
+			report.Status = "fail"
+		}
+	}
+	if h.shutdown.Load() {
+		report.Status = "shutdown"
+	}
+	return report
+}
+
+// ServeHTTP serves the [HealthReport] with [http.StatusServiceUnavailable] if not ready.
+func (h *Health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := h.Report(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != "pass" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report) //nolint: errcheck,errchkjson // nothing to do
+}
+
+func (check HealthCheck) run(ctx context.Context) HealthCheckReport {
+	timeout := check.Timeout
+	if timeout == 0 {
+		timeout = HealthCheckDefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// checks ignoring their context still report on time
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.Check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	report := HealthCheckReport{Status: "pass", Critical: check.Critical, Latency: time.Since(start).Seconds()}
+	if err != nil {
+		report.Status = "fail"
+		report.Error = err.Error()
+	}
+	return report
+}
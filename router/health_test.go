@@ -0,0 +1,86 @@
+package router_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rlibaert/service-example-go/router"
+)
+
+func TestHealth(t *testing.T) {
+	pass := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("unavailable") }
+	hang := func(context.Context) error { time.Sleep(time.Second); return nil } // ignores its context
+
+	serve := func(t *testing.T, h *router.Health) (int, *router.HealthReport) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		var report router.HealthReport
+		err := json.Unmarshal(w.Body.Bytes(), &report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, &report
+	}
+
+	for _, tt := range []struct {
+		name   string
+		checks []router.HealthCheck
+		code   int
+		status string
+	}{
+		{"empty", nil, http.StatusOK, "pass"},
+		{"pass", []router.HealthCheck{{Name: "a", Check: pass, Critical: true}}, http.StatusOK, "pass"},
+		{"critical", []router.HealthCheck{
+			{Name: "a", Check: pass},
+			{Name: "b", Check: fail, Critical: true},
+		}, http.StatusServiceUnavailable, "fail"},
+		{"non-critical", []router.HealthCheck{
+			{Name: "a", Check: pass, Critical: true},
+			{Name: "b", Check: fail},
+		}, http.StatusOK, "pass"},
+		{"timeout", []router.HealthCheck{
+			{Name: "a", Check: hang, Timeout: time.Millisecond, Critical: true},
+		}, http.StatusServiceUnavailable, "fail"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var h router.Health
+			h.Register(tt.checks...)
+
+			code, report := serve(t, &h)
+			if code != tt.code || report.Status != tt.status {
+				t.Errorf("got %d %q, want %d %q", code, report.Status, tt.code, tt.status)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Errorf("got %d checks, want %d", len(report.Checks), len(tt.checks))
+			}
+			for _, check := range tt.checks {
+				r := report.Checks[check.Name]
+				if r == nil {
+					t.Fatalf("missing check %q", check.Name)
+				}
+				if (r.Status == "pass") != (r.Error == "") || r.Critical != check.Critical || r.Latency < 0 {
+					t.Errorf("check %q: got %+v", check.Name, r)
+				}
+			}
+		})
+	}
+
+	t.Run("shutdown", func(t *testing.T) {
+		var h router.Health
+		h.Register(router.HealthCheck{Name: "a", Check: pass, Critical: true})
+		h.Shutdown()
+
+		code, report := serve(t, &h)
+		if code != http.StatusServiceUnavailable || report.Status != "shutdown" {
+			t.Errorf("got %d %q, want %d %q", code, report.Status, http.StatusServiceUnavailable, "shutdown")
+		}
+	})
+}
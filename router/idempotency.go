@@ -0,0 +1,400 @@
+package router
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// IdempotencyKeyMaxLength is the maximum length of an Idempotency-Key header.
+const IdempotencyKeyMaxLength = 255
+
+// idempotencyMaxBodyBytes is the size of the requests buffered to be fingerprinted,
+// for operations not limiting it.
+const idempotencyMaxBodyBytes = 1 << 20
+
+// ErrIdempotencyFull is the error of an [IdempotencyStore] with no space left to reserve a key.
+var ErrIdempotencyFull = errors.New("idempotency store full")
+
+// IdempotencyStore keeps the responses to requests by idempotency key.
+//
+// Responses are opaque to stores, so that they may be implemented next to domain storages.
+type IdempotencyStore interface {
+	// IdempotencyReserve reserves a key for a request fingerprint until a TTL elapses and returns true if it was free.
+	// Otherwise, it returns the fingerprint and response recorded for the key, nil while its request is in progress.
+	// It fails with [ErrIdempotencyFull] if there is no space left for the key.
+	IdempotencyReserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (string, []byte, bool, error)
+	// IdempotencySave records the response to the request a key is reserved for.
+	IdempotencySave(ctx context.Context, key string, response []byte) error
+	// IdempotencyRelease frees a key, e.g. for a failed request to be retried.
+	IdempotencyRelease(ctx context.Context, key string) error
+}
+
+// DefaultIdempotencyMemorySize is the default [IdempotencyMemory.Size].
+const DefaultIdempotencyMemorySize = 64 << 20
+
+// IdempotencyMemory is an in-memory [IdempotencyStore].
+//
+// The zero value is ready to use. Expired keys are purged as new ones are reserved, and the saved keys closest to
+// their expiry are evicted early for the others to fit in the size of the store. Keys of requests in progress are
+// never evicted early, for their requests not to run twice: reservations fail when they fill the store.
+type IdempotencyMemory struct {
+	Size int // in bytes of keys, fingerprints and responses, DefaultIdempotencyMemorySize if not positive
+
+	mu      sync.Mutex
+	keys    map[string]*idempotencyEntry
+	pending idempotencyHeap // entries of requests in progress
+	saved   idempotencyHeap // entries with a response
+	size    int
+}
+
+type idempotencyEntry struct {
+	key         string
+	fingerprint string
+	response    []byte
+	expires     time.Time
+	index       int // in the heap
+}
+
+func (e *idempotencyEntry) size() int { return len(e.key) + len(e.fingerprint) + len(e.response) }
+
+// idempotencyHeap is a [heap.Interface] of entries ordered by expiry.
+type idempotencyHeap []*idempotencyEntry
+
+func (h idempotencyHeap) Len() int           { return len(h) }
+func (h idempotencyHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+
+func (h idempotencyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *idempotencyHeap) Push(x any) {
+	e := x.(*idempotencyEntry) //nolint: errcheck // always true
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *idempotencyHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+var _ IdempotencyStore = (*IdempotencyMemory)(nil)
+
+func (m *IdempotencyMemory) IdempotencyReserve(
+	_ context.Context,
+	key, fingerprint string,
+	ttl time.Duration,
+) (string, []byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.keys == nil {
+		m.keys = map[string]*idempotencyEntry{}
+	}
+	m.evict(now, 0)
+
+	if e, ok := m.keys[key]; ok {
+		return e.fingerprint, e.response, false, nil
+	}
+	e := &idempotencyEntry{key: key, fingerprint: fingerprint, expires: now.Add(ttl)}
+	if !m.evict(now, e.size()) {
+		return "", nil, false, ErrIdempotencyFull
+	}
+	m.keys[key] = e
+	heap.Push(&m.pending, e)
+	m.size += e.size()
+	return "", nil, true, nil
+}
+
+func (m *IdempotencyMemory) IdempotencySave(_ context.Context, key string, response []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.keys[key]; ok {
+		m.remove(e)
+		e.response = response
+		m.keys[key] = e
+		heap.Push(m.entries(e), e)
+		m.size += e.size()
+		m.evict(time.Now(), 0)
+	}
+	return nil
+}
+
+func (m *IdempotencyMemory) IdempotencyRelease(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.keys[key]; ok {
+		m.remove(e)
+	}
+	return nil
+}
+
+// evict removes the expired entries, and the saved ones closest to their expiry until an entry of some size fits,
+// and reports whether it does.
+func (m *IdempotencyMemory) evict(now time.Time, size int) bool {
+	limit := m.Size
+	if limit <= 0 {
+		limit = DefaultIdempotencyMemorySize
+	}
+	for _, h := range []*idempotencyHeap{&m.pending, &m.saved} {
+		for len(*h) > 0 && !now.Before((*h)[0].expires) {
+			m.remove((*h)[0])
+		}
+	}
+	for len(m.saved) > 0 && m.size+size > limit {
+		m.remove(m.saved[0])
+	}
+	return m.size+size <= limit
+}
+
+// entries returns the heap of an entry, depending on whether its request is in progress.
+func (m *IdempotencyMemory) entries(e *idempotencyEntry) *idempotencyHeap {
+	if e.response == nil {
+		return &m.pending
+	}
+	return &m.saved
+}
+
+func (m *IdempotencyMemory) remove(e *idempotencyEntry) {
+	heap.Remove(m.entries(e), e.index)
+	delete(m.keys, e.key)
+	m.size -= e.size()
+}
+
+// idempotentResponse is a response recorded by an [IdempotencyStore].
+type idempotentResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// humaContext is [huma.Context] embedded without its name clashing with its Context method.
+type humaContext huma.Context
+
+// idempotentContext records the response written to a [huma.Context], and replays its request body.
+type idempotentContext struct {
+	humaContext
+	body     io.Reader
+	response idempotentResponse
+}
+
+func (ctx *idempotentContext) BodyReader() io.Reader { return ctx.body }
+
+func (ctx *idempotentContext) SetStatus(code int) {
+	ctx.response.Status = code
+	ctx.humaContext.SetStatus(code)
+}
+
+func (ctx *idempotentContext) SetHeader(name, value string) {
+	ctx.response.Header.Set(name, value)
+	ctx.humaContext.SetHeader(name, value)
+}
+
+func (ctx *idempotentContext) AppendHeader(name, value string) {
+	ctx.response.Header.Add(name, value)
+	ctx.humaContext.AppendHeader(name, value)
+}
+
+func (ctx *idempotentContext) BodyWriter() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		ctx.response.Body = append(ctx.response.Body, p...)
+		return ctx.humaContext.BodyWriter().Write(p)
+	})
+}
+
+// writerFunc is an [io.Writer] function.
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// OptIdempotency returns a [huma.API] option declaring an optional Idempotency-Key header on POST operations.
+//
+// The first response to a request with a key is recorded in a store for a TTL, and replayed to requests repeating
+// the key with an Idempotent-Replayed header. Reusing a key with a different request fails with
+// [http.StatusUnprocessableEntity], and while its request is in progress with [http.StatusConflict]. Requests failing
+// to reserve their key for lack of space answer [http.StatusServiceUnavailable] with a Retry-After header.
+// Keys are scoped to the [Principal] and tenant of requests, so the option is best used after [OptAuth] and
+// [TenantMiddleware]. Server errors are not recorded, for requests to be retried.
+func OptIdempotency(store IdempotencyStore, ttl time.Duration) func(huma.API) {
+	return func(api huma.API) {
+		param := &huma.Param{
+			Name:        "Idempotency-Key",
+			In:          "header",
+			Description: "unique key of the request, for retries to get its original response",
+			Schema:      &huma.Schema{Type: huma.TypeString, MaxLength: new(int)},
+		}
+		*param.Schema.MaxLength = IdempotencyKeyMaxLength
+
+		conflict := errorResponse(api, http.StatusConflict)
+		unprocessable := errorResponse(api, http.StatusUnprocessableEntity)
+		unavailable := errorResponse(api, http.StatusServiceUnavailable)
+		modifier := func(op *huma.Operation) {
+			if op.Method != http.MethodPost {
+				return
+			}
+			for _, p := range op.Parameters {
+				if p.Name == param.Name && p.In == param.In {
+					return // group modifiers may run twice
+				}
+			}
+			op.Parameters = append(op.Parameters, param)
+			if op.Responses == nil {
+				op.Responses = map[string]*huma.Response{}
+			}
+			for status, response := range map[int]*huma.Response{
+				http.StatusConflict:            conflict,
+				http.StatusUnprocessableEntity: unprocessable,
+				http.StatusServiceUnavailable:  unavailable,
+			} {
+				if _, ok := op.Responses[strconv.Itoa(status)]; !ok {
+					op.Responses[strconv.Itoa(status)] = response
+				}
+			}
+		}
+		if g, ok := api.(interface{ UseSimpleModifier(func(*huma.Operation)) }); ok {
+			g.UseSimpleModifier(modifier)
+		} else {
+			api.OpenAPI().OnAddOperation = append(api.OpenAPI().OnAddOperation,
+				func(_ *huma.OpenAPI, op *huma.Operation) { modifier(op) })
+		}
+
+		api.UseMiddleware(idempotencyMiddleware(api, store, ttl))
+	}
+}
+
+func idempotencyMiddleware(
+	api huma.API,
+	store IdempotencyStore,
+	ttl time.Duration,
+) func(huma.Context, func(huma.Context)) {
+	fail := func(ctx huma.Context, status int, msg string, errs ...error) {
+		huma.WriteErr(api, ctx, status, msg, errs...) //nolint: errcheck,gosec // nothing to do
+	}
+	return func(ctx huma.Context, next func(huma.Context)) {
+		key := ctx.Header("Idempotency-Key")
+		if key == "" || ctx.Method() != http.MethodPost {
+			next(ctx)
+			return
+		}
+		if len(key) > IdempotencyKeyMaxLength {
+			fail(ctx, http.StatusBadRequest, "Idempotency-Key too long",
+				&huma.ErrorDetail{Location: "header.Idempotency-Key", Value: key})
+			return
+		}
+
+		limit := ctx.Operation().MaxBodyBytes
+		if limit <= 0 {
+			limit = idempotencyMaxBodyBytes
+		}
+		body, err := io.ReadAll(io.LimitReader(ctx.BodyReader(), limit+1))
+		if err != nil {
+			fail(ctx, http.StatusBadRequest, "could not read request body")
+			return
+		}
+		if int64(len(body)) > limit {
+			fail(ctx, http.StatusRequestEntityTooLarge,
+				"request body too large for an Idempotency-Key, at most "+strconv.FormatInt(limit, 10)+" bytes")
+			return
+		}
+
+		r := requestFrom(ctx.Context())
+		var subject string
+		if r.principal != nil {
+			subject = r.principal.Subject
+		}
+		key = idempotencyScope(r.tenant, subject, key)
+		u := ctx.URL()
+		hash := sha256.New()
+		hash.Write([]byte(joinSpace(ctx.Method(), u.RequestURI(), ctx.Header("Content-Type")) + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		recorded, response, ok, err := store.IdempotencyReserve(ctx.Context(), key, fingerprint, ttl)
+		switch {
+		case errors.Is(err, ErrIdempotencyFull):
+			ctx.SetHeader("Retry-After", "1")
+			fail(ctx, http.StatusServiceUnavailable, "too many requests with an Idempotency-Key, retry later")
+			return
+		case err != nil:
+			fail(ctx, http.StatusInternalServerError, "could not check Idempotency-Key")
+			return
+		case !ok && recorded != fingerprint:
+			fail(ctx, http.StatusUnprocessableEntity, "Idempotency-Key already used for a different request")
+			return
+		case !ok && response == nil:
+			fail(ctx, http.StatusConflict, "request with the same Idempotency-Key in progress")
+			return
+		case !ok:
+			replay(ctx, response)
+			return
+		}
+
+		ictx := &idempotentContext{
+			humaContext: ctx,
+			body:        bytes.NewReader(body),
+			response:    idempotentResponse{Header: http.Header{}},
+		}
+		saved := false
+		defer func() {
+			if !saved {
+				// the key expires anyway if it cannot be released
+				_ = store.IdempotencyRelease(context.WithoutCancel(ctx.Context()), key)
+			}
+		}()
+		next(ictx)
+
+		if ictx.response.Status == 0 {
+			ictx.response.Status = ctx.Status()
+		}
+		if ictx.response.Status >= http.StatusInternalServerError {
+			return
+		}
+		b, _ := json.Marshal(&ictx.response) //nolint: errchkjson // plain struct always marshals
+		saved = store.IdempotencySave(context.WithoutCancel(ctx.Context()), key, b) == nil
+	}
+}
+
+// idempotencyScope returns the key an [IdempotencyStore] records a request with, hashing its tenant,
+// principal subject and Idempotency-Key each prefixed with its length, for no two scopes to be confused.
+func idempotencyScope(parts ...string) string {
+	hash := sha256.New()
+	for _, p := range parts {
+		hash.Write([]byte(strconv.Itoa(len(p)) + ":" + p))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay writes a recorded response.
+func replay(ctx huma.Context, response []byte) {
+	var r idempotentResponse
+	err := json.Unmarshal(response, &r)
+	if err != nil {
+		ctx.SetStatus(http.StatusInternalServerError)
+		return
+	}
+	for name, values := range r.Header {
+		for _, v := range values {
+			ctx.AppendHeader(name, v)
+		}
+	}
+	ctx.SetHeader("Idempotent-Replayed", "true")
+	ctx.SetStatus(r.Status)
+	ctx.BodyWriter().Write(r.Body) //nolint: errcheck,gosec // client gone
+}
@@ -0,0 +1,212 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/rlibaert/service-example-go/router"
+)
+
+func TestIdempotency(t *testing.T) {
+	_, api := humatest.New(t)
+	router.OptIdempotency(&router.IdempotencyMemory{}, time.Hour)(api)
+
+	var calls int
+	type input struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}
+	type output struct {
+		Location string `header:"Location"`
+		Body     struct {
+			Call int `json:"call"`
+		}
+	}
+	huma.Post(api, "/things", func(_ context.Context, i *input) (*output, error) {
+		calls++
+		if i.Body.Name == "fail" {
+			return nil, huma.Error503ServiceUnavailable("try again")
+		}
+		o := &output{Location: "/things/" + i.Body.Name}
+		o.Body.Call = calls
+		return o, nil
+	})
+
+	for _, tc := range []struct {
+		name     string
+		key      string
+		body     string
+		status   int
+		call     string
+		replayed bool
+	}{
+		{"no key", "", "thing", http.StatusOK, `{"call":1}`, false},
+		{"first", "k1", "thing", http.StatusOK, `{"call":2}`, false},
+		{"replay", "k1", "thing", http.StatusOK, `{"call":2}`, true},
+		{"reuse", "k1", "other", http.StatusUnprocessableEntity, "", false},
+		{"other key", "k2", "thing", http.StatusOK, `{"call":3}`, false},
+		{"server error", "k3", "fail", http.StatusServiceUnavailable, "", false},
+		{"retry", "k3", "fail", http.StatusServiceUnavailable, "", false},
+		{"too long", strings.Repeat("k", router.IdempotencyKeyMaxLength+1), "thing", http.StatusBadRequest, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args := []any{map[string]any{"name": tc.body}}
+			if tc.key != "" {
+				args = append([]any{"Idempotency-Key: " + tc.key}, args...)
+			}
+			resp := api.Post("/things", args...)
+			if resp.Code != tc.status {
+				t.Fatal("got", resp.Code, resp.Body.String(), "want", tc.status)
+			}
+			if tc.call != "" && !strings.Contains(resp.Body.String(), tc.call) {
+				t.Error("got body", resp.Body.String(), "want", tc.call)
+			}
+			if got := resp.Header().Get("Idempotent-Replayed") == "true"; got != tc.replayed {
+				t.Error("got replayed", got, "want", tc.replayed)
+			}
+			if tc.status == http.StatusOK && resp.Header().Get("Location") != "/things/"+tc.body {
+				t.Error("got location", resp.Header().Get("Location"))
+			}
+		})
+	}
+	if calls != 5 {
+		t.Error("handler called", calls, "times, want 5")
+	}
+
+	var found bool
+	for _, p := range api.OpenAPI().Paths["/things"].Post.Parameters {
+		found = found || p.Name == "Idempotency-Key"
+	}
+	if !found {
+		t.Error("Idempotency-Key header not declared")
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	_, api := humatest.New(t)
+	api.UseMiddleware(router.TenantMiddleware(api, router.TenantFromHeader("X-Tenant-Id")))
+	router.OptIdempotency(&router.IdempotencyMemory{}, time.Hour)(api)
+
+	var calls int
+	huma.Post(api, "/things", func(context.Context, *struct{}) (*struct{}, error) {
+		calls++
+		return nil, nil
+	})
+
+	// the tenant and key of both requests join into the same string
+	for _, headers := range [][]any{
+		{`X-Tenant-Id: acme"`, "Idempotency-Key: k"},
+		{"X-Tenant-Id: acme", `Idempotency-Key: "k`},
+	} {
+		resp := api.Post("/things", append(headers, strings.NewReader(""))...)
+		if resp.Code != http.StatusNoContent || resp.Header().Get("Idempotent-Replayed") != "" {
+			t.Error("POST with", headers, "got", resp.Code, resp.Header())
+		}
+	}
+	if calls != 2 {
+		t.Error("handler called", calls, "times, want 2")
+	}
+}
+
+func TestIdempotencyFull(t *testing.T) {
+	_, api := humatest.New(t)
+	// fits the scoped key and fingerprint of one request, both hex-encoded SHA-256
+	router.OptIdempotency(&router.IdempotencyMemory{Size: 128}, time.Hour)(api)
+
+	var calls atomic.Int32
+	started, done := make(chan struct{}), make(chan struct{})
+	huma.Post(api, "/things", func(context.Context, *struct{}) (*struct{}, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-done
+		}
+		return nil, nil
+	})
+	post := func(key string) *httptest.ResponseRecorder {
+		return api.Post("/things", "Idempotency-Key: "+key, strings.NewReader(""))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		post("slow")
+	}()
+	<-started
+
+	if resp := post("other"); resp.Code != http.StatusServiceUnavailable || resp.Header().Get("Retry-After") == "" {
+		t.Error("POST with a store full got", resp.Code, resp.Header())
+	}
+	if resp := post("slow"); resp.Code != http.StatusConflict {
+		t.Error("POST in progress got", resp.Code, "want", http.StatusConflict)
+	}
+	close(done)
+	wg.Wait()
+
+	if resp := post("other"); resp.Code != http.StatusNoContent {
+		t.Error("POST once the store has space got", resp.Code, "want", http.StatusNoContent)
+	}
+	if calls.Load() != 2 {
+		t.Error("handler called", calls.Load(), "times, want 2")
+	}
+}
+
+func TestIdempotencyMemory(t *testing.T) {
+	// fits two keys of 2 bytes and fingerprints of 1 byte, and a response of 2 bytes
+	m := &router.IdempotencyMemory{Size: 8}
+	reserve := func(key string, ttl time.Duration) bool {
+		t.Helper()
+		_, _, ok, err := m.IdempotencyReserve(t.Context(), key, "f", ttl)
+		if err != nil {
+			t.Fatal("IdempotencyReserve:", err)
+		}
+		return ok
+	}
+	save := func(key, response string) {
+		t.Helper()
+		if err := m.IdempotencySave(t.Context(), key, []byte(response)); err != nil {
+			t.Fatal("IdempotencySave:", err)
+		}
+	}
+
+	if !reserve("k1", time.Nanosecond) || !reserve("k2", time.Hour) {
+		t.Fatal("IdempotencyReserve of new keys failed")
+	}
+	time.Sleep(time.Millisecond)
+	if !reserve("k1", time.Hour) {
+		t.Error("IdempotencyReserve of an expired key failed")
+	}
+	full := func(key string) {
+		t.Helper()
+		_, _, _, err := m.IdempotencyReserve(t.Context(), key, "f", time.Hour)
+		if !errors.Is(err, router.ErrIdempotencyFull) {
+			t.Error("IdempotencyReserve of", key, "got", err, "want", router.ErrIdempotencyFull)
+		}
+	}
+	full("k3") // past the size of keys in progress
+
+	save("k1", "r")
+	if !reserve("k3", 2*time.Hour) {
+		t.Error("IdempotencyReserve of a key past the size failed")
+	}
+	if reserve("k2", time.Hour) {
+		t.Error("IdempotencyReserve evicted a key in progress")
+	}
+	full("k1") // evicted but still past the size
+
+	save("k2", "too long a response")
+	if !reserve("k2", time.Hour) {
+		t.Error("IdempotencyReserve kept a response past the size")
+	}
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// JWKSDefaultRefreshInterval is the refresh interval of a [JWKS] without one.
+const JWKSDefaultRefreshInterval = time.Minute
+
+// jwtAlgorithms are the signature algorithms accepted by [JWTValidator], each restricted to its key type.
+var jwtAlgorithms = []jose.SignatureAlgorithm{ //nolint: gochecknoglobals // read-only table
+	jose.HS256, jose.HS384, jose.HS512,
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWKS is a JSON Web Key Set read from a file or fetched from an http(s) URL.
+//
+// It is loaded on first use and reloaded, at most every RefreshInterval, when a token refers to an unknown key.
+type JWKS struct {
+	Location        string        // file path or URL
+	Client          *http.Client  // defaults to [http.DefaultClient]
+	RefreshInterval time.Duration // defaults to [JWKSDefaultRefreshInterval]
+
+	mu     sync.Mutex
+	set    *jose.JSONWebKeySet
+	loaded time.Time
+}
+
+// Keys returns the keys of an ID, every key if empty.
+func (s *JWKS) Keys(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := s.RefreshInterval
+	if interval == 0 {
+		interval = JWKSDefaultRefreshInterval
+	}
+
+	keys := s.lookup(kid)
+	if len(keys) == 0 && (s.set == nil || time.Since(s.loaded) >= interval) {
+		set, err := s.load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not load JWKS: %w", err)
+		}
+		s.set, s.loaded = set, time.Now()
+		keys = s.lookup(kid)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return keys, nil
+}
+
+func (s *JWKS) lookup(kid string) []jose.JSONWebKey {
+	switch {
+	case s.set == nil:
+		return nil
+	case kid == "":
+		return s.set.Keys
+	default:
+		return s.set.Key(kid)
+	}
+}
+
+func (s *JWKS) load(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	var r io.Reader
+	if strings.HasPrefix(s.Location, "http://") || strings.HasPrefix(s.Location, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Location, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := s.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(s.Location)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var set jose.JSONWebKeySet
+	err := json.NewDecoder(r).Decode(&set)
+	if err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// JWTValidator validates signed JWT, e.g. as the authentication function of a [BearerAuthenticator].
+//
+// Tokens must be signed by a key of the set and not be expired.
+// The principal's roles are both its "roles" and "scope" claims, its tenant is its "tenant" claim.
+type JWTValidator struct {
+	Keys     *JWKS
+	Issuer   string // expected "iss" claim, unless empty
+	Audience string // expected in the "aud" claim, unless empty
+	Leeway   time.Duration
+	Now      func() time.Time // defaults to [time.Now]
+}
+
+func (v *JWTValidator) now() time.Time {
+	if v.Now == nil {
+		return time.Now()
+	}
+	return v.Now()
+}
+
+// Authenticate validates a token and returns its [Principal].
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	tok, err := jwt.ParseSigned(token, jwtAlgorithms)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := v.Keys.Keys(ctx, tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		claims jwt.Claims
+		extra  struct {
+			Roles  []string `json:"roles"`
+			Scope  string   `json:"scope"`
+			Tenant string   `json:"tenant"`
+		}
+	)
+	for _, key := range keys {
+		err = tok.Claims(key, &claims, &extra)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Expiry == nil {
+		return nil, errors.New("missing expiration time")
+	}
+	expected := jwt.Expected{Issuer: v.Issuer, Time: v.now()}
+	if v.Audience != "" {
+		expected.AnyAudience = jwt.Audience{v.Audience}
+	}
+	err = claims.ValidateWithLeeway(expected, v.Leeway)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Roles:   append(extra.Roles, strings.Fields(extra.Scope)...),
+		Tenant:  extra.Tenant,
+	}, nil
+}
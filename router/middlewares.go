@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,16 +15,24 @@ import (
 )
 
 // RequestsLogMiddleware creates a [slog.Record] for done requests and calls a handling function.
+//
+// The record includes the subject of the [Principal] authenticated by [OptAuth], if any.
 func RequestsLogMiddleware(handle func(context.Context, slog.Record)) func(huma.Context, func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		start := time.Now()
+		ctx, r := withRequest(ctx)
 		defer func() {
+			var principal string
+			if r.principal != nil {
+				principal = r.principal.Subject
+			}
 			msg := joinSpace(ctx.Method(), ctx.URL().Path, ctx.Version().Proto)
 			rec := slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
 			rec.AddAttrs(
 				slog.String("from", ctx.RemoteAddr()),
 				slog.String("ref", ctx.Header("Referer")),
 				slog.String("ua", ctx.Header("User-Agent")),
+				slog.String("principal", principal),
 				slog.Int("status", ctx.Status()),
 				slog.Duration("dur", rec.Time.Sub(start)),
 			)
@@ -58,11 +67,14 @@ func RequestsMetricsMiddleware(set *metrics.Set) func(huma.Context, func(huma.Co
 
 // ResponsesMetricsMiddleware returns a middleware collecting request responses metrics.
 //
-//   - http_request_duration_seconds_bucket{method,path,status,le}
-//   - http_request_duration_seconds_sum{method,path,status}
-//   - http_request_duration_seconds_count{method,path,status}
-//   - http_requests_total{method,path,status}
-func ResponsesMetricsMiddleware(set *metrics.Set) func(huma.Context, func(huma.Context)) {
+//   - http_request_duration_seconds_bucket{method,path,status,tenant,le}
+//   - http_request_duration_seconds_sum{method,path,status,tenant}
+//   - http_request_duration_seconds_count{method,path,status,tenant}
+//   - http_requests_total{method,path,status,tenant}
+//
+// The tenant is the one identified by [TenantMiddleware], empty if none. It is labeled as is if that of
+// the [Principal] or one of those given, and "other" otherwise, for requests not to create series at will.
+func ResponsesMetricsMiddleware(set *metrics.Set, tenants ...string) func(huma.Context, func(huma.Context)) {
 	type value struct {
 		*metrics.PrometheusHistogram
 		*metrics.Counter
@@ -72,12 +84,19 @@ func ResponsesMetricsMiddleware(set *metrics.Set) func(huma.Context, func(huma.C
 	var m sync.Map
 	return func(ctx huma.Context, next func(huma.Context)) {
 		start := time.Now()
+		ctx, r := withRequest(ctx)
 		defer func() {
+			tenant := r.tenant
+			principal := r.principal != nil && r.principal.Tenant == tenant
+			if tenant != "" && !principal && !slices.Contains(tenants, tenant) {
+				tenant = "other"
+			}
 			op := ctx.Operation()
-			k := op.OperationID + http.StatusText(ctx.Status())
+			k := joinSpace(op.OperationID, strconv.Itoa(ctx.Status()), tenant)
 			v, ok := m.Load(k)
 			if !ok {
-				labels := joinQuote("{method=", op.Method, ",path=", op.Path, ",status=", strconv.Itoa(ctx.Status()), "}") //nolint: golines
+				labels := joinQuote("{method=", op.Method, ",path=", op.Path, ",status=", strconv.Itoa(ctx.Status()),
+					",tenant=", tenant, "}")
 				v, _ = m.LoadOrStore(k, value{
 					set.GetOrCreatePrometheusHistogramExt("http_request_duration_seconds"+labels, buckets),
 					set.GetOrCreateCounter("http_requests_total" + labels),
@@ -45,7 +45,21 @@ func ExampleRequestsLogMiddleware() {
 	))
 
 	// Output:
-	// time=2025-11-26T19:27:42.000Z level=INFO msg="GET /teapot HTTP/1.1" from=192.0.2.1:1234 ref="" ua="" status=418 dur=1ms
+	// time=2025-11-26T19:27:42.000Z level=INFO msg="GET /teapot HTTP/1.1" from=192.0.2.1:1234 ref="" ua="" principal="" status=418 dur=1ms
+}
+
+func TestRequestID(t *testing.T) {
+	var got string
+	handler := huma.Middlewares{
+		router.RequestsLogMiddleware(func(context.Context, slog.Record) {}),
+	}.Handler(func(ctx huma.Context) { got = router.RequestIDFrom(ctx.Context()) })
+
+	r := httptest.NewRequest(http.MethodGet, "/teapot", nil)
+	r.Header.Set("X-Request-Id", "req-1")
+	handler(humatest.NewContext(nil, r, httptest.NewRecorder()))
+	if got != "req-1" {
+		t.Errorf("RequestIDFrom got %q, want %q", got, "req-1")
+	}
 }
 
 func BenchmarkLog(b *testing.B) {
@@ -98,7 +112,7 @@ func ExampleResponsesMetricsMiddleware() {
 	op := huma.Operation{Method: http.MethodGet, Path: "/teapot"}
 
 	for range 42 {
-		handler(humatest.NewContext(&op, nil, httptest.NewRecorder()))
+		handler(humatest.NewContext(&op, httptest.NewRequest(http.MethodGet, "/teapot", nil), httptest.NewRecorder()))
 	}
 
 	var buf bytes.Buffer
@@ -115,14 +129,14 @@ func ExampleResponsesMetricsMiddleware() {
 	fmt.Println(sum, "value skipped", ok)
 
 	// Output:
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="0.001"} 42
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="0.005"} 42
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="0.025"} 42
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="0.125"} 42
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="0.625"} 42
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="3.125"} 42
-	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",le="+Inf"} 42
-	// http_request_duration_seconds_sum{method="GET",path="/teapot",status="418"} value skipped true
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="0.001"} 42
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="0.005"} 42
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="0.025"} 42
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="0.125"} 42
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="0.625"} 42
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="3.125"} 42
+	// http_request_duration_seconds_bucket{method="GET",path="/teapot",status="418",tenant="",le="+Inf"} 42
+	// http_request_duration_seconds_sum{method="GET",path="/teapot",status="418",tenant=""} value skipped true
 }
 
 func BenchmarkMetrics(b *testing.B) {
@@ -131,7 +145,10 @@ func BenchmarkMetrics(b *testing.B) {
 		router.RequestsMetricsMiddleware(set),
 		router.ResponsesMetricsMiddleware(set),
 	}.Handler(func(huma.Context) {})
-	ctx := humatest.NewContext(&huma.Operation{Method: http.MethodGet, Path: "/teapot"}, nil, nil)
+	ctx := humatest.NewContext(&huma.Operation{Method: http.MethodGet, Path: "/teapot"},
+		httptest.NewRequest(http.MethodGet, "/teapot", nil),
+		nil,
+	)
 
 	for b.Loop() {
 		handler(ctx)
@@ -0,0 +1,45 @@
+package router
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// requestKey is the [context.Context] key of a *request.
+type requestKey struct{}
+
+// request holds the identity of a request, set by middlewares such as [OptAuth] or [TenantMiddleware]
+// so that middlewares running before them, e.g. [RequestsLogMiddleware], can get it once done.
+type request struct {
+	id        string // X-Request-Id header
+	principal *Principal
+	tenant    string
+}
+
+// withRequest returns a context holding a request, reusing any existing one.
+func withRequest(ctx huma.Context) (huma.Context, *request) {
+	if r, ok := ctx.Context().Value(requestKey{}).(*request); ok {
+		return ctx, r
+	}
+	r := &request{id: ctx.Header("X-Request-Id")}
+	return huma.WithValue(ctx, requestKey{}, r), r
+}
+
+// RequestIDFrom returns the X-Request-Id header of the request of a context, once identified by a middleware
+// such as [RequestsLogMiddleware], empty if none.
+func RequestIDFrom(ctx context.Context) string { return requestFrom(ctx).id }
+
+// requestFrom returns the request of a context, a zero one if none.
+func requestFrom(ctx context.Context) *request {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return &request{}
+	}
+	return r
+}
+
+func (r *request) clone() *request {
+	clone := *r
+	return &clone
+}
@@ -28,6 +28,15 @@ func New(
 	return mux
 }
 
+// OpenAPI returns the OpenAPI document of the router [New] returns with the same options, without serving it.
+func OpenAPI(title, version string, opts ...func(huma.API)) *huma.OpenAPI {
+	api := humago.New(http.NewServeMux(), huma.DefaultConfig(title, version))
+	for _, opt := range opts {
+		opt(api)
+	}
+	return api.OpenAPI()
+}
+
 // OptUseMiddleware returns a [huma.API] option to append new middlewares.
 func OptUseMiddleware(middlewares ...func(huma.Context, func(huma.Context))) func(huma.API) {
 	return func(api huma.API) { api.UseMiddleware(middlewares...) }
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// WithTenant returns a context carrying a tenant, e.g. for tests or background jobs.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	r := requestFrom(ctx).clone()
+	r.tenant = tenant
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// TenantFrom returns the tenant of a context, empty if none.
+func TenantFrom(ctx context.Context) string { return requestFrom(ctx).tenant }
+
+// TenantMiddleware returns a middleware identifying the tenant of requests, see [TenantFrom],
+// with the first resolver returning a non-empty one.
+//
+// Requests of a [Principal] fail with [http.StatusForbidden] if resolved another tenant than the principal's,
+// e.g. with a header while it has none, for principals not to choose their tenant.
+// Resolvers relying on the [Principal] require the middleware to run after [OptAuth].
+func TenantMiddleware(api huma.API, resolvers ...func(huma.Context) string) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		for _, resolve := range resolvers {
+			tenant := resolve(ctx)
+			if tenant == "" {
+				continue
+			}
+			if p := PrincipalFrom(ctx.Context()); p != nil && p.Tenant != tenant {
+				huma.WriteErr(api, ctx, http.StatusForbidden, //nolint: errcheck,gosec // nothing to do
+					"tenant "+strconv.Quote(tenant)+" not granted to the principal")
+				return
+			}
+			ctx, r := withRequest(ctx)
+			r.tenant = tenant
+			next(ctx)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// TenantFromPrincipal is a [TenantMiddleware] resolver returning the tenant of the [Principal].
+func TenantFromPrincipal(ctx huma.Context) string {
+	p := PrincipalFrom(ctx.Context())
+	if p == nil {
+		return ""
+	}
+	return p.Tenant
+}
+
+// TenantFromHeader returns a [TenantMiddleware] resolver reading the tenant from a request header.
+func TenantFromHeader(name string) func(huma.Context) string {
+	return func(ctx huma.Context) string { return strings.TrimSpace(ctx.Header(name)) }
+}
+
+// TenantFromSubdomain returns a [TenantMiddleware] resolver reading the tenant from the subdomain of
+// a domain in the request host, e.g. "acme" in "acme.contacts.example.com" for "contacts.example.com".
+func TenantFromSubdomain(domain string) func(huma.Context) string {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return func(ctx huma.Context) string {
+		host, _, err := net.SplitHostPort(ctx.Host())
+		if err != nil {
+			host = ctx.Host()
+		}
+		subdomain, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || strings.Contains(subdomain, ".") {
+			return ""
+		}
+		return subdomain
+	}
+}
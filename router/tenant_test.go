@@ -0,0 +1,107 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/rlibaert/service-example-go/router"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	set := metrics.NewSet()
+	_, api := humatest.New(t)
+	api.UseMiddleware(router.ResponsesMetricsMiddleware(set, "acme"))
+	router.OptAuth(map[string]router.Authenticator{
+		"apikey": router.APIKeyAuthenticator("X-API-Key", map[string]*router.Principal{
+			"anonymous": {},
+			"initech":   {Tenant: "initech"},
+		}),
+	})(api)
+	api.UseMiddleware(
+		router.TenantMiddleware(api,
+			router.TenantFromPrincipal,
+			router.TenantFromHeader("X-Tenant-Id"),
+			router.TenantFromSubdomain("contacts.example.com"),
+		),
+	)
+
+	var tenant string
+	handler := func(ctx context.Context, _ *struct{}) (*struct{}, error) {
+		tenant = router.TenantFrom(ctx)
+		return nil, nil
+	}
+	huma.Get(api, "/tenant", handler)
+	huma.Get(api, "/public", handler, func(op *huma.Operation) { op.Security = []map[string][]string{} })
+
+	for _, tt := range []struct {
+		name    string
+		host    string
+		headers []any
+		status  int
+		want    string
+	}{
+		{"none", "example.com", nil, http.StatusNoContent, ""},
+		{"header", "example.com", []any{"X-Tenant-Id: acme"}, http.StatusNoContent, "acme"},
+		{"subdomain", "Globex.Contacts.Example.com:8888", nil, http.StatusNoContent, "globex"},
+		{"nested subdomain", "a.globex.contacts.example.com", nil, http.StatusNoContent, ""},
+		{"domain", "contacts.example.com", nil, http.StatusNoContent, ""},
+		{"header over subdomain", "globex.contacts.example.com", []any{"X-Tenant-Id: acme"}, http.StatusNoContent,
+			"acme"},
+		{"principal", "example.com", []any{"X-API-Key: initech"}, http.StatusNoContent, "initech"},
+		{"principal choosing", "example.com", []any{"X-API-Key: initech", "X-Tenant-Id: acme"}, http.StatusNoContent,
+			"initech"},
+		{"principal without", "example.com", []any{"X-API-Key: anonymous"}, http.StatusNoContent, ""},
+		{"principal without choosing", "example.com", []any{"X-API-Key: anonymous", "X-Tenant-Id: acme"},
+			http.StatusForbidden, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant = ""
+			path := "/public"
+			if strings.Contains(fmt.Sprint(tt.headers...), "X-API-Key") {
+				path = "/tenant"
+			}
+			resp := api.Do(http.MethodGet, path, append([]any{"Host: " + tt.host}, tt.headers...)...)
+			if resp.Code != tt.status {
+				t.Fatal("GET got", resp.Code, "want", tt.status)
+			}
+			if tenant != tt.want {
+				t.Errorf("got tenant %q, want %q", tenant, tt.want)
+			}
+		})
+	}
+
+	t.Run("metrics", func(t *testing.T) {
+		var buf bytes.Buffer
+		set.WritePrometheus(&buf)
+		// globex is neither allowed nor that of a principal
+		for _, tenant := range []string{"", "acme", "other", "initech"} {
+			label := `http_requests_total{method="GET",path="/public",status="204",tenant="` + tenant + `"}`
+			if tenant == "initech" {
+				label = `http_requests_total{method="GET",path="/tenant",status="204",tenant="initech"}`
+			}
+			if !strings.Contains(buf.String(), label) {
+				t.Errorf("missing %s in\n%s", label, buf.String())
+			}
+		}
+		if strings.Contains(buf.String(), "globex") {
+			t.Errorf("got globex in\n%s", buf.String())
+		}
+	})
+}
+
+func TestTenantFromSubdomain(t *testing.T) {
+	resolve := router.TenantFromSubdomain(".contacts.example.com.")
+	ctx := humatest.NewContext(nil, httptest.NewRequest(http.MethodGet, "http://acme.contacts.example.com/", nil), nil)
+	if got := resolve(ctx); got != "acme" {
+		t.Errorf("got %q, want %q", got, "acme")
+	}
+}
@@ -0,0 +1,54 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware returns a middleware starting a server span per operation, named by its ID,
+// as a child of the trace propagated by the request headers, e.g. W3C traceparent.
+func TracingMiddleware(
+	tracer trace.Tracer,
+	propagator propagation.TextMapPropagator,
+) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		parent := propagator.Extract(ctx.Context(), headerCarrier{ctx})
+		spanctx, span := tracer.Start(parent, op.OperationID,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(op.Method),
+				semconv.HTTPRoute(op.Path),
+				semconv.URLPath(ctx.URL().Path),
+			),
+		)
+		defer func() {
+			status := ctx.Status()
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+		}()
+
+		next(huma.WithContext(ctx, spanctx))
+	}
+}
+
+// headerCarrier is a read-only [propagation.TextMapCarrier] of the request headers.
+type headerCarrier struct{ ctx huma.Context }
+
+func (c headerCarrier) Get(key string) string { return c.ctx.Header(key) }
+
+func (c headerCarrier) Set(string, string) {}
+
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.ctx.EachHeader(func(name, _ string) { keys = append(keys, name) })
+	return keys
+}
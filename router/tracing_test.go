@@ -0,0 +1,70 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rlibaert/service-example-go/router"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(router.TracingMiddleware(tracer, propagation.TraceContext{}))
+
+	var handled trace.SpanContext
+	huma.Get(api, "/teapots/{id}", func(ctx context.Context, _ *struct {
+		ID string `path:"id"`
+	}) (*struct{}, error) {
+		handled = trace.SpanContextFromContext(ctx)
+		return nil, huma.Error500InternalServerError("no tea")
+	}, func(op *huma.Operation) { op.OperationID = "get-teapot" })
+
+	api.Get("/teapots/42", "traceparent: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name() != "get-teapot" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("got span %q of kind %v", span.Name(), span.SpanKind())
+	}
+	if got := span.Parent().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("got parent trace %s", got)
+	}
+	if handled.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("handler got span %s, want %s", handled.SpanID(), span.SpanContext().SpanID())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("got status %v, want %v", span.Status().Code, codes.Error)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, a := range span.Attributes() {
+		attrs[a.Key] = a.Value
+	}
+	for k, want := range map[attribute.Key]string{
+		"http.request.method":       http.MethodGet,
+		"http.route":                "/teapots/{id}",
+		"url.path":                  "/teapots/42",
+		"http.response.status_code": "500",
+	} {
+		if got := attrs[k].Emit(); got != want {
+			t.Errorf("got %s=%q, want %q", k, got, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// mockEvent is an [domain.AuditEvent] kept by a [Mock] with its tenant.
+type mockEvent struct {
+	tenant domain.TenantID
+	event  domain.AuditEvent
+}
+
+var _ domain.AuditStore = (*Mock)(nil)
+
+func (s *Mock) AuditAppend(ctx context.Context, e *domain.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).AuditAppend(ctx, e)
+}
+
+func (s *Mock) AuditList(ctx context.Context, q *domain.AuditQuery) (*domain.AuditPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).AuditList(ctx, q)
+}
+
+func (s *mockTx) AuditAppend(ctx context.Context, e *domain.AuditEvent) error {
+	e.ID = int64(len(s.events)) + 1
+	clone := *e
+	clone.Changes = slices.Clone(e.Changes)
+	s.events = append(s.events, mockEvent{domain.TenantFrom(ctx), clone})
+	return nil
+}
+
+func (s *mockTx) AuditList(ctx context.Context, q *domain.AuditQuery) (*domain.AuditPage, error) {
+	before, err := domain.DecodeAuditCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := domain.TenantFrom(ctx)
+	page := &domain.AuditPage{}
+	for _, e := range slices.Backward(s.events) {
+		if e.tenant != tenant || e.event.ContactID != q.ContactID || before != 0 && e.event.ID >= before {
+			continue
+		}
+		if q.Limit > 0 && len(page.Events) == q.Limit {
+			page.Next = domain.AuditCursor(page.Events[q.Limit-1].ID)
+			break
+		}
+		clone := e.event
+		clone.Changes = slices.Clone(e.event.Changes)
+		page.Events = append(page.Events, &clone)
+	}
+	return page, nil
+}
+
+var _ domain.AuditStore = (*SQLite)(nil)
+
+func (s *SQLite) AuditAppend(ctx context.Context, e *domain.AuditEvent) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.conn().ExecContext(ctx,
+		`INSERT INTO audit_events (tenant, contact_id, action, actor, request_id, time, changes)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		domain.TenantFrom(ctx), e.ContactID, e.Action, e.Actor, e.RequestID, e.Time.UnixNano(), string(changes),
+	)
+	if err != nil {
+		return err
+	}
+	e.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLite) AuditList(ctx context.Context, q *domain.AuditQuery) (*domain.AuditPage, error) {
+	before, err := domain.DecodeAuditCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if before == 0 {
+		before = math.MaxInt64
+	}
+	limit := -1
+	if q.Limit > 0 {
+		limit = q.Limit + 1
+	}
+
+	rows, err := s.conn().QueryContext(ctx,
+		`SELECT id, contact_id, action, actor, request_id, time, changes FROM audit_events
+		WHERE tenant = ? AND contact_id = ? AND id < ?
+		ORDER BY id DESC LIMIT ?`,
+		domain.TenantFrom(ctx), q.ContactID, before, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.AuditPage{}
+	for rows.Next() {
+		var (
+			e       domain.AuditEvent
+			t       int64
+			changes string
+		)
+		err := rows.Scan(&e.ID, &e.ContactID, &e.Action, &e.Actor, &e.RequestID, &t, &changes)
+		if err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(0, t)
+		err = json.Unmarshal([]byte(changes), &e.Changes)
+		if err != nil {
+			return nil, err
+		}
+		page.Events = append(page.Events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if q.Limit > 0 && len(page.Events) > q.Limit {
+		page.Events = page.Events[:q.Limit]
+		page.Next = domain.AuditCursor(page.Events[q.Limit-1].ID)
+	}
+	return page, nil
+}
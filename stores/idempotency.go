@@ -0,0 +1,57 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// IdempotencyReserve implements router.IdempotencyStore, purging expired keys.
+func (s *SQLite) IdempotencyReserve(
+	ctx context.Context,
+	key, fingerprint string,
+	ttl time.Duration,
+) (string, []byte, bool, error) {
+	var (
+		recorded string
+		response []byte
+		reserved bool
+	)
+	now := time.Now()
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires <= ?`, now.UnixNano())
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO idempotency_keys (key, fingerprint, expires) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+			key, fingerprint, now.Add(ttl).UnixNano(),
+		)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil || n == 1 {
+			reserved = n == 1
+			return err
+		}
+
+		return tx.QueryRowContext(ctx,
+			`SELECT fingerprint, response FROM idempotency_keys WHERE key = ?`, key,
+		).Scan(&recorded, &response)
+	})
+	return recorded, response, reserved, err
+}
+
+// IdempotencySave implements router.IdempotencyStore.
+func (s *SQLite) IdempotencySave(ctx context.Context, key string, response []byte) error {
+	_, err := s.conn().ExecContext(ctx, `UPDATE idempotency_keys SET response = ? WHERE key = ?`, response, key)
+	return err
+}
+
+// IdempotencyRelease implements router.IdempotencyStore.
+func (s *SQLite) IdempotencyRelease(ctx context.Context, key string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = ?`, key)
+	return err
+}
@@ -0,0 +1,14 @@
+CREATE TABLE contacts (
+	id            TEXT PRIMARY KEY,
+	firstname     TEXT NOT NULL,
+	lastname      TEXT NOT NULL,
+	birthday      TEXT NOT NULL,
+	-- sort keys, see domain.ContactsSort.Key
+	firstname_key TEXT NOT NULL,
+	lastname_key  TEXT NOT NULL,
+	birthday_key  TEXT NOT NULL
+);
+
+CREATE INDEX contacts_firstname_key ON contacts (firstname_key, id);
+CREATE INDEX contacts_lastname_key ON contacts (lastname_key, id);
+CREATE INDEX contacts_birthday_key ON contacts (birthday_key, id);
@@ -0,0 +1,9 @@
+ALTER TABLE contacts ADD COLUMN tenant TEXT NOT NULL DEFAULT '';
+
+DROP INDEX contacts_firstname_key;
+DROP INDEX contacts_lastname_key;
+DROP INDEX contacts_birthday_key;
+
+CREATE INDEX contacts_firstname_key ON contacts (tenant, firstname_key, id);
+CREATE INDEX contacts_lastname_key ON contacts (tenant, lastname_key, id);
+CREATE INDEX contacts_birthday_key ON contacts (tenant, birthday_key, id);
@@ -0,0 +1,3 @@
+ALTER TABLE contacts ADD COLUMN emails TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE contacts ADD COLUMN phones TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE contacts ADD COLUMN addresses TEXT NOT NULL DEFAULT '[]';
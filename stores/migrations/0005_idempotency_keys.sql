@@ -0,0 +1,8 @@
+CREATE TABLE idempotency_keys (
+	key         TEXT PRIMARY KEY,
+	fingerprint TEXT NOT NULL,
+	response    BLOB,              -- NULL while the request is in progress
+	expires     INTEGER NOT NULL   -- Unix time in nanoseconds
+);
+
+CREATE INDEX idempotency_keys_expires ON idempotency_keys (expires);
@@ -0,0 +1,4 @@
+-- Unix time in nanoseconds the contact was moved to the trash at, NULL otherwise
+ALTER TABLE contacts ADD COLUMN deleted_at INTEGER;
+
+CREATE INDEX contacts_deleted_at ON contacts (deleted_at) WHERE deleted_at IS NOT NULL;
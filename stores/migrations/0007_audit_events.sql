@@ -0,0 +1,12 @@
+CREATE TABLE audit_events (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	tenant     TEXT NOT NULL,
+	contact_id TEXT NOT NULL,
+	action     TEXT NOT NULL,
+	actor      TEXT NOT NULL,
+	request_id TEXT NOT NULL,
+	time       INTEGER NOT NULL, -- Unix time in nanoseconds
+	changes    TEXT NOT NULL     -- JSON array of the changed fields
+);
+
+CREATE INDEX audit_events_contact ON audit_events (tenant, contact_id, id);
@@ -0,0 +1,5 @@
+CREATE TABLE outbox (
+	seq   INTEGER PRIMARY KEY AUTOINCREMENT,
+	id    TEXT NOT NULL UNIQUE,
+	event TEXT NOT NULL -- JSON of the domain event
+);
@@ -0,0 +1,23 @@
+CREATE TABLE webhooks (
+	id      TEXT PRIMARY KEY,
+	tenant  TEXT NOT NULL,
+	url     TEXT NOT NULL,
+	secret  TEXT NOT NULL,
+	types   TEXT NOT NULL,   -- JSON array of event types, all if empty
+	created INTEGER NOT NULL -- Unix time in nanoseconds
+);
+
+CREATE INDEX webhooks_tenant ON webhooks (tenant, created);
+
+CREATE TABLE webhook_deliveries (
+	id         TEXT PRIMARY KEY,
+	webhook_id TEXT NOT NULL,
+	event      TEXT NOT NULL,    -- JSON of the domain event
+	attempts   INTEGER NOT NULL,
+	next       INTEGER NOT NULL, -- Unix time in nanoseconds of the next attempt
+	last_error TEXT NOT NULL,
+	dead       INTEGER NOT NULL  -- boolean
+);
+
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (dead, next);
+CREATE INDEX webhook_deliveries_webhook ON webhook_deliveries (webhook_id, dead);
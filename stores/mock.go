@@ -1,8 +1,12 @@
 package stores
 
 import (
+	"cmp"
 	"context"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -14,9 +18,15 @@ type Mock struct {
 	mu       sync.Mutex
 	index    map[domain.ContactID]int
 	contacts []*domain.Contact
+	tenants  []domain.TenantID // tenant of the contact at the same index
+	events   []mockEvent
+	outbox   []*domain.Event
 }
 
-var _ domain.Store = (*Mock)(nil)
+var (
+	_ domain.AtomicStore = (*Mock)(nil)
+	_ domain.OutboxStore = (*Mock)(nil)
+)
 
 func MustNewMock(cs ...*domain.Contact) *Mock {
 	s := &Mock{index: map[domain.ContactID]int{}}
@@ -29,53 +39,234 @@ func MustNewMock(cs ...*domain.Contact) *Mock {
 	return s
 }
 
-func (s *Mock) ContactsSet(_ context.Context, c *domain.Contact) (domain.ContactID, error) {
+func (s *Mock) ContactsSet(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsSet(ctx, c)
+}
+
+func (s *Mock) ContactsGet(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsGet(ctx, id)
+}
+
+func (s *Mock) ContactsReset(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsReset(ctx, id, c)
+}
+
+func (s *Mock) ContactsDel(ctx context.Context, id domain.ContactID, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsDel(ctx, id, version)
+}
+
+func (s *Mock) ContactsUndel(ctx context.Context, id domain.ContactID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsUndel(ctx, id)
+}
+
+func (s *Mock) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsPurge(ctx, id)
+}
+
+func (s *Mock) ContactsPurgeBefore(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsPurgeBefore(ctx, before)
+}
+
+func (s *Mock) ContactsModify(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsModify(ctx, id, p)
+}
+
+func (s *Mock) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).ContactsList(ctx, q)
+}
 
+// ContactsAtomic holds the lock while calling the function, and restores the contacts, outbox and history
+// if it fails.
+func (s *Mock) ContactsAtomic(_ context.Context, f func(domain.Store) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// stored contacts are replaced rather than modified, shallow copies are enough
+	index, contacts, tenants := maps.Clone(s.index), slices.Clone(s.contacts), slices.Clone(s.tenants)
+	outbox, events := slices.Clone(s.outbox), len(s.events)
+	err := f((*mockTx)(s))
+	if err != nil {
+		s.index, s.contacts, s.tenants, s.outbox, s.events = index, contacts, tenants, outbox, s.events[:events]
+	}
+	return err
+}
+
+// mockTx implements [domain.Store] with the lock of a [Mock] held.
+type mockTx Mock
+
+// lookup returns the index of a contact of the context's tenant, in the trash or not.
+func (s *mockTx) lookup(ctx context.Context, id domain.ContactID, trashed bool) (int, error) {
+	index, ok := s.index[id]
+	if !ok || s.contacts[index] == nil || s.tenants[index] != domain.TenantFrom(ctx) ||
+		s.contacts[index].DeletedAt.IsZero() == trashed {
+		return 0, domain.ErrNotFound
+	}
+	return index, nil
+}
+
+func (s *mockTx) ContactsSet(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	c = c.Clone()
+	c.Version = 1
+	c.DeletedAt = time.Time{}
 	for ko := true; ko; _, ko = s.index[c.ID] {
 		c.ID = domain.ContactID{UUID: uuid.New()}
 	}
 	s.index[c.ID] = len(s.contacts)
 	s.contacts = append(s.contacts, c)
+	s.tenants = append(s.tenants, domain.TenantFrom(ctx))
 	return c.ID, nil
 }
 
-func (s *Mock) ContactsGet(_ context.Context, id domain.ContactID) (*domain.Contact, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *mockTx) ContactsGet(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	index, err := s.lookup(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
 
-	index, ok := s.index[id]
-	if !ok || s.contacts[index] == nil {
-		return nil, domain.ErrNotFound
+	return s.contacts[index].Clone(), nil
+}
+
+func (s *mockTx) ContactsReset(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
+	index, err := s.lookup(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if c.Version != 0 && c.Version != s.contacts[index].Version {
+		return domain.ErrConflict
 	}
 
-	return s.contacts[index], nil
+	c = c.Clone()
+	c.ID = id
+	c.Version = s.contacts[index].Version + 1
+	c.DeletedAt = s.contacts[index].DeletedAt
+	s.contacts[index] = c
+	return nil
 }
 
-func (s *Mock) ContactsReset(_ context.Context, id domain.ContactID, c *domain.Contact) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *mockTx) ContactsDel(ctx context.Context, id domain.ContactID, version int) error {
+	index, err := s.lookup(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != s.contacts[index].Version {
+		return domain.ErrConflict
+	}
 
-	index, ok := s.index[id]
-	if !ok || s.contacts[index] == nil {
-		return domain.ErrNotFound
+	// stored contacts are replaced rather than modified, see [Mock.ContactsAtomic]
+	c := s.contacts[index].Clone()
+	c.DeletedAt = time.Now()
+	s.contacts[index] = c
+	return nil
+}
+
+func (s *mockTx) ContactsUndel(ctx context.Context, id domain.ContactID) error {
+	index, err := s.lookup(ctx, id, true)
+	if err != nil {
+		return err
 	}
 
-	c.ID = id
+	c := s.contacts[index].Clone()
+	c.DeletedAt = time.Time{}
+	c.Version++
 	s.contacts[index] = c
 	return nil
 }
 
-func (s *Mock) ContactsDel(_ context.Context, id domain.ContactID) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *mockTx) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	index, err := s.lookup(ctx, id, true)
+	if err != nil {
+		return err
+	}
 
-	index, ok := s.index[id]
-	if !ok || s.contacts[index] == nil {
-		return domain.ErrNotFound
+	s.purge(index)
+	return nil
+}
+
+func (s *mockTx) ContactsPurgeBefore(_ context.Context, before time.Time) (int, error) {
+	var n int
+	for index, c := range s.contacts {
+		if c != nil && !c.DeletedAt.IsZero() && c.DeletedAt.Before(before) {
+			s.purge(index)
+			n++
+		}
 	}
+	return n, nil
+}
 
+// purge forgets the contact at an index, leaving its slot empty for the others to keep theirs.
+func (s *mockTx) purge(index int) {
+	delete(s.index, s.contacts[index].ID)
 	s.contacts[index] = nil
-	return nil
+	s.tenants[index] = ""
+}
+
+func (s *mockTx) ContactsModify(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	index, err := s.lookup(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if p.Version != 0 && p.Version != s.contacts[index].Version {
+		return nil, domain.ErrConflict
+	}
+
+	c := s.contacts[index].Clone()
+	p.Apply(c)
+
+	c.ID = id
+	c.Version = s.contacts[index].Version + 1
+	s.contacts[index] = c
+	return c.Clone(), nil
+}
+
+func (s *mockTx) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	cur, err := domain.DecodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := domain.TenantFrom(ctx)
+	var cs []*domain.Contact
+	for index, c := range s.contacts {
+		if c != nil && s.tenants[index] == tenant && c.DeletedAt.IsZero() != q.Trashed &&
+			q.Match(c) && (q.Cursor == "" || cur.Less(q.Sort.Key(c), c.ID)) {
+			cs = append(cs, c.Clone())
+		}
+	}
+	slices.SortFunc(cs, func(a, b *domain.Contact) int {
+		return cmp.Or(cmp.Compare(q.Sort.Key(a), q.Sort.Key(b)), cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	page := &domain.ContactsPage{Contacts: cs}
+	if q.Limit > 0 && len(cs) > q.Limit {
+		page.Contacts = cs[:q.Limit]
+		last := page.Contacts[q.Limit-1]
+		page.Next = domain.Cursor{Key: q.Sort.Key(last), ID: last.ID}.Encode()
+	}
+	return page, nil
 }
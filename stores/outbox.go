@@ -0,0 +1,114 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+func (s *Mock) OutboxAppend(ctx context.Context, events ...*domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).OutboxAppend(ctx, events...)
+}
+
+func (s *Mock) OutboxFetch(ctx context.Context, limit int) ([]*domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).OutboxFetch(ctx, limit)
+}
+
+func (s *Mock) OutboxDelete(ctx context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (*mockTx)(s).OutboxDelete(ctx, ids...)
+}
+
+// cloneEvent returns a copy of an event sharing no memory with it.
+func cloneEvent(e *domain.Event) *domain.Event {
+	clone := *e
+	if e.Contact != nil {
+		clone.Contact = e.Contact.Clone()
+	}
+	return &clone
+}
+
+func (s *mockTx) OutboxAppend(_ context.Context, events ...*domain.Event) error {
+	for _, e := range events {
+		s.outbox = append(s.outbox, cloneEvent(e))
+	}
+	return nil
+}
+
+func (s *mockTx) OutboxFetch(_ context.Context, limit int) ([]*domain.Event, error) {
+	events := s.outbox[:min(limit, len(s.outbox))]
+	clones := make([]*domain.Event, len(events))
+	for n, e := range events {
+		clones[n] = cloneEvent(e)
+	}
+	return clones, nil
+}
+
+func (s *mockTx) OutboxDelete(_ context.Context, ids ...string) error {
+	// the outbox is replaced rather than modified, see [Mock.ContactsAtomic]
+	s.outbox = slices.DeleteFunc(slices.Clone(s.outbox), func(e *domain.Event) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	return nil
+}
+
+var _ domain.OutboxStore = (*SQLite)(nil)
+
+func (s *SQLite) OutboxAppend(ctx context.Context, events ...*domain.Event) error {
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = s.conn().ExecContext(ctx, `INSERT INTO outbox (id, event) VALUES (?, ?)`, e.ID, string(b))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) OutboxFetch(ctx context.Context, limit int) ([]*domain.Event, error) {
+	rows, err := s.conn().QueryContext(ctx, `SELECT event FROM outbox ORDER BY seq LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.Event
+	for rows.Next() {
+		var b string
+		err := rows.Scan(&b)
+		if err != nil {
+			return nil, err
+		}
+		var e domain.Event
+		err = json.Unmarshal([]byte(b), &e)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+func (s *SQLite) OutboxDelete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]any, len(ids))
+	for n, id := range ids {
+		args[n] = id
+	}
+	_, err := s.conn().ExecContext(ctx, //nolint: gosec // placeholders only
+		`DELETE FROM outbox WHERE id IN (?`+strings.Repeat(`, ?`, len(ids)-1)+`)`, args...)
+	return err
+}
@@ -0,0 +1,470 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// SQLite is an implementation of [domain.Store] backed by a SQLite database.
+type SQLite struct {
+	db     *sql.DB
+	atomic *sql.Tx // transaction of [SQLite.ContactsAtomic], if any
+}
+
+var _ domain.AtomicStore = (*SQLite)(nil)
+
+// migrations are the versioned SQL scripts applied to the database, in lexical order.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// NewSQLite opens a SQLite database given its DSN, e.g. "file:contacts.db" or ":memory:",
+// and applies its pending migrations.
+func NewSQLite(ctx context.Context, dsn string) (*SQLite, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes writes and keeps ":memory:" databases alive
+	db.SetMaxOpenConns(1)
+
+	s := &SQLite{db: db}
+	err = s.migrate(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *SQLite) Close() error { return s.db.Close() }
+
+// Check pings the database, e.g. as a readiness check.
+func (s *SQLite) Check(ctx context.Context) error { return s.db.PingContext(ctx) }
+
+func (s *SQLite) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+
+		err = s.tx(ctx, func(tx *sql.Tx) error {
+			var applied bool
+			err := tx.QueryRowContext(ctx,
+				`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, version,
+			).Scan(&applied)
+			if err != nil || applied {
+				return err
+			}
+
+			script, err := migrations.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, string(script))
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// conn returns the transaction of [SQLite.ContactsAtomic] if any, the database otherwise.
+func (s *SQLite) conn() interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+} {
+	if s.atomic != nil {
+		return s.atomic
+	}
+	return s.db
+}
+
+// tx runs a function in a transaction, committed if it returns no error and rolled back otherwise.
+// It joins the transaction of [SQLite.ContactsAtomic], if any.
+func (s *SQLite) tx(ctx context.Context, f func(*sql.Tx) error) error {
+	if s.atomic != nil {
+		return f(s.atomic)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint: errcheck // no-op once committed
+
+	err = f(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqliteColumns are the columns scanned by [scanContact].
+const sqliteColumns = `id, version, firstname, lastname, birthday, emails, phones, addresses, deleted_at`
+
+// sqliteEmail, sqlitePhone and sqliteAddress are the JSON representations of contact details in their columns.
+type (
+	sqliteEmail struct {
+		Address string       `json:"address"`
+		Label   domain.Label `json:"label"`
+		Primary bool         `json:"primary,omitempty"`
+	}
+	sqlitePhone struct {
+		Number  string       `json:"number"`
+		Label   domain.Label `json:"label"`
+		Primary bool         `json:"primary,omitempty"`
+	}
+	sqliteAddress struct {
+		Street     string       `json:"street,omitempty"`
+		Locality   string       `json:"locality"`
+		Region     string       `json:"region,omitempty"`
+		PostalCode string       `json:"postal_code,omitempty"`
+		Country    string       `json:"country"`
+		Label      domain.Label `json:"label"`
+		Primary    bool         `json:"primary,omitempty"`
+	}
+)
+
+func scanContact(row interface{ Scan(...any) error }) (*domain.Contact, error) {
+	var (
+		c                         domain.Contact
+		birthday                  string
+		emails, phones, addresses string
+		deletedAt                 sql.NullInt64
+	)
+	err := row.Scan(&c.ID, &c.Version, &c.Firstname, &c.Lastname, &birthday, &emails, &phones, &addresses, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.Birthday, err = time.Parse(time.RFC3339Nano, birthday)
+	if err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		c.DeletedAt = time.Unix(0, deletedAt.Int64)
+	}
+	c.Emails, err = scanDetails(emails, func(e sqliteEmail) domain.Email { return domain.Email(e) })
+	if err != nil {
+		return nil, fmt.Errorf("emails: %w", err)
+	}
+	c.Phones, err = scanDetails(phones, func(p sqlitePhone) domain.Phone { return domain.Phone(p) })
+	if err != nil {
+		return nil, fmt.Errorf("phones: %w", err)
+	}
+	c.Addresses, err = scanDetails(addresses, func(a sqliteAddress) domain.Address { return domain.Address(a) })
+	if err != nil {
+		return nil, fmt.Errorf("addresses: %w", err)
+	}
+	return &c, nil
+}
+
+// scanDetails decodes the JSON array of a details column, nil if empty.
+func scanDetails[T, U any](column string, conv func(U) T) ([]T, error) {
+	var rows []U
+	err := json.Unmarshal([]byte(column), &rows)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	details := make([]T, len(rows))
+	for n, row := range rows {
+		details[n] = conv(row)
+	}
+	return details, nil
+}
+
+// detailsValue encodes details as the JSON array of their column.
+func detailsValue[T, U any](details []T, conv func(T) U) string {
+	rows := make([]U, len(details))
+	for n, d := range details {
+		rows[n] = conv(d)
+	}
+	b, _ := json.Marshal(rows) //nolint: errchkjson // plain structs always marshal
+	return string(b)
+}
+
+// contactValues returns the values of a [Contact] for the columns
+// firstname, lastname, birthday, emails, phones, addresses, firstname_key, lastname_key and birthday_key.
+func contactValues(c *domain.Contact) []any {
+	return []any{
+		c.Firstname,
+		c.Lastname,
+		c.Birthday.Format(time.RFC3339Nano),
+		detailsValue(c.Emails, func(e domain.Email) sqliteEmail { return sqliteEmail(e) }),
+		detailsValue(c.Phones, func(p domain.Phone) sqlitePhone { return sqlitePhone(p) }),
+		detailsValue(c.Addresses, func(a domain.Address) sqliteAddress { return sqliteAddress(a) }),
+		domain.ContactsSortFirstname.Key(c),
+		domain.ContactsSortLastname.Key(c),
+		domain.ContactsSortBirthday.Key(c),
+	}
+}
+
+func (s *SQLite) ContactsSet(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	id := domain.ContactID{UUID: uuid.New()}
+	_, err := s.conn().ExecContext(ctx,
+		`INSERT INTO contacts (id, tenant, firstname, lastname, birthday, emails, phones, addresses,
+		firstname_key, lastname_key, birthday_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append([]any{id, domain.TenantFrom(ctx)}, contactValues(c)...)...,
+	)
+	if err != nil {
+		return domain.ContactID{}, err
+	}
+	return id, nil
+}
+
+func (s *SQLite) ContactsGet(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	return scanContact(s.conn().QueryRowContext(ctx,
+		`SELECT `+sqliteColumns+` FROM contacts WHERE id = ? AND tenant = ? AND deleted_at IS NULL`,
+		id, domain.TenantFrom(ctx),
+	))
+}
+
+func (s *SQLite) ContactsReset(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		err := checkVersion(ctx, tx, id, c.Version)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE contacts SET version = version + 1, firstname = ?, lastname = ?, birthday = ?,
+			emails = ?, phones = ?, addresses = ?, firstname_key = ?, lastname_key = ?, birthday_key = ?
+			WHERE id = ?`,
+			append(contactValues(c), id)...,
+		)
+		return err
+	})
+}
+
+func (s *SQLite) ContactsDel(ctx context.Context, id domain.ContactID, version int) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		err := checkVersion(ctx, tx, id, version)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE contacts SET deleted_at = ? WHERE id = ?`, time.Now().UnixNano(), id)
+		return err
+	})
+}
+
+func (s *SQLite) ContactsUndel(ctx context.Context, id domain.ContactID) error {
+	res, err := s.conn().ExecContext(ctx,
+		`UPDATE contacts SET deleted_at = NULL, version = version + 1
+		WHERE id = ? AND tenant = ? AND deleted_at IS NOT NULL`,
+		id, domain.TenantFrom(ctx),
+	)
+	return affected(res, err)
+}
+
+func (s *SQLite) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	res, err := s.conn().ExecContext(ctx,
+		`DELETE FROM contacts WHERE id = ? AND tenant = ? AND deleted_at IS NOT NULL`,
+		id, domain.TenantFrom(ctx),
+	)
+	return affected(res, err)
+}
+
+func (s *SQLite) ContactsPurgeBefore(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.conn().ExecContext(ctx, `DELETE FROM contacts WHERE deleted_at < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// affected fails with [domain.ErrNotFound] if a statement affected no row.
+func affected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (s *SQLite) ContactsModify(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	var c *domain.Contact
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		var err error
+		c, err = scanContact(tx.QueryRowContext(ctx,
+			`SELECT `+sqliteColumns+` FROM contacts WHERE id = ? AND tenant = ? AND deleted_at IS NULL`,
+			id, domain.TenantFrom(ctx),
+		))
+		if err != nil {
+			return err
+		}
+		if p.Version != 0 && p.Version != c.Version {
+			return domain.ErrConflict
+		}
+
+		p.Apply(c)
+
+		c.ID = id
+		c.Version++
+		_, err = tx.ExecContext(ctx,
+			`UPDATE contacts SET version = ?, firstname = ?, lastname = ?, birthday = ?,
+			emails = ?, phones = ?, addresses = ?, firstname_key = ?, lastname_key = ?, birthday_key = ?
+			WHERE id = ?`,
+			append(append([]any{c.Version}, contactValues(c)...), id)...,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ContactsAtomic calls the function with a store running its queries in a single transaction.
+func (s *SQLite) ContactsAtomic(ctx context.Context, f func(domain.Store) error) error {
+	return s.tx(ctx, func(tx *sql.Tx) error { return f(&SQLite{db: s.db, atomic: tx}) })
+}
+
+// checkVersion checks a contact of the context's tenant exists out of the trash and its version matches, unless zero.
+func checkVersion(ctx context.Context, tx *sql.Tx, id domain.ContactID, version int) error {
+	var stored int
+	err := tx.QueryRowContext(ctx,
+		`SELECT version FROM contacts WHERE id = ? AND tenant = ? AND deleted_at IS NULL`, id, domain.TenantFrom(ctx),
+	).Scan(&stored)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.ErrNotFound
+	case err != nil:
+		return err
+	case version != 0 && version != stored:
+		return domain.ErrConflict
+	default:
+		return nil
+	}
+}
+
+// sqliteSortColumns maps sort orders to their key column.
+var sqliteSortColumns = map[domain.ContactsSort]string{ //nolint: gochecknoglobals // read-only table
+	domain.ContactsSortFirstname: "firstname_key",
+	domain.ContactsSortLastname:  "lastname_key",
+	domain.ContactsSortBirthday:  "birthday_key",
+}
+
+func (s *SQLite) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	cur, err := domain.DecodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	column, ok := sqliteSortColumns[q.Sort]
+	if !ok {
+		column = sqliteSortColumns[domain.ContactsSortLastname]
+	}
+
+	var (
+		where = []string{`tenant = ?`, `deleted_at IS NULL`}
+		args  = []any{domain.TenantFrom(ctx)}
+	)
+	if q.Trashed {
+		where[1] = `deleted_at IS NOT NULL`
+	}
+	if q.NamePrefix != "" {
+		like := escapeLike(strings.ToLower(q.NamePrefix)) + "%"
+		where = append(where, `(firstname_key LIKE ? ESCAPE '\' OR lastname_key LIKE ? ESCAPE '\')`)
+		args = append(args, like, like)
+	}
+	if !q.BornAfter.IsZero() {
+		where = append(where, `birthday_key > ?`)
+		args = append(args, domain.ContactsSortBirthday.Key(&domain.Contact{Birthday: q.BornAfter}))
+	}
+	if !q.BornBefore.IsZero() {
+		where = append(where, `birthday_key < ?`)
+		args = append(args, domain.ContactsSortBirthday.Key(&domain.Contact{Birthday: q.BornBefore}))
+	}
+	if q.Cursor != "" {
+		where = append(where, `(`+column+`, id) > (?, ?)`)
+		args = append(args, cur.Key, cur.ID)
+	}
+	limit := -1
+	if q.Limit > 0 {
+		limit = q.Limit + 1
+	}
+	args = append(args, limit)
+
+	rows, err := s.conn().QueryContext(ctx, //nolint: gosec // column comes from a fixed table
+		`SELECT `+sqliteColumns+` FROM contacts WHERE `+strings.Join(where, ` AND `)+
+			` ORDER BY `+column+`, id LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.ContactsPage{}
+	for rows.Next() {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Contacts = append(page.Contacts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if q.Limit > 0 && len(page.Contacts) > q.Limit {
+		page.Contacts = page.Contacts[:q.Limit]
+		last := page.Contacts[q.Limit-1]
+		page.Next = domain.Cursor{Key: q.Sort.Key(last), ID: last.ID}.Encode()
+	}
+	return page, nil
+}
+
+// escapeLike escapes the LIKE wildcards of a string with '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
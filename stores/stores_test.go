@@ -0,0 +1,95 @@
+package stores_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/router"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/webhooks/webhookstest"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("mock", func(t *testing.T) {
+		domaintest.TestStore(t, stores.MustNewMock())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := stores.NewSQLite(t.Context(), "file:"+t.TempDir()+"/contacts.db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+
+		domaintest.TestStore(t, store)
+	})
+}
+
+func TestAuditStore(t *testing.T) {
+	t.Run("mock", func(t *testing.T) {
+		domaintest.TestAuditStore(t, stores.MustNewMock())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := stores.NewSQLite(t.Context(), "file:"+t.TempDir()+"/contacts.db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+
+		domaintest.TestAuditStore(t, store)
+	})
+}
+
+func TestSQLiteWebhooks(t *testing.T) {
+	store, err := stores.NewSQLite(t.Context(), "file:"+t.TempDir()+"/contacts.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	webhookstest.TestStore(t, store)
+}
+
+var _ router.IdempotencyStore = (*stores.SQLite)(nil)
+
+func TestSQLiteIdempotency(t *testing.T) {
+	store, err := stores.NewSQLite(t.Context(), "file:"+t.TempDir()+"/contacts.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	reserve := func(key, fingerprint string, ttl time.Duration) (string, []byte, bool) {
+		t.Helper()
+		recorded, response, ok, err := store.IdempotencyReserve(t.Context(), key, fingerprint, ttl)
+		if err != nil {
+			t.Fatal("IdempotencyReserve:", err)
+		}
+		return recorded, response, ok
+	}
+
+	if _, _, ok := reserve("k", "f1", time.Hour); !ok {
+		t.Fatal("IdempotencyReserve of a new key failed")
+	}
+	if recorded, response, ok := reserve("k", "f2", time.Hour); ok || recorded != "f1" || response != nil {
+		t.Error("IdempotencyReserve of a key in progress returned", recorded, response, ok)
+	}
+	if err := store.IdempotencySave(t.Context(), "k", []byte("response")); err != nil {
+		t.Fatal("IdempotencySave:", err)
+	}
+	if recorded, response, ok := reserve("k", "f1", time.Hour); ok || recorded != "f1" || string(response) != "response" {
+		t.Error("IdempotencyReserve of a saved key returned", recorded, response, ok)
+	}
+	if err := store.IdempotencyRelease(t.Context(), "k"); err != nil {
+		t.Fatal("IdempotencyRelease:", err)
+	}
+	if _, _, ok := reserve("k", "f2", time.Nanosecond); !ok {
+		t.Error("IdempotencyReserve of a released key failed")
+	}
+	time.Sleep(time.Millisecond)
+	if _, _, ok := reserve("k", "f3", time.Hour); !ok {
+		t.Error("IdempotencyReserve of an expired key failed")
+	}
+}
@@ -0,0 +1,214 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/webhooks"
+)
+
+var _ webhooks.Store = (*SQLite)(nil)
+
+// sqliteWebhookColumns are the columns scanned by [scanWebhook].
+const sqliteWebhookColumns = `id, url, secret, types, created`
+
+func scanWebhook(row interface{ Scan(...any) error }) (*webhooks.Subscription, error) {
+	var (
+		s       webhooks.Subscription
+		types   string
+		created int64
+	)
+	err := row.Scan(&s.ID, &s.URL, &s.Secret, &types, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Created = time.Unix(0, created)
+	err = json.Unmarshal([]byte(types), &s.Types)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *SQLite) WebhooksCreate(ctx context.Context, sub *webhooks.Subscription) error {
+	types, err := json.Marshal(sub.Types)
+	if err != nil {
+		return err
+	}
+	id := uuid.NewString()
+	_, err = s.conn().ExecContext(ctx,
+		`INSERT INTO webhooks (id, tenant, url, secret, types, created) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, domain.TenantFrom(ctx), sub.URL, sub.Secret, string(types), sub.Created.UnixNano(),
+	)
+	if err != nil {
+		return err
+	}
+	sub.ID = id
+	return nil
+}
+
+func (s *SQLite) WebhooksGet(ctx context.Context, id string) (*webhooks.Subscription, error) {
+	return scanWebhook(s.conn().QueryRowContext(ctx,
+		`SELECT `+sqliteWebhookColumns+` FROM webhooks WHERE id = ? AND tenant = ?`, id, domain.TenantFrom(ctx),
+	))
+}
+
+func (s *SQLite) WebhooksList(ctx context.Context) ([]*webhooks.Subscription, error) {
+	rows, err := s.conn().QueryContext(ctx,
+		`SELECT `+sqliteWebhookColumns+` FROM webhooks WHERE tenant = ? ORDER BY created, id`, domain.TenantFrom(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*webhooks.Subscription
+	for rows.Next() {
+		sub, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (s *SQLite) WebhooksDelete(ctx context.Context, id string) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		err := affected(tx.ExecContext(ctx,
+			`DELETE FROM webhooks WHERE id = ? AND tenant = ?`, id, domain.TenantFrom(ctx)))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id)
+		return err
+	})
+}
+
+// checkWebhook checks a subscription of the context's tenant exists.
+func (s *SQLite) checkWebhook(ctx context.Context, id string) error {
+	var exists bool
+	err := s.conn().QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = ? AND tenant = ?)`, id, domain.TenantFrom(ctx),
+	).Scan(&exists)
+	if err == nil && !exists {
+		err = domain.ErrNotFound
+	}
+	return err
+}
+
+// sqliteDeliveryColumns are the columns scanned by [scanDelivery].
+const sqliteDeliveryColumns = `id, webhook_id, event, attempts, next, last_error, dead`
+
+func scanDelivery(row interface{ Scan(...any) error }) (*webhooks.Delivery, error) {
+	var (
+		d     webhooks.Delivery
+		event string
+		next  int64
+	)
+	err := row.Scan(&d.ID, &d.Subscription, &event, &d.Attempts, &next, &d.LastError, &d.Dead)
+	if err != nil {
+		return nil, err
+	}
+	d.Next = time.Unix(0, next)
+	err = json.Unmarshal([]byte(event), &d.Event)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (s *SQLite) queryDeliveries(ctx context.Context, query string, args ...any) ([]*webhooks.Delivery, error) {
+	rows, err := s.conn().QueryContext(ctx, `SELECT `+sqliteDeliveryColumns+` FROM webhook_deliveries `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*webhooks.Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (s *SQLite) DeliveriesAppend(ctx context.Context, deliveries ...*webhooks.Delivery) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		for _, d := range deliveries {
+			event, err := json.Marshal(d.Event)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO webhook_deliveries (`+sqliteDeliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				d.ID, d.Subscription, string(event), d.Attempts, d.Next.UnixNano(), d.LastError, d.Dead,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLite) DeliveriesDue(ctx context.Context, t time.Time, limit int) ([]*webhooks.Delivery, error) {
+	return s.queryDeliveries(ctx, `WHERE NOT dead AND next <= ? ORDER BY next, id LIMIT ?`, t.UnixNano(), limit)
+}
+
+func (s *SQLite) DeliveriesUpdate(ctx context.Context, d *webhooks.Delivery) error {
+	return affected(s.conn().ExecContext(ctx,
+		`UPDATE webhook_deliveries SET attempts = ?, next = ?, last_error = ?, dead = ? WHERE id = ?`,
+		d.Attempts, d.Next.UnixNano(), d.LastError, d.Dead, d.ID,
+	))
+}
+
+func (s *SQLite) DeliveriesDelete(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE id = ?`, id)
+	return err
+}
+
+func (s *SQLite) DeliveriesDead(ctx context.Context, id string) ([]*webhooks.Delivery, error) {
+	err := s.checkWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.queryDeliveries(ctx, `WHERE webhook_id = ? AND dead ORDER BY next, id`, id)
+}
+
+func (s *SQLite) DeliveriesRevive(ctx context.Context, id string, t time.Time) (int, error) {
+	err := s.checkWebhook(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.conn().ExecContext(ctx,
+		`UPDATE webhook_deliveries SET dead = FALSE, attempts = 0, next = ? WHERE webhook_id = ? AND dead`,
+		t.UnixNano(), id,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (s *SQLite) DeliveriesPurgeBefore(ctx context.Context, t time.Time) (int, error) {
+	res, err := s.conn().ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE dead AND next < ?`, t.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,369 @@
+package vcard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Error is an invalid vCard entry. Decoding may continue with the next entry.
+type Error struct {
+	Card int // 1-based index of the entry in the stream, text outside entries counting as one
+	Line int // line of the error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("vcard: card %d, line %d: %v", e.Card, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Decoder reads vCard 3.0 and 4.0 entries from a stream, one at a time.
+//
+// Properties unknown to [domain.Contact] are ignored.
+type Decoder struct {
+	s    *bufio.Scanner
+	line int // number of the last line scanned
+	card int // number of entries read
+
+	ahead   string // line scanned ahead, if any
+	isAhead bool
+	begun   bool // whether the BEGIN line of the next entry was already read
+}
+
+// NewDecoder returns a [Decoder] reading from r.
+func NewDecoder(r io.Reader) *Decoder { return &Decoder{s: bufio.NewScanner(r)} }
+
+// Decode reads the next entry. It returns [io.EOF] at the end of the stream and an [*Error] for invalid
+// entries, any other error ends decoding.
+//
+// Decoded contacts have an ID if their UID is an "urn:uuid:" URI, and are neither normalized nor validated.
+func (d *Decoder) Decode() (*domain.Contact, error) {
+	if !d.begun {
+		line, n, ok := d.next()
+		for ok && strings.TrimSpace(line) == "" {
+			line, n, ok = d.next()
+		}
+		if !ok {
+			return nil, d.err(io.EOF)
+		}
+		d.card++
+		if !isDelimiter(line, "BEGIN") {
+			for {
+				line, _, ok = d.next()
+				if !ok || isDelimiter(line, "BEGIN") {
+					d.begun = ok
+					break
+				}
+			}
+			return nil, &Error{Card: d.card, Line: n, Err: errors.New("expected BEGIN:VCARD")}
+		}
+	} else {
+		d.card++
+	}
+	d.begun = false
+
+	var (
+		b       builder
+		err     error
+		errLine int
+	)
+	for {
+		line, n, ok := d.next()
+		switch {
+		case !ok:
+			err = d.err(nil)
+			if err != nil {
+				return nil, err
+			}
+			return nil, &Error{Card: d.card, Line: d.line, Err: errors.New("missing END:VCARD")}
+		case isDelimiter(line, "BEGIN"):
+			d.begun = true
+			return nil, &Error{Card: d.card, Line: n, Err: errors.New("missing END:VCARD")}
+		case isDelimiter(line, "END"):
+			if err == nil {
+				err, errLine = b.finish(), n
+			}
+			if err != nil {
+				return nil, &Error{Card: d.card, Line: errLine, Err: err}
+			}
+			return &b.contact, nil
+		case err == nil && strings.TrimSpace(line) != "":
+			err, errLine = b.property(line), n
+		}
+	}
+}
+
+func (d *Decoder) err(eof error) error {
+	if err := d.s.Err(); err != nil {
+		return err
+	}
+	return eof
+}
+
+// physical returns the next physical line, without its line terminator.
+func (d *Decoder) physical() (string, bool) {
+	if d.isAhead {
+		d.isAhead = false
+		return d.ahead, true
+	}
+	if !d.s.Scan() {
+		return "", false
+	}
+	d.line++
+	return strings.TrimSuffix(d.s.Text(), "\r"), true
+}
+
+// next returns the next content line, unfolded, and its line number.
+func (d *Decoder) next() (string, int, bool) {
+	line, ok := d.physical()
+	if !ok {
+		return "", 0, false
+	}
+	n := d.line
+	for {
+		continuation, ok := d.physical()
+		if !ok {
+			break
+		}
+		if continuation == "" || (continuation[0] != ' ' && continuation[0] != '\t') {
+			d.ahead, d.isAhead = continuation, true
+			break
+		}
+		line += continuation[1:]
+	}
+	return line, n, true
+}
+
+// isDelimiter reports whether a line is the BEGIN or END delimiter of an entry.
+func isDelimiter(line, delimiter string) bool {
+	return strings.EqualFold(strings.TrimSpace(line), delimiter+":VCARD")
+}
+
+// builder builds a [domain.Contact] from the properties of an entry.
+type builder struct {
+	contact domain.Contact
+
+	formatted string
+	named     bool
+	prefs     [3][]int // PREF parameters of emails, phones and addresses
+}
+
+func (b *builder) property(line string) error {
+	name, params, value, err := parseLine(line)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "VERSION":
+		if value != "3.0" && value != "4.0" {
+			return fmt.Errorf("unsupported version %q", value)
+		}
+	case "UID":
+		if s, ok := cutPrefixFold(value, "urn:uuid:"); ok {
+			if id, err := uuid.Parse(s); err == nil {
+				b.contact.ID = domain.ContactID{UUID: id}
+			}
+		}
+	case "FN":
+		b.formatted = unescape(value)
+	case "N":
+		parts := split(value, ';')
+		b.contact.Lastname = unescape(parts[0])
+		if len(parts) > 1 {
+			b.contact.Firstname = unescape(parts[1])
+		}
+		b.named = true
+	case "BDAY":
+		b.contact.Birthday, err = parseDate(params, value)
+		if err != nil {
+			return err
+		}
+	case "EMAIL":
+		label, pref := labelPref(params)
+		b.contact.Emails = append(b.contact.Emails, domain.Email{Address: unescape(value), Label: label})
+		b.prefs[0] = append(b.prefs[0], pref)
+	case "TEL":
+		number, _ := cutPrefixFold(value, "tel:")
+		number, _, _ = strings.Cut(number, ";") // drops URI parameters, e.g. ";ext=123"
+		label, pref := labelPref(params)
+		b.contact.Phones = append(b.contact.Phones, domain.Phone{Number: unescape(number), Label: label})
+		b.prefs[1] = append(b.prefs[1], pref)
+	case "ADR":
+		parts := split(value, ';')
+		for len(parts) < 7 { //nolint: mnd // post office box, extended address, street, locality, region, code, country
+			parts = append(parts, "")
+		}
+		label, pref := labelPref(params)
+		b.contact.Addresses = append(b.contact.Addresses, domain.Address{
+			Street:     unescape(parts[2]),
+			Locality:   unescape(parts[3]),
+			Region:     unescape(parts[4]),
+			PostalCode: unescape(parts[5]),
+			Country:    unescape(parts[6]),
+			Label:      label,
+		})
+		b.prefs[2] = append(b.prefs[2], pref)
+	}
+	return nil
+}
+
+// finish completes the contact once every property is read.
+//
+// Names default to the first and last words of FN, and the most preferred detail of each kind is primary.
+func (b *builder) finish() error {
+	c := &b.contact
+	if !b.named || (c.Firstname == "" && c.Lastname == "") {
+		words := strings.Fields(b.formatted)
+		if len(words) == 0 {
+			return errors.New("missing N and FN")
+		}
+		c.Firstname = words[0]
+		c.Lastname = strings.Join(words[1:], " ")
+	}
+
+	for kind, prefs := range b.prefs {
+		best := -1
+		for n, pref := range prefs {
+			if pref > 0 && (best < 0 || pref < prefs[best]) {
+				best = n
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		switch kind {
+		case 0:
+			c.Emails[best].Primary = true
+		case 1:
+			c.Phones[best].Primary = true
+		case 2: //nolint: mnd // addresses
+			c.Addresses[best].Primary = true
+		}
+	}
+	return nil
+}
+
+// parseLine splits a content line into its name, upper-cased and without group, its parameters and its value.
+//
+// Parameter names are upper-cased and parameters without names, e.g. vCard 3.0 "TEL;HOME:...", are types.
+func parseLine(line string) (string, map[string][]string, string, error) {
+	colon, quoted := -1, false
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", errors.New("missing ':' in content line")
+	}
+
+	fields := splitQuoted(line[:colon], ';')
+	name := strings.ToUpper(strings.TrimSpace(fields[0]))
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	if name == "" {
+		return "", nil, "", errors.New("missing property name")
+	}
+
+	params := map[string][]string{}
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			key, value = "TYPE", key
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		for _, v := range splitQuoted(value, ',') {
+			params[key] = append(params[key], strings.Trim(strings.TrimSpace(v), `"`))
+		}
+	}
+	return name, params, line[colon+1:], nil
+}
+
+// splitQuoted splits a string on the separators outside double quotes.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// labelPref returns the label and preference of a detail from its TYPE and PREF parameters, zero if not preferred.
+//
+// The vCard 3.0 "pref" type is a preference of 1.
+func labelPref(params map[string][]string) (domain.Label, int) {
+	label, pref := domain.LabelOther, 0
+	for _, typ := range params["TYPE"] {
+		switch strings.ToLower(typ) {
+		case "home":
+			label = domain.LabelHome
+		case "work":
+			label = domain.LabelWork
+		case "pref":
+			pref = 1
+		}
+	}
+	if values := params["PREF"]; len(values) > 0 {
+		if n, err := strconv.Atoi(values[0]); err == nil && n > 0 {
+			pref = n
+		}
+	}
+	return label, pref
+}
+
+// parseDate parses a BDAY value, ignoring its time if any.
+func parseDate(params map[string][]string, value string) (time.Time, error) {
+	for _, v := range params["VALUE"] {
+		if strings.EqualFold(v, "text") {
+			return time.Time{}, errors.New("text birthdays are not supported")
+		}
+	}
+	if strings.HasPrefix(value, "--") {
+		return time.Time{}, errors.New("birthdays without year are not supported")
+	}
+	date, _, _ := strings.Cut(value, "T")
+	for _, layout := range []string{"20060102", time.DateOnly} {
+		t, err := time.Parse(layout, date)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid birthday %q", value)
+}
+
+// cutPrefixFold is [strings.CutPrefix] ignoring case, returning s as is if it lacks the prefix.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
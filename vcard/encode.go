@@ -0,0 +1,62 @@
+package vcard
+
+import (
+	"io"
+	"strings"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Encoder writes vCard 4.0 entries to a stream.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an [Encoder] writing to w.
+func NewEncoder(w io.Writer) *Encoder { return &Encoder{w: w} }
+
+// Encode writes a [domain.Contact] as a vCard entry, with an UID unless its ID is zero.
+func (e *Encoder) Encode(c *domain.Contact) error {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCARD\r\n")
+	b.WriteString("VERSION:4.0\r\n")
+	if c.ID != (domain.ContactID{}) {
+		b.WriteString(fold("UID:urn:uuid:" + c.ID.String()))
+	}
+	b.WriteString(fold("FN:" + escaper.Replace(strings.TrimSpace(c.Firstname+" "+c.Lastname))))
+	b.WriteString(fold("N:" + escaper.Replace(c.Lastname) + ";" + escaper.Replace(c.Firstname) + ";;;"))
+	if !c.Birthday.IsZero() {
+		b.WriteString("BDAY:" + c.Birthday.Format("20060102") + "\r\n")
+	}
+	for _, email := range c.Emails {
+		b.WriteString(fold("EMAIL" + params(email.Label, email.Primary) + ":" + escaper.Replace(email.Address)))
+	}
+	for _, phone := range c.Phones {
+		b.WriteString(fold("TEL;VALUE=uri" + params(phone.Label, phone.Primary) + ":tel:" + phone.Number))
+	}
+	for _, a := range c.Addresses {
+		b.WriteString(fold("ADR" + params(a.Label, a.Primary) + ":;;" + strings.Join([]string{
+			escaper.Replace(a.Street),
+			escaper.Replace(a.Locality),
+			escaper.Replace(a.Region),
+			escaper.Replace(a.PostalCode),
+			escaper.Replace(a.Country),
+		}, ";")))
+	}
+	b.WriteString("END:VCARD\r\n")
+
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// params returns the TYPE and PREF parameters of a detail.
+func params(l domain.Label, primary bool) string {
+	var s string
+	if l == domain.LabelHome || l == domain.LabelWork {
+		s += ";TYPE=" + string(l)
+	}
+	if primary {
+		s += ";PREF=1"
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+// Package vcard encodes and decodes [domain.Contact] objects as vCard (RFC 6350) entries.
+//
+// Names map to the N and FN properties, the birthday to BDAY, and details to EMAIL, TEL and ADR
+// properties: their home and work labels map to the TYPE parameter and their primary flag to PREF=1.
+package vcard
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MediaType is the media type of vCard streams.
+const MediaType = "text/vcard"
+
+// maxLineLength is the length in octets after which content lines are folded.
+const maxLineLength = 75
+
+// escaper escapes text values.
+var escaper = strings.NewReplacer( //nolint: gochecknoglobals // read-only table
+	`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`,
+)
+
+// unescape reverses [escaper], leaving unknown escapes as is.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		case '\\', ',', ';':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// split splits a compound value on its unescaped separators.
+func split(s string, sep byte) []string {
+	var (
+		parts []string
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// fold folds a content line at [maxLineLength] octets, without splitting UTF-8 sequences.
+func fold(line string) string {
+	if len(line) <= maxLineLength {
+		return line + "\r\n"
+	}
+	var b strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		b.WriteString(line[:n])
+		b.WriteString("\r\n ")
+		line = line[n:]
+		limit = maxLineLength - 1 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
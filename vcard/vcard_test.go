@@ -0,0 +1,130 @@
+package vcard_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/vcard"
+)
+
+func TestRoundTrip(t *testing.T) {
+	want := &domain.Contact{
+		ID:        domain.ContactID{UUID: uuid.New()},
+		Firstname: "Zoë",
+		Lastname:  "Smith; Jr, " + strings.Repeat("é", 40),
+		Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		Emails: []domain.Email{
+			{Address: "zoe@example.com", Label: domain.LabelOther},
+			{Address: "zsmith@example.com", Label: domain.LabelWork, Primary: true},
+		},
+		Phones: []domain.Phone{{Number: "+33123456789", Label: domain.LabelHome}},
+		Addresses: []domain.Address{{
+			Street:     "1 rue de Rivoli\nBâtiment B",
+			Locality:   "Paris",
+			PostalCode: "75001",
+			Country:    "FR",
+			Label:      domain.LabelHome,
+			Primary:    true,
+		}},
+	}
+
+	var b strings.Builder
+	err := vcard.NewEncoder(&b).Encode(want)
+	if err != nil {
+		t.Fatal("Encode:", err)
+	}
+	for line := range strings.SplitSeq(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+
+	d := vcard.NewDecoder(strings.NewReader(b.String()))
+	got, err := d.Decode()
+	if err != nil {
+		t.Fatal("Decode:", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v\nfrom\n%s", got, want, b.String())
+	}
+	if _, err := d.Decode(); !errors.Is(err, io.EOF) {
+		t.Error("Decode after the last card got", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	const stream = "" +
+		"BEGIN:VCARD\n" +
+		"VERSION:3.0\n" +
+		"FN:John Smith\n" +
+		"BDAY:1999-12-31\n" +
+		"item1.EMAIL;TYPE=INTERNET,HOME:john@example.com\n" +
+		"TEL;WORK;TYPE=pref:01 23\n" +
+		" 45 67 89\n" +
+		"END:VCARD\n" +
+		"\n" +
+		"BEGIN:VCARD\n" +
+		"VERSION:4.0\n" +
+		"N:Doe;Jane;;;\n" +
+		"BDAY;VALUE=text:circa 1800\n" +
+		"END:VCARD\n" +
+		"garbage\n" +
+		"BEGIN:VCARD\n" +
+		"N:Roe;Richard;;;\n" +
+		"BEGIN:VCARD\n" +
+		"VERSION:4.0\n" +
+		"N:Doe;John;;;\n" +
+		"EMAIL;PREF=2:john@example.com\n" +
+		"EMAIL;PREF=1:jdoe@example.com\n" +
+		"END:VCARD\n" +
+		"BEGIN:VCARD\n" +
+		"N:Moe;Mary;;;\n"
+
+	d := vcard.NewDecoder(strings.NewReader(stream))
+	for n, want := range []struct {
+		contact *domain.Contact
+		line    int // of the error, if any
+	}{
+		{contact: &domain.Contact{
+			Firstname: "John",
+			Lastname:  "Smith",
+			Birthday:  time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC),
+			Emails:    []domain.Email{{Address: "john@example.com", Label: domain.LabelHome}},
+			Phones:    []domain.Phone{{Number: "01 2345 67 89", Label: domain.LabelWork, Primary: true}},
+		}},
+		{line: 13},
+		{line: 15},
+		{line: 18},
+		{contact: &domain.Contact{
+			Firstname: "John",
+			Lastname:  "Doe",
+			Emails: []domain.Email{
+				{Address: "john@example.com", Label: domain.LabelOther},
+				{Address: "jdoe@example.com", Label: domain.LabelOther, Primary: true},
+			},
+		}},
+		{line: 25},
+	} {
+		got, err := d.Decode()
+		if want.contact != nil {
+			if err != nil || !reflect.DeepEqual(got, want.contact) {
+				t.Errorf("card %d got %+v, %v, want %+v", n+1, got, err, want.contact)
+			}
+			continue
+		}
+		var verr *vcard.Error
+		if !errors.As(err, &verr) || verr.Card != n+1 || verr.Line != want.line {
+			t.Errorf("card %d got %v, want an error at line %d", n+1, err, want.line)
+		}
+	}
+	if _, err := d.Decode(); !errors.Is(err, io.EOF) {
+		t.Error("Decode after the last card got", err)
+	}
+}
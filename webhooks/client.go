@@ -0,0 +1,68 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrAddress is the error of deliveries to addresses that are not public.
+var ErrAddress = errors.New("webhooks: address not public")
+
+// NewClient returns the HTTP client of a [Dispatcher] with a timeout, only connecting to public addresses
+// for subscribers not to reach the private network of the service, and not following redirects for deliveries
+// to only go to the subscribed URLs.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Control: dialPublic}
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint: errcheck // always true
+	transport.Proxy = nil                                        // a proxy would connect in our place
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		Timeout:       timeout,
+	}
+}
+
+// special are the special-purpose ranges of global unicast addresses that are not public (RFC 6890),
+// or that embed IPv4 addresses which could be private.
+var special = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space (CGNAT)
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+}
+
+// dialPublic is a [net.Dialer] Control function failing with [ErrAddress] on connections to addresses
+// that are not global unicast, private or in the [special] ranges, checked once resolved.
+func dialPublic(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrAddress, addr)
+	}
+	for _, prefix := range special {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s in %s", ErrAddress, addr, prefix)
+		}
+	}
+	return nil
+}
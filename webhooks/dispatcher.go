@@ -0,0 +1,295 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Dispatcher defaults.
+const (
+	DefaultMaxAttempts = 10
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = time.Hour
+	DefaultTimeout     = 10 * time.Second
+	DefaultWorkers     = 8
+	DefaultRetention   = 7 * 24 * time.Hour
+)
+
+// dispatchBatchSize is the number of events or deliveries handled at once.
+const dispatchBatchSize = 100
+
+// Dispatcher delivers the events of an outbox to the subscriptions of their tenant accepting them.
+//
+// Delivery is at least once: payloads are POSTed as JSON with the ID of their event in a Webhook-Id header
+// and their signature in a Webhook-Signature header, see [Sign]. Failed deliveries, those not answered with
+// a 2xx status, are retried with an exponential backoff, and dead-lettered once out of attempts.
+//
+// Deliveries are attempted by a bounded number of workers, each taking the deliveries due to a subscription
+// in order, for a slow or failing subscriber to only delay its own deliveries. Dead letters are kept for
+// a retention, then purged.
+type Dispatcher struct {
+	Outbox       domain.OutboxStore
+	Store        Store
+	Client       *http.Client                 // defaults to [NewClient] with a [DefaultTimeout]
+	Payload      func(*domain.Event) any      // body of deliveries, the event itself if nil
+	MaxAttempts  int                          // before dead-lettering deliveries, defaults to [DefaultMaxAttempts]
+	Backoff      time.Duration                // before the first retry, doubled on the others, see [DefaultBackoff]
+	MaxBackoff   time.Duration                // defaults to [DefaultMaxBackoff]
+	Workers      int                          // attempting deliveries concurrently, defaults to [DefaultWorkers]
+	Retention    time.Duration                // of dead letters, defaults to [DefaultRetention]
+	ErrorHandler func(context.Context, error) // reports failed deliveries and store accesses, if set, concurrently
+	Now          func() time.Time             // defaults to [time.Now]
+}
+
+func (d *Dispatcher) handle(ctx context.Context, err error) {
+	if d.ErrorHandler != nil {
+		d.ErrorHandler(ctx, err)
+	}
+}
+
+func (d *Dispatcher) now() time.Time {
+	if d.Now == nil {
+		return time.Now()
+	}
+	return d.Now()
+}
+
+// Run dispatches events every interval until the context is done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := d.Dispatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.handle(ctx, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch turns the events of the outbox into deliveries, attempts the deliveries due,
+// then purges the dead letters past their retention.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	for {
+		events, err := d.Outbox.OutboxFetch(ctx, dispatchBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			break
+		}
+		err = d.fanOut(ctx, events)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		due, err := d.Store.DeliveriesDue(ctx, d.now(), dispatchBatchSize)
+		if err != nil {
+			return err
+		}
+		complete, err := d.deliver(ctx, due)
+		if err != nil {
+			return err
+		}
+		if !complete || len(due) < dispatchBatchSize {
+			break
+		}
+	}
+
+	retention := d.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	_, err := d.Store.DeliveriesPurgeBefore(ctx, d.now().Add(-retention))
+	return err
+}
+
+// deliver attempts deliveries with a pool of workers, each taking the queue of deliveries of a subscription.
+// Queues stop at their first failed attempt for the order of deliveries to be kept, and are reported incomplete.
+func (d *Dispatcher) deliver(ctx context.Context, deliveries []*Delivery) (bool, error) {
+	var (
+		queues = make(map[string][]*Delivery)
+		order  []string
+	)
+	for _, delivery := range deliveries {
+		if _, ok := queues[delivery.Subscription]; !ok {
+			order = append(order, delivery.Subscription)
+		}
+		queues[delivery.Subscription] = append(queues[delivery.Subscription], delivery)
+	}
+
+	workers := d.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errs     []error
+		complete = true
+		next     = make(chan []*Delivery)
+	)
+	for range min(workers, len(order)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for queue := range next {
+				for n, delivery := range queue {
+					ok, err := d.attempt(ctx, delivery)
+					if err == nil && ok {
+						continue
+					}
+					mu.Lock()
+					errs = append(errs, err)
+					complete = complete && n == len(queue)-1
+					mu.Unlock()
+					break
+				}
+			}
+		}()
+	}
+	for _, id := range order {
+		next <- queues[id]
+	}
+	close(next)
+	wg.Wait()
+	return complete, errors.Join(errs...)
+}
+
+// fanOut records the deliveries of events to their subscriptions, then deletes them from the outbox.
+// Deliveries are repeated if the events cannot be deleted.
+func (d *Dispatcher) fanOut(ctx context.Context, events []*domain.Event) error {
+	var (
+		deliveries []*Delivery
+		ids        = make([]string, len(events))
+		now        = d.now()
+	)
+	for n, e := range events {
+		ids[n] = e.ID
+		subscriptions, err := d.Store.WebhooksList(domain.WithTenant(ctx, e.Tenant))
+		if err != nil {
+			return err
+		}
+		for _, s := range subscriptions {
+			if s.Accepts(e.Type) {
+				deliveries = append(deliveries, &Delivery{
+					ID:           uuid.NewString(),
+					Subscription: s.ID,
+					Event:        e,
+					Next:         now,
+				})
+			}
+		}
+	}
+
+	err := d.Store.DeliveriesAppend(ctx, deliveries...)
+	if err != nil {
+		return err
+	}
+	return d.Outbox.OutboxDelete(ctx, ids...)
+}
+
+// attempt attempts a delivery, records its outcome and reports whether it succeeded.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery) (bool, error) {
+	ctx = domain.WithTenant(ctx, delivery.Event.Tenant)
+	s, err := d.Store.WebhooksGet(ctx, delivery.Subscription)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true, d.Store.DeliveriesDelete(ctx, delivery.ID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = d.post(ctx, s, delivery.Event)
+	if err == nil {
+		return true, d.Store.DeliveriesDelete(ctx, delivery.ID)
+	}
+
+	d.handle(ctx, fmt.Errorf("delivery %s of event %s to %s: %w", delivery.ID, delivery.Event.ID, s.URL, err))
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	maxAttempts := d.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if delivery.Attempts >= maxAttempts {
+		delivery.Dead = true
+		delivery.Next = d.now()
+	} else {
+		delivery.Next = d.now().Add(d.backoff(delivery.Attempts))
+	}
+	return false, d.Store.DeliveriesUpdate(ctx, delivery)
+}
+
+// backoff returns the delay before the next attempt of a delivery attempted a number of times.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff, maxBackoff := d.Backoff, d.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	for range attempts - 1 {
+		if backoff >= maxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// post posts the payload of an event to a subscription.
+func (d *Dispatcher) post(ctx context.Context, s *Subscription, e *domain.Event) error {
+	var payload any = e
+	if d.Payload != nil {
+		payload = d.Payload(e)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, e.ID)
+	req.Header.Set(SignatureHeader, Sign(s.Secret, d.now(), body))
+
+	client := d.Client
+	if client == nil {
+		client = NewClient(DefaultTimeout)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16)) //nolint: errcheck,gosec // drains for reuse only
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("status " + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
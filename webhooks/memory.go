@@ -0,0 +1,182 @@
+package webhooks
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Memory is an in-memory [Store].
+//
+// The zero value is ready to use.
+type Memory struct {
+	mu            sync.Mutex
+	subscriptions []memorySubscription
+	deliveries    []*Delivery
+}
+
+type memorySubscription struct {
+	tenant domain.TenantID
+	*Subscription
+}
+
+var _ Store = (*Memory)(nil)
+
+func cloneSubscription(s *Subscription) *Subscription {
+	clone := *s
+	clone.Types = slices.Clone(s.Types)
+	return &clone
+}
+
+func cloneDelivery(d *Delivery) *Delivery {
+	clone := *d
+	event := *d.Event
+	if d.Event.Contact != nil {
+		event.Contact = d.Event.Contact.Clone()
+	}
+	clone.Event = &event
+	return &clone
+}
+
+// lookup returns the index of a subscription of the context's tenant.
+func (m *Memory) lookup(ctx context.Context, id string) (int, error) {
+	n := slices.IndexFunc(m.subscriptions, func(s memorySubscription) bool {
+		return s.ID == id && s.tenant == domain.TenantFrom(ctx)
+	})
+	if n < 0 {
+		return 0, domain.ErrNotFound
+	}
+	return n, nil
+}
+
+func (m *Memory) WebhooksCreate(ctx context.Context, s *Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = uuid.NewString()
+	m.subscriptions = append(m.subscriptions, memorySubscription{domain.TenantFrom(ctx), cloneSubscription(s)})
+	return nil
+}
+
+func (m *Memory) WebhooksGet(ctx context.Context, id string) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, err := m.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneSubscription(m.subscriptions[n].Subscription), nil
+}
+
+func (m *Memory) WebhooksList(ctx context.Context) ([]*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var subscriptions []*Subscription
+	for _, s := range m.subscriptions {
+		if s.tenant == domain.TenantFrom(ctx) {
+			subscriptions = append(subscriptions, cloneSubscription(s.Subscription))
+		}
+	}
+	return subscriptions, nil
+}
+
+func (m *Memory) WebhooksDelete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, err := m.lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	m.subscriptions = slices.Delete(m.subscriptions, n, n+1)
+	m.deliveries = slices.DeleteFunc(m.deliveries, func(d *Delivery) bool { return d.Subscription == id })
+	return nil
+}
+
+func (m *Memory) DeliveriesAppend(_ context.Context, deliveries ...*Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range deliveries {
+		m.deliveries = append(m.deliveries, cloneDelivery(d))
+	}
+	return nil
+}
+
+func (m *Memory) DeliveriesDue(_ context.Context, t time.Time, limit int) ([]*Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*Delivery
+	for _, d := range m.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if !d.Dead && !d.Next.After(t) {
+			due = append(due, cloneDelivery(d))
+		}
+	}
+	return due, nil
+}
+
+func (m *Memory) DeliveriesUpdate(_ context.Context, d *Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := slices.IndexFunc(m.deliveries, func(stored *Delivery) bool { return stored.ID == d.ID })
+	if n < 0 {
+		return domain.ErrNotFound
+	}
+	m.deliveries[n] = cloneDelivery(d)
+	return nil
+}
+
+func (m *Memory) DeliveriesDelete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = slices.DeleteFunc(m.deliveries, func(d *Delivery) bool { return d.ID == id })
+	return nil
+}
+
+func (m *Memory) DeliveriesDead(ctx context.Context, id string) ([]*Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var dead []*Delivery
+	for _, d := range m.deliveries {
+		if d.Subscription == id && d.Dead {
+			dead = append(dead, cloneDelivery(d))
+		}
+	}
+	return dead, nil
+}
+
+func (m *Memory) DeliveriesRevive(ctx context.Context, id string, t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.lookup(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	var revived int
+	for n, d := range m.deliveries {
+		if d.Subscription == id && d.Dead {
+			d = cloneDelivery(d)
+			d.Dead, d.Attempts, d.Next = false, 0, t
+			m.deliveries[n] = d
+			revived++
+		}
+	}
+	return revived, nil
+}
+
+func (m *Memory) DeliveriesPurgeBefore(_ context.Context, t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.deliveries)
+	m.deliveries = slices.DeleteFunc(m.deliveries, func(d *Delivery) bool { return d.Dead && d.Next.Before(t) })
+	return n - len(m.deliveries), nil
+}
@@ -0,0 +1,147 @@
+// Package webhooks delivers [domain.Event] objects to the URLs subscribed to them, with signed payloads.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Subscription subscribes a URL to events of the tenant it belongs to.
+type Subscription struct {
+	ID      string
+	URL     string
+	Secret  string             // signs the payloads delivered to the URL, kept as is by stores to do so
+	Types   []domain.EventType // of the events delivered, all if empty
+	Created time.Time
+}
+
+// Accepts reports whether events of a type are delivered to the subscription.
+func (s *Subscription) Accepts(typ domain.EventType) bool {
+	return len(s.Types) == 0 || slices.Contains(s.Types, typ)
+}
+
+// Validate checks the subscription delivers known events to an absolute HTTP URL,
+// failing with [domain.ErrInvalid] and [domain.FieldError] details otherwise.
+func (s *Subscription) Validate() error {
+	var errs []error
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, &domain.FieldError{Field: "url", Value: s.URL, Err: errors.New("not an absolute HTTP URL")})
+	}
+	for n, typ := range s.Types {
+		if !slices.Contains(domain.EventTypes, typ) {
+			errs = append(errs, &domain.FieldError{
+				Field: fmt.Sprintf("types[%d]", n),
+				Value: typ,
+				Err:   errors.New("unknown event type"),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(append([]error{domain.ErrInvalid}, errs...)...)
+	}
+	return nil
+}
+
+// Delivery is the delivery of an [domain.Event] to a [Subscription], retried until it succeeds
+// or runs out of attempts and is dead-lettered.
+type Delivery struct {
+	ID           string
+	Subscription string // ID of the [Subscription]
+	Event        *domain.Event
+	Attempts     int
+	Next         time.Time // of the next attempt, or of the dead-lettering
+	LastError    string
+	Dead         bool
+}
+
+// Store keeps subscriptions, scoped to the tenant given by [domain.TenantFrom], and their deliveries.
+type Store interface {
+	// WebhooksCreate records a [Subscription], setting its ID.
+	WebhooksCreate(context.Context, *Subscription) error
+	// WebhooksGet retrieves a [Subscription] given its ID, failing with [domain.ErrNotFound].
+	WebhooksGet(context.Context, string) (*Subscription, error)
+	// WebhooksList retrieves the subscriptions, oldest first.
+	WebhooksList(context.Context) ([]*Subscription, error)
+	// WebhooksDelete deletes a [Subscription] and its deliveries given its ID, failing with [domain.ErrNotFound].
+	WebhooksDelete(context.Context, string) error
+
+	// DeliveriesAppend records deliveries.
+	DeliveriesAppend(context.Context, ...*Delivery) error
+	// DeliveriesDue retrieves up to a number of deliveries due at a time and not dead-lettered, whatever their tenant.
+	DeliveriesDue(context.Context, time.Time, int) ([]*Delivery, error)
+	// DeliveriesUpdate records the attempts of a [Delivery].
+	DeliveriesUpdate(context.Context, *Delivery) error
+	// DeliveriesDelete deletes a [Delivery] given its ID, e.g. once successful.
+	DeliveriesDelete(context.Context, string) error
+	// DeliveriesDead retrieves the dead-lettered deliveries of a [Subscription] given its ID,
+	// failing with [domain.ErrNotFound].
+	DeliveriesDead(context.Context, string) ([]*Delivery, error)
+	// DeliveriesRevive schedules the dead-lettered deliveries of a [Subscription] given its ID again at a time,
+	// resetting their attempts, and returns their number. It fails with [domain.ErrNotFound].
+	DeliveriesRevive(context.Context, string, time.Time) (int, error)
+	// DeliveriesPurgeBefore permanently deletes the deliveries dead-lettered before a time, whatever their tenant,
+	// and returns their number.
+	DeliveriesPurgeBefore(context.Context, time.Time) (int, error)
+}
+
+// Headers of deliveries.
+const (
+	IDHeader        = "Webhook-Id"        // ID of the delivered event, for consumers to ignore repeated deliveries
+	SignatureHeader = "Webhook-Signature" // see [Sign]
+)
+
+// ErrSignature is the error of payloads whose signature cannot be verified.
+var ErrSignature = errors.New("webhooks: invalid signature")
+
+// Sign returns the signature of a payload sent at a time with a secret, as sent in the Webhook-Signature header:
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">".
+func Sign(secret string, t time.Time, payload []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, payload))
+}
+
+func mac(secret, ts string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts + "."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// Verify checks a signature returned by [Sign] for a payload, sent at most a tolerance ago unless zero,
+// failing with [ErrSignature].
+func Verify(secret, signature string, payload []byte, tolerance time.Duration) error {
+	var ts, v1 string
+	for part := range strings.SplitSeq(signature, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			v1 = v
+		}
+	}
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", ErrSignature)
+	}
+	if tolerance > 0 && time.Since(time.Unix(sent, 0)).Abs() > tolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrSignature)
+	}
+	got, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(got, mac(secret, ts, payload)) {
+		return fmt.Errorf("%w: mismatch", ErrSignature)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/webhooks"
+	"github.com/rlibaert/service-example-go/webhooks/webhookstest"
+)
+
+func TestMemory(t *testing.T) {
+	webhookstest.TestStore(t, &webhooks.Memory{})
+}
+
+func TestSignature(t *testing.T) {
+	payload := []byte(`{"type":"contact.created"}`)
+	signature := webhooks.Sign("secret", time.Now(), payload)
+
+	if err := webhooks.Verify("secret", signature, payload, time.Minute); err != nil {
+		t.Error("Verify:", err)
+	}
+	for name, err := range map[string]error{
+		"secret":  webhooks.Verify("other", signature, payload, time.Minute),
+		"payload": webhooks.Verify("secret", signature, []byte(`{}`), time.Minute),
+		"expired": webhooks.Verify("secret", webhooks.Sign("secret", time.Now().Add(-time.Hour), payload),
+			payload, time.Minute),
+		"malformed": webhooks.Verify("secret", "v1=00", payload, 0),
+	} {
+		if !errors.Is(err, webhooks.ErrSignature) {
+			t.Errorf("Verify with a wrong %s returned %v", name, err)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var (
+		fail      atomic.Bool
+		delivered = make(chan *domain.Event, 10)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// the dispatcher's clock is faked, the timestamp cannot be checked
+		if err := webhooks.Verify("secret", r.Header.Get(webhooks.SignatureHeader), body, 0); err != nil {
+			t.Error("delivery with an invalid signature:", err)
+		}
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var e domain.Event
+		if err := json.Unmarshal(body, &e); err != nil || e.ID != r.Header.Get(webhooks.IDHeader) {
+			t.Error("delivery of an unexpected payload:", string(body), err)
+		}
+		delivered <- &e
+	}))
+	defer server.Close()
+
+	outbox := stores.MustNewMock()
+	store := &webhooks.Memory{}
+	now := time.Now()
+	dispatcher := &webhooks.Dispatcher{
+		Outbox:      outbox,
+		Store:       store,
+		Client:      server.Client(), // the default one does not deliver to loopback addresses
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+		Now:         func() time.Time { return now },
+	}
+	dispatch := func() {
+		t.Helper()
+		if err := dispatcher.Dispatch(t.Context()); err != nil {
+			t.Fatal("Dispatch:", err)
+		}
+	}
+
+	acme := domain.WithTenant(t.Context(), "acme")
+	subscribe := func(ctx context.Context, types ...domain.EventType) *webhooks.Subscription {
+		s := &webhooks.Subscription{URL: server.URL, Secret: "secret", Types: types}
+		if err := store.WebhooksCreate(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s := subscribe(acme, domain.ContactCreated)
+	subscribe(acme, domain.ContactDeleted)
+	subscribe(t.Context())
+
+	service := &domain.ServiceStore{Store: outbox}
+	id, err := service.ContactsCreate(acme, domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail.Store(true)
+	dispatch()
+	if events, _ := outbox.OutboxFetch(t.Context(), 10); len(events) != 0 {
+		t.Error("Dispatch left events in the outbox")
+	}
+	dispatch()
+	now = now.Add(time.Minute)
+	dispatch()
+	dead, _ := store.DeliveriesDead(acme, s.ID)
+	if len(dead) != 0 {
+		t.Fatal("delivery dead-lettered before running out of attempts")
+	}
+
+	fail.Store(false)
+	now = now.Add(2 * time.Minute)
+	dispatch()
+	select {
+	case e := <-delivered:
+		if e.Type != domain.ContactCreated || e.ContactID != id || e.Tenant != "acme" {
+			t.Errorf("delivered %+v", e)
+		}
+	default:
+		t.Fatal("no delivery once the subscriber recovered")
+	}
+
+	fail.Store(true)
+	_, err = service.ContactsCreate(acme, domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		dispatch()
+		now = now.Add(time.Hour)
+	}
+	dead, _ = store.DeliveriesDead(acme, s.ID)
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastError != "status 503" {
+		t.Fatalf("DeliveriesDead got %+v, want a delivery out of attempts", dead)
+	}
+
+	fail.Store(false)
+	if n, err := store.DeliveriesRevive(acme, s.ID, now); err != nil || n != 1 {
+		t.Fatal("DeliveriesRevive returned", n, err)
+	}
+	dispatch()
+	if len(delivered) != 1 {
+		t.Error("got", len(delivered), "deliveries of a revived dead letter, want 1")
+	}
+
+	fail.Store(true)
+	_, err = service.ContactsCreate(acme, domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		dispatch()
+		now = now.Add(time.Hour)
+	}
+	now = now.Add(webhooks.DefaultRetention)
+	dispatch()
+	if dead, _ = store.DeliveriesDead(acme, s.ID); len(dead) != 0 {
+		t.Errorf("DeliveriesDead got %+v past their retention", dead)
+	}
+}
+
+func TestDispatcherPrivate(t *testing.T) {
+	var called atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called.Store(true) }))
+	defer server.Close()
+
+	outbox := stores.MustNewMock()
+	store := &webhooks.Memory{}
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	dispatcher := &webhooks.Dispatcher{
+		Outbox: outbox,
+		Store:  store,
+		ErrorHandler: func(_ context.Context, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	}
+
+	err := store.WebhooksCreate(t.Context(), &webhooks.Subscription{URL: server.URL, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := &domain.ServiceStore{Store: outbox}
+	if _, err := service.ContactsCreate(t.Context(), domaintest.NewContact()); err != nil {
+		t.Fatal(err)
+	}
+	if err := dispatcher.Dispatch(t.Context()); err != nil {
+		t.Fatal("Dispatch:", err)
+	}
+	if called.Load() || len(errs) != 1 || !errors.Is(errs[0], webhooks.ErrAddress) {
+		t.Errorf("delivery to %s got errors %v, want %v", server.URL, errs, webhooks.ErrAddress)
+	}
+}
+
+func TestClient(t *testing.T) {
+	client := webhooks.NewClient(time.Second)
+	for _, addr := range []string{
+		"127.0.0.1",
+		"10.0.0.1",
+		"169.254.169.254",
+		"0.0.0.1",
+		"100.64.0.1",
+		"198.18.0.1",
+		"240.0.0.1",
+		"255.255.255.255",
+		"224.0.0.1",
+		"239.255.255.250",
+		"::1",
+		"fd00::1",
+		"fe80::1",
+		"ff0e::1",
+		"::ffff:10.0.0.1",
+		"::ffff:127.0.0.1",
+		"64:ff9b::a00:1",
+		"2002:a00:1::",
+	} {
+		url := "http://" + netip.AddrPortFrom(netip.MustParseAddr(addr), 80).String()
+		if _, err := client.Get(url); !errors.Is(err, webhooks.ErrAddress) {
+			t.Errorf("GET %s got error %v, want %v", url, err, webhooks.ErrAddress)
+		}
+	}
+}
+
+func TestDispatcherQueues(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.URL.Path]++
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	outbox := stores.MustNewMock()
+	store := &webhooks.Memory{}
+	dispatcher := &webhooks.Dispatcher{Outbox: outbox, Store: store, Client: server.Client(), Workers: 2}
+	for _, path := range []string{"/fail", "/ok"} {
+		err := store.WebhooksCreate(t.Context(), &webhooks.Subscription{URL: server.URL + path, Secret: "secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := &domain.ServiceStore{Store: outbox}
+	for range 3 {
+		if _, err := service.ContactsCreate(t.Context(), domaintest.NewContact()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dispatcher.Dispatch(t.Context()); err != nil {
+		t.Fatal("Dispatch:", err)
+	}
+
+	// the queue of the failing subscription stops at its first delivery, the other one goes on
+	if requests["/fail"] != 1 || requests["/ok"] != 3 {
+		t.Error("got requests", requests, "want 1 to /fail and 3 to /ok")
+	}
+}
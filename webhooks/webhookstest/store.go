@@ -0,0 +1,194 @@
+// Package webhookstest provides utilities for [webhooks] testing.
+package webhookstest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/webhooks"
+)
+
+// TestStore tests a [webhooks.Store] implementation.
+//
+// The store is shared by every subtest and may already contain subscriptions and deliveries.
+func TestStore(t *testing.T, store webhooks.Store) {
+	t.Run("Subscriptions", func(t *testing.T) { testSubscriptions(t, store) })
+	t.Run("Deliveries", func(t *testing.T) { testDeliveries(t, store) })
+}
+
+// create creates a subscription of a tenant.
+func create(t *testing.T, store webhooks.Store, tenant domain.TenantID) *webhooks.Subscription {
+	t.Helper()
+	s := &webhooks.Subscription{
+		URL:     "https://example.com/hooks/" + uuid.NewString(),
+		Secret:  "secret",
+		Types:   []domain.EventType{domain.ContactCreated},
+		Created: time.Now().Round(0),
+	}
+	err := store.WebhooksCreate(domain.WithTenant(t.Context(), tenant), s)
+	if err != nil {
+		t.Fatal("WebhooksCreate:", err)
+	}
+	if s.ID == "" {
+		t.Fatal("WebhooksCreate set no ID")
+	}
+	return s
+}
+
+func testSubscriptions(t *testing.T, store webhooks.Store) {
+	tenant := domain.TenantID(uuid.NewString())
+	ctx := domain.WithTenant(t.Context(), tenant)
+	first, second := create(t, store, tenant), create(t, store, tenant)
+
+	got, err := store.WebhooksGet(ctx, first.ID)
+	if err != nil {
+		t.Fatal("WebhooksGet:", err)
+	}
+	if got.URL != first.URL || got.Secret != first.Secret || !slices.Equal(got.Types, first.Types) ||
+		!got.Created.Equal(first.Created) {
+		t.Errorf("WebhooksGet got %+v, want %+v", got, first)
+	}
+	if _, err := store.WebhooksGet(t.Context(), first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("WebhooksGet from another tenant returned", err)
+	}
+
+	list, err := store.WebhooksList(ctx)
+	if err != nil {
+		t.Fatal("WebhooksList:", err)
+	}
+	var ids []string
+	for _, s := range list {
+		ids = append(ids, s.ID)
+	}
+	if want := []string{first.ID, second.ID}; !slices.Equal(ids, want) {
+		t.Error("WebhooksList got", ids, "want", want)
+	}
+
+	if err := store.WebhooksDelete(t.Context(), first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("WebhooksDelete from another tenant returned", err)
+	}
+	if err := store.WebhooksDelete(ctx, first.ID); err != nil {
+		t.Fatal("WebhooksDelete:", err)
+	}
+	if _, err := store.WebhooksGet(ctx, first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("WebhooksGet of a deleted subscription returned", err)
+	}
+	if err := store.WebhooksDelete(ctx, first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("WebhooksDelete of a deleted subscription returned", err)
+	}
+}
+
+func testDeliveries(t *testing.T, store webhooks.Store) {
+	tenant := domain.TenantID(uuid.NewString())
+	ctx := domain.WithTenant(t.Context(), tenant)
+	s := create(t, store, tenant)
+
+	now := time.Now()
+	delivery := func(next time.Time) *webhooks.Delivery {
+		return &webhooks.Delivery{
+			ID:           uuid.NewString(),
+			Subscription: s.ID,
+			Event: &domain.Event{
+				ID:        uuid.NewString(),
+				Type:      domain.ContactDeleted,
+				Tenant:    tenant,
+				ContactID: domain.ContactID{UUID: uuid.New()},
+			},
+			Next: next,
+		}
+	}
+	due, later := delivery(now.Add(-time.Second)), delivery(now.Add(time.Hour))
+	if err := store.DeliveriesAppend(t.Context(), due, later); err != nil {
+		t.Fatal("DeliveriesAppend:", err)
+	}
+
+	// the store may hold deliveries of other subtests, only ours are looked for
+	dueIDs := func() []string {
+		t.Helper()
+		deliveries, err := store.DeliveriesDue(t.Context(), now, 1<<20)
+		if err != nil {
+			t.Fatal("DeliveriesDue:", err)
+		}
+		var ids []string
+		for _, d := range deliveries {
+			if d.Subscription == s.ID {
+				if d.ID == due.ID && (d.Event.ID != due.Event.ID || d.Event.Tenant != tenant) {
+					t.Errorf("DeliveriesDue got %+v, want %+v", d.Event, due.Event)
+				}
+				ids = append(ids, d.ID)
+			}
+		}
+		return ids
+	}
+	if got := dueIDs(); !slices.Equal(got, []string{due.ID}) {
+		t.Error("DeliveriesDue got", got, "want", due.ID)
+	}
+
+	due.Attempts, due.LastError, due.Dead = 3, "status 500", true
+	if err := store.DeliveriesUpdate(t.Context(), due); err != nil {
+		t.Fatal("DeliveriesUpdate:", err)
+	}
+	if got := dueIDs(); len(got) != 0 {
+		t.Error("DeliveriesDue got dead-lettered deliveries", got)
+	}
+	dead, err := store.DeliveriesDead(ctx, s.ID)
+	if err != nil {
+		t.Fatal("DeliveriesDead:", err)
+	}
+	if len(dead) != 1 || dead[0].ID != due.ID || dead[0].Attempts != 3 || dead[0].LastError != "status 500" {
+		t.Errorf("DeliveriesDead got %+v, want %+v", dead, due)
+	}
+	if _, err := store.DeliveriesDead(t.Context(), s.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("DeliveriesDead from another tenant returned", err)
+	}
+
+	if _, err := store.DeliveriesRevive(t.Context(), s.ID, now); !errors.Is(err, domain.ErrNotFound) {
+		t.Error("DeliveriesRevive from another tenant returned", err)
+	}
+	if n, err := store.DeliveriesRevive(ctx, s.ID, now); err != nil || n != 1 {
+		t.Fatal("DeliveriesRevive returned", n, err)
+	}
+	if got := dueIDs(); !slices.Equal(got, []string{due.ID}) {
+		t.Error("DeliveriesDue after DeliveriesRevive got", got, "want", due.ID)
+	}
+
+	if err := store.DeliveriesDelete(t.Context(), due.ID); err != nil {
+		t.Fatal("DeliveriesDelete:", err)
+	}
+	if got := dueIDs(); len(got) != 0 {
+		t.Error("DeliveriesDue got deleted deliveries", got)
+	}
+
+	purged := delivery(now.Add(-time.Second))
+	purged.Dead = true
+	if err := store.DeliveriesAppend(t.Context(), purged); err != nil {
+		t.Fatal("DeliveriesAppend:", err)
+	}
+	for _, tc := range []struct {
+		before time.Time
+		want   int
+	}{{now.Add(-time.Minute), 1}, {now, 0}} {
+		if _, err := store.DeliveriesPurgeBefore(t.Context(), tc.before); err != nil {
+			t.Fatal("DeliveriesPurgeBefore:", err)
+		}
+		if got, _ := store.DeliveriesDead(ctx, s.ID); len(got) != tc.want {
+			t.Errorf("DeliveriesDead after DeliveriesPurgeBefore %v got %d, want %d", tc.before, len(got), tc.want)
+		}
+	}
+
+	if err := store.WebhooksDelete(ctx, s.ID); err != nil {
+		t.Fatal("WebhooksDelete:", err)
+	}
+	deliveries, err := store.DeliveriesDue(t.Context(), later.Next, 1<<20)
+	if err != nil {
+		t.Fatal("DeliveriesDue:", err)
+	}
+	if slices.ContainsFunc(deliveries, func(d *webhooks.Delivery) bool { return d.ID == later.ID }) {
+		t.Error("DeliveriesDue got a delivery of a deleted subscription")
+	}
+}
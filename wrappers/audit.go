@@ -0,0 +1,211 @@
+package wrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// StoreAuditor wraps a [domain.Store] that is a [domain.AuditStore] to record the changes to its contacts as
+// [domain.AuditEvent] objects, each in the same transaction as its change if the store is a [domain.AtomicStore],
+// functions of its ContactsAtomic method included.
+//
+// Changes fail if their event cannot be recorded, with [errors.ErrUnsupported] if the store keeps no history.
+// The changes of non-atomic stores are then applied nevertheless.
+type StoreAuditor struct {
+	Store     domain.Store
+	Now       func() time.Time             // defaults to [time.Now]
+	Actor     func(context.Context) string // subject of the caller, none by default
+	RequestID func(context.Context) string // ID of the request, none by default
+
+	atomic bool // whether the store is a transaction, changes being recorded in it
+}
+
+// atomically calls a function with the store, or with a transaction of it if it is a [domain.AtomicStore]
+// and not one already.
+func (store StoreAuditor) atomically(ctx context.Context, f func(domain.Store) error) error {
+	if atomic, ok := store.Store.(domain.AtomicStore); ok && !store.atomic {
+		called := false
+		err := atomic.ContactsAtomic(ctx, func(s domain.Store) error {
+			called = true
+			return f(s)
+		})
+		if called || !errors.Is(err, errors.ErrUnsupported) {
+			return err
+		}
+	}
+	return f(store.Store)
+}
+
+// record applies a change to a contact with a function and records its event, reading the contact before
+// updates and deletions, and after changes keeping it.
+func (store StoreAuditor) record(
+	ctx context.Context,
+	action domain.AuditAction,
+	id domain.ContactID,
+	f func(domain.Store) (domain.ContactID, error),
+) (domain.ContactID, error) {
+	err := store.atomically(ctx, func(s domain.Store) error {
+		var before, after *domain.Contact
+		if action == domain.AuditUpdate || action == domain.AuditDelete {
+			before, _ = s.ContactsGet(ctx, id) // failing changes are not recorded
+		}
+		var err error
+		id, err = f(s)
+		if err != nil {
+			return err
+		}
+		if action != domain.AuditDelete && action != domain.AuditPurge {
+			after, err = s.ContactsGet(ctx, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		audit, ok := s.(domain.AuditStore)
+		if !ok {
+			return fmt.Errorf("%w: no history kept", errors.ErrUnsupported)
+		}
+		now := time.Now
+		if store.Now != nil {
+			now = store.Now
+		}
+		var actor, requestID string
+		if store.Actor != nil {
+			actor = store.Actor(ctx)
+		}
+		if store.RequestID != nil {
+			requestID = store.RequestID(ctx)
+		}
+		return audit.AuditAppend(ctx, &domain.AuditEvent{
+			ContactID: id,
+			Action:    action,
+			Actor:     actor,
+			RequestID: requestID,
+			Time:      now(),
+			Changes:   domain.AuditDiff(before, after),
+		})
+	})
+	return id, err
+}
+
+func (store StoreAuditor) ContactsSet(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	return store.record(ctx, domain.AuditCreate, domain.ContactID{}, func(s domain.Store) (domain.ContactID, error) {
+		return s.ContactsSet(ctx, c)
+	})
+}
+
+func (store StoreAuditor) ContactsGet(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	return store.Store.ContactsGet(ctx, id)
+}
+
+func (store StoreAuditor) ContactsReset(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
+	_, err := store.record(ctx, domain.AuditUpdate, id, func(s domain.Store) (domain.ContactID, error) {
+		return id, s.ContactsReset(ctx, id, c)
+	})
+	return err
+}
+
+func (store StoreAuditor) ContactsDel(ctx context.Context, id domain.ContactID, version int) error {
+	_, err := store.record(ctx, domain.AuditDelete, id, func(s domain.Store) (domain.ContactID, error) {
+		return id, s.ContactsDel(ctx, id, version)
+	})
+	return err
+}
+
+func (store StoreAuditor) ContactsUndel(ctx context.Context, id domain.ContactID) error {
+	_, err := store.record(ctx, domain.AuditRestore, id, func(s domain.Store) (domain.ContactID, error) {
+		return id, s.ContactsUndel(ctx, id)
+	})
+	return err
+}
+
+func (store StoreAuditor) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	_, err := store.record(ctx, domain.AuditPurge, id, func(s domain.Store) (domain.ContactID, error) {
+		return id, s.ContactsPurge(ctx, id)
+	})
+	return err
+}
+
+// ContactsPurgeBefore does not record the purges of the trash, which concern every tenant.
+func (store StoreAuditor) ContactsPurgeBefore(ctx context.Context, before time.Time) (int, error) {
+	return store.Store.ContactsPurgeBefore(ctx, before)
+}
+
+func (store StoreAuditor) ContactsModify(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	var c *domain.Contact
+	_, err := store.record(ctx, domain.AuditUpdate, id, func(s domain.Store) (domain.ContactID, error) {
+		var err error
+		c, err = s.ContactsModify(ctx, id, p)
+		return id, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (store StoreAuditor) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	return store.Store.ContactsList(ctx, q)
+}
+
+// ContactsAtomic calls the function with an auditor of the transaction, recording the changes in it.
+// It fails with [errors.ErrUnsupported] if the wrapped store is not a [domain.AtomicStore].
+func (store StoreAuditor) ContactsAtomic(ctx context.Context, f func(domain.Store) error) error {
+	atomic, ok := store.Store.(domain.AtomicStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return atomic.ContactsAtomic(ctx, func(s domain.Store) error {
+		tx := store
+		tx.Store, tx.atomic = s, true
+		return f(tx)
+	})
+}
+
+func (store StoreAuditor) OutboxAppend(ctx context.Context, events ...*domain.Event) error {
+	outbox, ok := store.Store.(domain.OutboxStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return outbox.OutboxAppend(ctx, events...)
+}
+
+func (store StoreAuditor) OutboxFetch(ctx context.Context, limit int) ([]*domain.Event, error) {
+	outbox, ok := store.Store.(domain.OutboxStore)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return outbox.OutboxFetch(ctx, limit)
+}
+
+func (store StoreAuditor) OutboxDelete(ctx context.Context, ids ...string) error {
+	outbox, ok := store.Store.(domain.OutboxStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return outbox.OutboxDelete(ctx, ids...)
+}
+
+func (store StoreAuditor) AuditAppend(ctx context.Context, e *domain.AuditEvent) error {
+	audit, ok := store.Store.(domain.AuditStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return audit.AuditAppend(ctx, e)
+}
+
+func (store StoreAuditor) AuditList(ctx context.Context, q *domain.AuditQuery) (*domain.AuditPage, error) {
+	audit, ok := store.Store.(domain.AuditStore)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return audit.AuditList(ctx, q)
+}
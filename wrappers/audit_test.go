@@ -0,0 +1,129 @@
+package wrappers_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+func TestAuditor(t *testing.T) {
+	store := stores.MustNewMock()
+	service := &domain.ServiceStore{Store: wrappers.StoreAuditor{
+		Store:     store,
+		Actor:     func(context.Context) string { return "alice" },
+		RequestID: func(context.Context) string { return "req-1" },
+	}}
+
+	domaintest.TestService(t, service)
+
+	ctx := t.Context()
+	id, err := service.ContactsCreate(ctx, domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := service.ContactsRead(ctx, id)
+	c.Birthday = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := service.ContactsUpdate(ctx, id, c); err != nil {
+		t.Fatal(err)
+	}
+	jane := "jane"
+	_, err = service.ContactsPatch(ctx, id, &domain.ContactPatch{Firstname: &jane})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ContactsUpdate(ctx, id, &domain.Contact{Version: 1}); err == nil {
+		t.Fatal("ContactsUpdate with a stale version succeeded")
+	}
+	if err := service.ContactsDelete(ctx, id, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ContactsRestore(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	// the update of the deleted contact aborts the batch, and the deletion is not recorded
+	_, err = service.ContactsBatch(ctx, []domain.BatchAction{
+		{Op: domain.BatchDelete, ID: id},
+		{Op: domain.BatchUpdate, ID: id, Contact: domaintest.NewContact()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := service.ContactsHistory(ctx, &domain.AuditQuery{ContactID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []domain.AuditAction
+	for _, e := range page.Events {
+		actions = append(actions, e.Action)
+		if e.Actor != "alice" || e.RequestID != "req-1" || e.Time.IsZero() {
+			t.Errorf("event %+v misses its context", e)
+		}
+	}
+	want := []domain.AuditAction{
+		domain.AuditRestore, domain.AuditDelete, domain.AuditUpdate, domain.AuditUpdate, domain.AuditCreate,
+	}
+	if !slices.Equal(actions, want) {
+		t.Fatal("ContactsHistory got", actions, "want", want)
+	}
+
+	for n, want := range map[int][]domain.AuditChange{
+		2: {{Field: "firstname", Before: c.Firstname, After: "jane"}},
+		3: {{Field: "birthday", Before: "1999-12-31", After: "2000-01-01"}},
+	} {
+		if got := page.Events[n].Changes; !slices.Equal(got, want) {
+			t.Errorf("event %d changes got %+v, want %+v", n, got, want)
+		}
+	}
+	if len(page.Events[1].Changes) == 0 || page.Events[1].Changes[0].After != "" {
+		t.Errorf("delete event changes got %+v", page.Events[1].Changes)
+	}
+
+	t.Run("Failure", func(t *testing.T) {
+		store := stores.MustNewMock()
+		service := &domain.ServiceStore{Store: wrappers.StoreAuditor{Store: failingAuditStore{store}}}
+		if _, err := service.ContactsCreate(t.Context(), domaintest.NewContact()); !errors.Is(err, errAudit) {
+			t.Fatal("ContactsCreate got", err, "want", errAudit)
+		}
+		page, err := store.ContactsList(t.Context(), &domain.ContactsQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Contacts) != 0 {
+			t.Error("ContactsCreate kept a contact whose event failed to be recorded")
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		// hides the history of the mock
+		store := struct{ domain.Store }{stores.MustNewMock()}
+		service := &domain.ServiceStore{Store: wrappers.StoreAuditor{Store: store}}
+		_, err := service.ContactsCreate(t.Context(), domaintest.NewContact())
+		if !errors.Is(err, errors.ErrUnsupported) {
+			t.Error("ContactsCreate without history got", err, "want", errors.ErrUnsupported)
+		}
+	})
+}
+
+var errAudit = errors.New("audit failed")
+
+// failingAuditStore is an atomic store failing to record audit events.
+type failingAuditStore struct{ domain.Store }
+
+func (s failingAuditStore) ContactsAtomic(ctx context.Context, f func(domain.Store) error) error {
+	atomic := s.Store.(domain.AtomicStore) //nolint: errcheck // always true
+	return atomic.ContactsAtomic(ctx, func(store domain.Store) error { return f(failingAuditStore{store}) })
+}
+
+func (failingAuditStore) AuditAppend(context.Context, *domain.AuditEvent) error { return errAudit }
+
+func (failingAuditStore) AuditList(context.Context, *domain.AuditQuery) (*domain.AuditPage, error) {
+	return nil, errAudit
+}
@@ -0,0 +1,257 @@
+package wrappers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/webhooks"
+)
+
+// Permission is required to call [domain.Service] methods.
+type Permission string
+
+// Permissions required by [ServiceAuthorizer] and [WebhooksAuthorizer].
+const (
+	PermissionContactsRead   Permission = "contacts:read"   // ContactsRead, ContactsList, ContactsHistory and ContactsEvents
+	PermissionContactsWrite  Permission = "contacts:write"  // ContactsCreate, ContactsUpdate, ContactsPatch, ContactsRestore and batches
+	PermissionContactsDelete Permission = "contacts:delete" // ContactsDelete, ContactsPurge and batches deleting contacts
+	PermissionWebhooks       Permission = "webhooks:manage" // managing webhook subscriptions and their dead letters
+	PermissionAll            Permission = "*"               // every permission
+)
+
+// Policy grants permissions to roles.
+//
+// Roles named after a permission, e.g. the "contacts:read" scope of an OAuth token, are always granted it.
+type Policy map[string][]Permission
+
+// LoadPolicy reads a [Policy] from a JSON file mapping roles to permissions,
+// e.g. {"admin": ["*"], "editor": ["contacts:read", "contacts:write"]}.
+func LoadPolicy(path string) (Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	err = json.Unmarshal(b, &p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Allows reports whether any of the roles is granted a permission.
+func (p Policy) Allows(roles []string, perm Permission) bool {
+	for _, role := range roles {
+		if role == string(perm) || slices.Contains(p[role], perm) || slices.Contains(p[role], PermissionAll) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceAuthorizer wraps a [domain.Service] to only let callers granted the required [Permission] call it,
+// others get a [domain.ErrForbidden].
+type ServiceAuthorizer struct {
+	Service domain.Service
+	Policy  Policy
+	Roles   func(context.Context) []string // roles of the caller, none if anonymous
+}
+
+// authorize fails with a [domain.ErrForbidden] unless any of the roles is granted a permission.
+func (p Policy) authorize(roles []string, perm Permission) error {
+	if !p.Allows(roles, perm) {
+		return fmt.Errorf("%w: %s permission required", domain.ErrForbidden, perm)
+	}
+	return nil
+}
+
+func (service ServiceAuthorizer) authorize(ctx context.Context, perm Permission) error {
+	return service.Policy.authorize(service.Roles(ctx), perm)
+}
+
+func (service ServiceAuthorizer) ContactsCreate(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	err := service.authorize(ctx, PermissionContactsWrite)
+	if err != nil {
+		return domain.ContactID{}, err
+	}
+	return service.Service.ContactsCreate(ctx, c)
+}
+
+func (service ServiceAuthorizer) ContactsRead(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	err := service.authorize(ctx, PermissionContactsRead)
+	if err != nil {
+		return nil, err
+	}
+	return service.Service.ContactsRead(ctx, id)
+}
+
+func (service ServiceAuthorizer) ContactsUpdate(
+	ctx context.Context,
+	id domain.ContactID,
+	c *domain.Contact,
+) (int, error) {
+	err := service.authorize(ctx, PermissionContactsWrite)
+	if err != nil {
+		return 0, err
+	}
+	return service.Service.ContactsUpdate(ctx, id, c)
+}
+
+func (service ServiceAuthorizer) ContactsDelete(ctx context.Context, id domain.ContactID, version int) error {
+	err := service.authorize(ctx, PermissionContactsDelete)
+	if err != nil {
+		return err
+	}
+	return service.Service.ContactsDelete(ctx, id, version)
+}
+
+func (service ServiceAuthorizer) ContactsRestore(ctx context.Context, id domain.ContactID) error {
+	err := service.authorize(ctx, PermissionContactsWrite)
+	if err != nil {
+		return err
+	}
+	return service.Service.ContactsRestore(ctx, id)
+}
+
+func (service ServiceAuthorizer) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	err := service.authorize(ctx, PermissionContactsDelete)
+	if err != nil {
+		return err
+	}
+	return service.Service.ContactsPurge(ctx, id)
+}
+
+func (service ServiceAuthorizer) ContactsPatch(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	err := service.authorize(ctx, PermissionContactsWrite)
+	if err != nil {
+		return nil, err
+	}
+	return service.Service.ContactsPatch(ctx, id, p)
+}
+
+func (service ServiceAuthorizer) ContactsList(
+	ctx context.Context,
+	q *domain.ContactsQuery,
+) (*domain.ContactsPage, error) {
+	err := service.authorize(ctx, PermissionContactsRead)
+	if err != nil {
+		return nil, err
+	}
+	return service.Service.ContactsList(ctx, q)
+}
+
+// ContactsBatch requires the permissions of every operation of the batch.
+func (service ServiceAuthorizer) ContactsBatch(
+	ctx context.Context,
+	actions []domain.BatchAction,
+) ([]domain.BatchResult, error) {
+	required := map[Permission]bool{}
+	for _, a := range actions {
+		if a.Op == domain.BatchDelete {
+			required[PermissionContactsDelete] = true
+		} else {
+			required[PermissionContactsWrite] = true
+		}
+	}
+	for _, perm := range []Permission{PermissionContactsWrite, PermissionContactsDelete} {
+		if !required[perm] {
+			continue
+		}
+		err := service.authorize(ctx, perm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return service.Service.ContactsBatch(ctx, actions)
+}
+
+func (service ServiceAuthorizer) ContactsHistory(
+	ctx context.Context,
+	q *domain.AuditQuery,
+) (*domain.AuditPage, error) {
+	err := service.authorize(ctx, PermissionContactsRead)
+	if err != nil {
+		return nil, err
+	}
+	return service.Service.ContactsHistory(ctx, q)
+}
+
+func (service ServiceAuthorizer) ContactsEvents(
+	ctx context.Context,
+	q *domain.EventsQuery,
+) (<-chan *domain.Event, error) {
+	err := service.authorize(ctx, PermissionContactsRead)
+	if err != nil {
+		return nil, err
+	}
+	return service.Service.ContactsEvents(ctx, q)
+}
+
+// WebhooksAuthorizer wraps a [webhooks.Store] to only let callers granted [PermissionWebhooks] manage subscriptions,
+// others get a [domain.ErrForbidden]. Deliveries are not authorized, they are the business of dispatchers.
+type WebhooksAuthorizer struct {
+	webhooks.Store
+	Policy Policy
+	Roles  func(context.Context) []string // roles of the caller, none if anonymous
+}
+
+func (store WebhooksAuthorizer) authorize(ctx context.Context) error {
+	return store.Policy.authorize(store.Roles(ctx), PermissionWebhooks)
+}
+
+func (store WebhooksAuthorizer) WebhooksCreate(ctx context.Context, s *webhooks.Subscription) error {
+	err := store.authorize(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Store.WebhooksCreate(ctx, s)
+}
+
+func (store WebhooksAuthorizer) WebhooksGet(ctx context.Context, id string) (*webhooks.Subscription, error) {
+	err := store.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Store.WebhooksGet(ctx, id)
+}
+
+func (store WebhooksAuthorizer) WebhooksList(ctx context.Context) ([]*webhooks.Subscription, error) {
+	err := store.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Store.WebhooksList(ctx)
+}
+
+func (store WebhooksAuthorizer) WebhooksDelete(ctx context.Context, id string) error {
+	err := store.authorize(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Store.WebhooksDelete(ctx, id)
+}
+
+func (store WebhooksAuthorizer) DeliveriesDead(ctx context.Context, id string) ([]*webhooks.Delivery, error) {
+	err := store.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Store.DeliveriesDead(ctx, id)
+}
+
+func (store WebhooksAuthorizer) DeliveriesRevive(ctx context.Context, id string, t time.Time) (int, error) {
+	err := store.authorize(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return store.Store.DeliveriesRevive(ctx, id, t)
+}
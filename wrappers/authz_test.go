@@ -0,0 +1,113 @@
+package wrappers_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/webhooks"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+type rolesKey struct{}
+
+func withRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+func TestAuthorizer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(path, []byte(`{"admin": ["*"], "editor": ["contacts:read", "contacts:write"]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := wrappers.LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := wrappers.ServiceAuthorizer{
+		Service: &domain.ServiceStore{Store: stores.MustNewMock()},
+		Policy:  policy,
+		Roles: func(ctx context.Context) []string {
+			roles, _ := ctx.Value(rolesKey{}).([]string)
+			return roles
+		},
+	}
+
+	domaintest.TestService(t, service, domaintest.OptContext(func(ctx context.Context) context.Context {
+		return withRoles(ctx, "admin")
+	}))
+
+	for _, tt := range []struct {
+		name      string
+		roles     []string
+		forbidden []string
+	}{
+		{"anonymous", nil, []string{
+			"ContactsCreate", "ContactsRead", "ContactsUpdate", "ContactsDelete", "ContactsPatch", "ContactsList",
+			"ContactsBatch", "ContactsRestore", "ContactsPurge", "ContactsHistory", "ContactsEvents",
+		}},
+		{"unknown", []string{"nobody"}, []string{
+			"ContactsCreate", "ContactsRead", "ContactsUpdate", "ContactsDelete", "ContactsPatch", "ContactsList",
+			"ContactsBatch", "ContactsRestore", "ContactsPurge", "ContactsHistory", "ContactsEvents",
+		}},
+		{"scope", []string{"contacts:read"}, []string{
+			"ContactsCreate", "ContactsUpdate", "ContactsDelete", "ContactsPatch", "ContactsBatch",
+			"ContactsRestore", "ContactsPurge",
+		}},
+		{"editor", []string{"editor"}, []string{"ContactsDelete", "ContactsPurge"}},
+		{"editor and scope", []string{"editor", "contacts:delete"}, nil},
+		{"admin", []string{"admin"}, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			domaintest.TestForbidden(t, withRoles(t.Context(), tt.roles...), service, tt.forbidden...)
+		})
+	}
+}
+
+func TestWebhooksAuthorizer(t *testing.T) {
+	store := wrappers.WebhooksAuthorizer{
+		Store:  &webhooks.Memory{},
+		Policy: wrappers.Policy{"admin": {wrappers.PermissionAll}},
+		Roles: func(ctx context.Context) []string {
+			roles, _ := ctx.Value(rolesKey{}).([]string)
+			return roles
+		},
+	}
+
+	admin := withRoles(t.Context(), "admin")
+	s := &webhooks.Subscription{URL: "https://example.com/hooks"}
+	if err := store.WebhooksCreate(admin, s); err != nil {
+		t.Fatal("WebhooksCreate as admin:", err)
+	}
+	if _, err := store.WebhooksList(withRoles(t.Context(), "webhooks:manage")); err != nil {
+		t.Error("WebhooksList with scope:", err)
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"anonymous": t.Context(),
+		"scope":     withRoles(t.Context(), "contacts:read", "contacts:write", "contacts:delete"),
+	} {
+		for method, err := range map[string]error{
+			"WebhooksCreate":   store.WebhooksCreate(ctx, &webhooks.Subscription{URL: s.URL}),
+			"WebhooksGet":      second(store.WebhooksGet(ctx, s.ID)),
+			"WebhooksList":     second(store.WebhooksList(ctx)),
+			"WebhooksDelete":   store.WebhooksDelete(ctx, s.ID),
+			"DeliveriesDead":   second(store.DeliveriesDead(ctx, s.ID)),
+			"DeliveriesRevive": second(store.DeliveriesRevive(ctx, s.ID, time.Now())),
+		} {
+			if !errors.Is(err, domain.ErrForbidden) {
+				t.Errorf("%s as %s returned %v", method, name, err)
+			}
+		}
+	}
+}
+
+func second[T any](_ T, err error) error { return err }
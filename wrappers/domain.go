@@ -30,14 +30,76 @@ func (service ServiceErrorHandler) ContactsRead(ctx context.Context, id domain.C
 	return c, err
 }
 
-func (service ServiceErrorHandler) ContactsUpdate(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
-	err := service.Service.ContactsUpdate(ctx, id, c)
+func (service ServiceErrorHandler) ContactsUpdate(
+	ctx context.Context,
+	id domain.ContactID,
+	c *domain.Contact,
+) (int, error) {
+	version, err := service.Service.ContactsUpdate(ctx, id, c)
+	service.handle(ctx, err)
+	return version, err
+}
+
+func (service ServiceErrorHandler) ContactsDelete(ctx context.Context, id domain.ContactID, version int) error {
+	err := service.Service.ContactsDelete(ctx, id, version)
+	service.handle(ctx, err)
+	return err
+}
+
+func (service ServiceErrorHandler) ContactsRestore(ctx context.Context, id domain.ContactID) error {
+	err := service.Service.ContactsRestore(ctx, id)
 	service.handle(ctx, err)
 	return err
 }
 
-func (service ServiceErrorHandler) ContactsDelete(ctx context.Context, id domain.ContactID) error {
-	err := service.Service.ContactsDelete(ctx, id)
+func (service ServiceErrorHandler) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	err := service.Service.ContactsPurge(ctx, id)
 	service.handle(ctx, err)
 	return err
 }
+
+func (service ServiceErrorHandler) ContactsPatch(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	c, err := service.Service.ContactsPatch(ctx, id, p)
+	service.handle(ctx, err)
+	return c, err
+}
+
+func (service ServiceErrorHandler) ContactsList(
+	ctx context.Context,
+	q *domain.ContactsQuery,
+) (*domain.ContactsPage, error) {
+	page, err := service.Service.ContactsList(ctx, q)
+	service.handle(ctx, err)
+	return page, err
+}
+
+func (service ServiceErrorHandler) ContactsBatch(
+	ctx context.Context,
+	actions []domain.BatchAction,
+) ([]domain.BatchResult, error) {
+	results, err := service.Service.ContactsBatch(ctx, actions)
+	service.handle(ctx, err)
+	return results, err
+}
+
+func (service ServiceErrorHandler) ContactsEvents(
+	ctx context.Context,
+	q *domain.EventsQuery,
+) (<-chan *domain.Event, error) {
+	events, err := service.Service.ContactsEvents(ctx, q)
+	service.handle(ctx, err)
+	return events, err
+}
+
+func (service ServiceErrorHandler) ContactsHistory(
+	ctx context.Context,
+	q *domain.AuditQuery,
+) (*domain.AuditPage, error) {
+	page, err := service.Service.ContactsHistory(ctx, q)
+	service.handle(ctx, err)
+	return page, err
+}
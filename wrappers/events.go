@@ -0,0 +1,148 @@
+package wrappers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// EventLog defaults.
+const (
+	DefaultEventLogSize   = 1000 // events retained
+	DefaultEventLogBuffer = 64   // events buffered per subscriber
+)
+
+// EventLog is a bounded in-memory [domain.EventStream], logging the events broadcast to subscribers.
+//
+// The zero value is ready to use.
+type EventLog struct {
+	Size   int // of the log, defaults to [DefaultEventLogSize]
+	Buffer int // of subscribers, lagging ones being dropped, defaults to [DefaultEventLogBuffer]
+
+	mu          sync.Mutex
+	events      []*domain.Event
+	subscribers map[*eventSubscriber]struct{}
+	closed      bool
+}
+
+var _ domain.EventStream = (*EventLog)(nil)
+
+type eventSubscriber struct {
+	tenant domain.TenantID
+	query  domain.EventsQuery
+	ch     chan *domain.Event
+}
+
+// accepts reports whether an event is sent to the subscriber.
+func (s *eventSubscriber) accepts(e *domain.Event) bool {
+	return e.Tenant == s.tenant && s.query.Matches(e)
+}
+
+// Publish logs events and sends them to the subscribers of their tenant and contact, see [domain.EventsQuery].
+func (l *EventLog) Publish(events ...*domain.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	size := l.Size
+	if size <= 0 {
+		size = DefaultEventLogSize
+	}
+	l.events = append(l.events, events...)
+	if len(l.events) > size {
+		l.events = slices.Clone(l.events[len(l.events)-size:])
+	}
+
+	for s := range l.subscribers {
+		for _, e := range events {
+			if s.accepts(e) && !s.send(e) {
+				l.drop(s)
+				break
+			}
+		}
+	}
+}
+
+// send sends an event to a subscriber unless its buffer is full.
+func (s *eventSubscriber) send(e *domain.Event) bool {
+	select {
+	case s.ch <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// Subscribe returns a channel of the events of a tenant matching an [domain.EventsQuery],
+// see [domain.Service.ContactsEvents]. It is closed once the context is done or the log closed,
+// or early if the subscriber lags behind.
+//
+// It fails with [domain.ErrEventsLost] if the last event of the query is not in the log, e.g. evicted.
+func (l *EventLog) Subscribe(
+	ctx context.Context,
+	tenant domain.TenantID,
+	q *domain.EventsQuery,
+) (<-chan *domain.Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := &eventSubscriber{tenant: tenant, query: *q}
+	var backlog []*domain.Event
+	if q.LastID != "" {
+		n := slices.IndexFunc(l.events, func(e *domain.Event) bool { return e.ID == q.LastID })
+		if n < 0 {
+			return nil, fmt.Errorf("%w: event %q not retained", domain.ErrEventsLost, q.LastID)
+		}
+		for _, e := range l.events[n+1:] {
+			if s.accepts(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+
+	buffer := l.Buffer
+	if buffer <= 0 {
+		buffer = DefaultEventLogBuffer
+	}
+	s.ch = make(chan *domain.Event, len(backlog)+buffer)
+	for _, e := range backlog {
+		s.ch <- e
+	}
+	if l.closed {
+		close(s.ch)
+		return s.ch, nil
+	}
+
+	if l.subscribers == nil {
+		l.subscribers = map[*eventSubscriber]struct{}{}
+	}
+	l.subscribers[s] = struct{}{}
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.drop(s)
+	}()
+	return s.ch, nil
+}
+
+// drop closes the channel of a subscriber, if not already.
+func (l *EventLog) drop(s *eventSubscriber) {
+	if _, ok := l.subscribers[s]; ok {
+		delete(l.subscribers, s)
+		close(s.ch)
+	}
+}
+
+// Close closes the channels of the subscribers, e.g. on shutdown, and those of later ones.
+// Events are still logged.
+func (l *EventLog) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for s := range l.subscribers {
+		l.drop(s)
+	}
+}
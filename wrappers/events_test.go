@@ -0,0 +1,154 @@
+package wrappers_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+// drain returns the events of a channel received until it blocks or is closed, and whether it is closed.
+func drain(events <-chan *domain.Event) ([]*domain.Event, bool) {
+	var got []*domain.Event
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return got, true
+			}
+			got = append(got, e)
+		default:
+			return got, false
+		}
+	}
+}
+
+func TestEventLogService(t *testing.T) {
+	service := &domain.ServiceStore{Store: stores.MustNewMock(), Events: &wrappers.EventLog{}}
+
+	domaintest.TestService(t, service)
+
+	ctx, cancel := context.WithCancel(domain.WithTenant(t.Context(), "acme"))
+	events, err := service.ContactsEvents(ctx, &domain.EventsQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := service.ContactsEvents(t.Context(), &domain.EventsQuery{})
+
+	id, err := service.ContactsCreate(ctx, domaintest.NewContact())
+	if err != nil {
+		t.Fatal(err)
+	}
+	contact, _ := service.ContactsEvents(ctx, &domain.EventsQuery{ContactID: id})
+	if _, err := service.ContactsPatch(ctx, id, &domain.ContactPatch{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ContactsDelete(ctx, id, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ContactsRestore(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.ContactsBatch(ctx, []domain.BatchAction{
+		{Op: domain.BatchCreate, Contact: domaintest.NewContact()},
+		{Op: domain.BatchDelete, ID: id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ContactsPurge(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	// failed writes publish no events
+	if _, err := service.ContactsPatch(ctx, id, &domain.ContactPatch{}); err == nil {
+		t.Fatal("ContactsPatch of a purged contact succeeded")
+	}
+
+	got, _ := drain(events)
+	var types []domain.EventType
+	for _, e := range got {
+		types = append(types, e.Type)
+		if e.Tenant != "acme" || (e.Contact == nil) != (e.Type == domain.ContactDeleted) {
+			t.Errorf("got event %+v", e)
+		}
+	}
+	want := []domain.EventType{
+		domain.ContactCreated, domain.ContactUpdated, domain.ContactDeleted, domain.ContactUpdated,
+		domain.ContactCreated, domain.ContactDeleted,
+	}
+	if !slices.Equal(types, want) {
+		t.Error("got events", types, "want", want)
+	}
+	if got, _ := drain(other); len(got) != 0 {
+		t.Error("got events of another tenant", got)
+	}
+	if rest, _ := drain(contact); !slices.Equal(rest, []*domain.Event{got[1], got[2], got[3], got[5]}) {
+		t.Error("got events of the contact", rest)
+	}
+
+	resumed, _ := service.ContactsEvents(ctx, &domain.EventsQuery{LastID: got[3].ID})
+	if rest, _ := drain(resumed); len(rest) != 2 || rest[0] != got[4] || rest[1] != got[5] {
+		t.Error("got resumed events", rest, "want", got[4:])
+	}
+	resumed, _ = service.ContactsEvents(ctx, &domain.EventsQuery{LastID: got[3].ID, ContactID: id})
+	if rest, _ := drain(resumed); len(rest) != 1 || rest[0] != got[5] {
+		t.Error("got resumed events of the contact", rest, "want", got[5])
+	}
+
+	cancel()
+	for range events { //nolint: revive // waits for the channel to be closed
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	log := &wrappers.EventLog{Size: 3, Buffer: 2}
+	publish := func(n int) {
+		for range n {
+			log.Publish(&domain.Event{ID: strconv.Itoa(n)})
+		}
+	}
+
+	subscribe := func(q *domain.EventsQuery) <-chan *domain.Event {
+		t.Helper()
+		events, err := log.Subscribe(t.Context(), "", q)
+		if err != nil {
+			t.Fatal("Subscribe:", err)
+		}
+		return events
+	}
+
+	first := &domain.Event{ID: "first"}
+	log.Publish(first)
+	publish(2)
+	if got, _ := drain(subscribe(&domain.EventsQuery{LastID: first.ID})); len(got) != 2 {
+		t.Error("got", len(got), "events after the first, want 2")
+	}
+	publish(1)
+	for _, id := range []string{first.ID, "unknown"} {
+		_, err := log.Subscribe(t.Context(), "", &domain.EventsQuery{LastID: id})
+		if !errors.Is(err, domain.ErrEventsLost) {
+			t.Errorf("Subscribe after event %q got %v, want %v", id, err, domain.ErrEventsLost)
+		}
+	}
+
+	lagging := subscribe(&domain.EventsQuery{})
+	publish(3)
+	if got, closed := drain(lagging); len(got) != 2 || !closed {
+		t.Error("got", len(got), "events from a lagging subscriber, closed", closed, "want 2 then closed")
+	}
+
+	live := subscribe(&domain.EventsQuery{})
+	log.Close()
+	if _, closed := drain(live); !closed {
+		t.Error("subscription not closed by Close")
+	}
+	if _, closed := drain(subscribe(&domain.EventsQuery{})); !closed {
+		t.Error("subscription after Close not closed")
+	}
+}
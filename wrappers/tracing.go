@@ -0,0 +1,294 @@
+package wrappers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rlibaert/service-example-go/domain"
+)
+
+// Span attributes.
+const (
+	contactIDKey    = attribute.Key("contact.id")    // of a [domain.ContactID]
+	batchActionsKey = attribute.Key("batch.actions") // number of [domain.BatchAction]
+	outboxEventsKey = attribute.Key("outbox.events") // number of [domain.Event]
+)
+
+// start starts an internal span as a child of the one in the context.
+func start(
+	ctx context.Context,
+	tracer trace.Tracer,
+	name string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+}
+
+// end records the error of a span, if any, and ends it.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ServiceTracer wraps a [domain.Service] to trace its calls as spans.
+type ServiceTracer struct {
+	Service domain.Service
+	Tracer  trace.Tracer
+}
+
+func (service ServiceTracer) ContactsCreate(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsCreate")
+	id, err := service.Service.ContactsCreate(ctx, c)
+	span.SetAttributes(contactIDKey.String(id.String()))
+	end(span, err)
+	return id, err
+}
+
+func (service ServiceTracer) ContactsRead(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsRead", contactIDKey.String(id.String()))
+	c, err := service.Service.ContactsRead(ctx, id)
+	end(span, err)
+	return c, err
+}
+
+func (service ServiceTracer) ContactsUpdate(
+	ctx context.Context,
+	id domain.ContactID,
+	c *domain.Contact,
+) (int, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsUpdate", contactIDKey.String(id.String()))
+	version, err := service.Service.ContactsUpdate(ctx, id, c)
+	end(span, err)
+	return version, err
+}
+
+func (service ServiceTracer) ContactsDelete(ctx context.Context, id domain.ContactID, version int) error {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsDelete", contactIDKey.String(id.String()))
+	err := service.Service.ContactsDelete(ctx, id, version)
+	end(span, err)
+	return err
+}
+
+func (service ServiceTracer) ContactsRestore(ctx context.Context, id domain.ContactID) error {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsRestore", contactIDKey.String(id.String()))
+	err := service.Service.ContactsRestore(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (service ServiceTracer) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsPurge", contactIDKey.String(id.String()))
+	err := service.Service.ContactsPurge(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (service ServiceTracer) ContactsPatch(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsPatch", contactIDKey.String(id.String()))
+	c, err := service.Service.ContactsPatch(ctx, id, p)
+	end(span, err)
+	return c, err
+}
+
+func (service ServiceTracer) ContactsList(
+	ctx context.Context,
+	q *domain.ContactsQuery,
+) (*domain.ContactsPage, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsList")
+	page, err := service.Service.ContactsList(ctx, q)
+	end(span, err)
+	return page, err
+}
+
+func (service ServiceTracer) ContactsBatch(
+	ctx context.Context,
+	actions []domain.BatchAction,
+) ([]domain.BatchResult, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsBatch", batchActionsKey.Int(len(actions)))
+	results, err := service.Service.ContactsBatch(ctx, actions)
+	end(span, err)
+	return results, err
+}
+
+func (service ServiceTracer) ContactsHistory(
+	ctx context.Context,
+	q *domain.AuditQuery,
+) (*domain.AuditPage, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsHistory", contactIDKey.String(q.ContactID.String()))
+	page, err := service.Service.ContactsHistory(ctx, q)
+	end(span, err)
+	return page, err
+}
+
+// ContactsEvents traces the subscription to the events, not their stream.
+func (service ServiceTracer) ContactsEvents(
+	ctx context.Context,
+	q *domain.EventsQuery,
+) (<-chan *domain.Event, error) {
+	ctx, span := start(ctx, service.Tracer, "Service.ContactsEvents")
+	events, err := service.Service.ContactsEvents(ctx, q)
+	end(span, err)
+	return events, err
+}
+
+// StoreTracer wraps a [domain.Store] to trace its calls as spans.
+type StoreTracer struct {
+	Store  domain.Store
+	Tracer trace.Tracer
+}
+
+func (store StoreTracer) ContactsSet(ctx context.Context, c *domain.Contact) (domain.ContactID, error) {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsSet")
+	id, err := store.Store.ContactsSet(ctx, c)
+	span.SetAttributes(contactIDKey.String(id.String()))
+	end(span, err)
+	return id, err
+}
+
+func (store StoreTracer) ContactsGet(ctx context.Context, id domain.ContactID) (*domain.Contact, error) {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsGet", contactIDKey.String(id.String()))
+	c, err := store.Store.ContactsGet(ctx, id)
+	end(span, err)
+	return c, err
+}
+
+func (store StoreTracer) ContactsReset(ctx context.Context, id domain.ContactID, c *domain.Contact) error {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsReset", contactIDKey.String(id.String()))
+	err := store.Store.ContactsReset(ctx, id, c)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) ContactsDel(ctx context.Context, id domain.ContactID, version int) error {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsDel", contactIDKey.String(id.String()))
+	err := store.Store.ContactsDel(ctx, id, version)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) ContactsUndel(ctx context.Context, id domain.ContactID) error {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsUndel", contactIDKey.String(id.String()))
+	err := store.Store.ContactsUndel(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) ContactsPurge(ctx context.Context, id domain.ContactID) error {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsPurge", contactIDKey.String(id.String()))
+	err := store.Store.ContactsPurge(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) ContactsPurgeBefore(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsPurgeBefore")
+	n, err := store.Store.ContactsPurgeBefore(ctx, before)
+	end(span, err)
+	return n, err
+}
+
+func (store StoreTracer) ContactsModify(
+	ctx context.Context,
+	id domain.ContactID,
+	p *domain.ContactPatch,
+) (*domain.Contact, error) {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsModify", contactIDKey.String(id.String()))
+	c, err := store.Store.ContactsModify(ctx, id, p)
+	end(span, err)
+	return c, err
+}
+
+func (store StoreTracer) ContactsList(ctx context.Context, q *domain.ContactsQuery) (*domain.ContactsPage, error) {
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsList")
+	page, err := store.Store.ContactsList(ctx, q)
+	end(span, err)
+	return page, err
+}
+
+// ContactsAtomic traces the transaction as a span, and the calls of the function as well.
+// It fails with [errors.ErrUnsupported] if the wrapped store is not a [domain.AtomicStore].
+func (store StoreTracer) ContactsAtomic(ctx context.Context, f func(domain.Store) error) error {
+	atomic, ok := store.Store.(domain.AtomicStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	ctx, span := start(ctx, store.Tracer, "Store.ContactsAtomic")
+	err := atomic.ContactsAtomic(ctx, func(s domain.Store) error {
+		return f(StoreTracer{Store: s, Tracer: store.Tracer})
+	})
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) OutboxAppend(ctx context.Context, events ...*domain.Event) error {
+	outbox, ok := store.Store.(domain.OutboxStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	ctx, span := start(ctx, store.Tracer, "Store.OutboxAppend", outboxEventsKey.Int(len(events)))
+	err := outbox.OutboxAppend(ctx, events...)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) OutboxFetch(ctx context.Context, limit int) ([]*domain.Event, error) {
+	outbox, ok := store.Store.(domain.OutboxStore)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	ctx, span := start(ctx, store.Tracer, "Store.OutboxFetch")
+	events, err := outbox.OutboxFetch(ctx, limit)
+	end(span, err)
+	return events, err
+}
+
+func (store StoreTracer) OutboxDelete(ctx context.Context, ids ...string) error {
+	outbox, ok := store.Store.(domain.OutboxStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	ctx, span := start(ctx, store.Tracer, "Store.OutboxDelete", outboxEventsKey.Int(len(ids)))
+	err := outbox.OutboxDelete(ctx, ids...)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) AuditAppend(ctx context.Context, e *domain.AuditEvent) error {
+	audit, ok := store.Store.(domain.AuditStore)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	ctx, span := start(ctx, store.Tracer, "Store.AuditAppend", contactIDKey.String(e.ContactID.String()))
+	err := audit.AuditAppend(ctx, e)
+	end(span, err)
+	return err
+}
+
+func (store StoreTracer) AuditList(ctx context.Context, q *domain.AuditQuery) (*domain.AuditPage, error) {
+	audit, ok := store.Store.(domain.AuditStore)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	ctx, span := start(ctx, store.Tracer, "Store.AuditList", contactIDKey.String(q.ContactID.String()))
+	page, err := audit.AuditList(ctx, q)
+	end(span, err)
+	return page, err
+}
@@ -0,0 +1,53 @@
+package wrappers_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/rlibaert/service-example-go/domain"
+	"github.com/rlibaert/service-example-go/domaintest"
+	"github.com/rlibaert/service-example-go/stores"
+	"github.com/rlibaert/service-example-go/wrappers"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	service := wrappers.ServiceTracer{
+		Service: &domain.ServiceStore{Store: wrappers.StoreTracer{Store: stores.MustNewMock(), Tracer: tracer}},
+		Tracer:  tracer,
+	}
+
+	domaintest.TestService(t, service)
+
+	t.Run("Spans", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+		service.Tracer = tracer
+		service.Service = &domain.ServiceStore{Store: wrappers.StoreTracer{Store: stores.MustNewMock(), Tracer: tracer}}
+
+		_, err := service.ContactsRead(context.Background(), domain.ContactID{})
+		if err == nil {
+			t.Fatal("got no error")
+		}
+
+		spans := recorder.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("got %d spans, want 2", len(spans))
+		}
+		store, svc := spans[0], spans[1]
+		if svc.Name() != "Service.ContactsRead" || store.Name() != "Store.ContactsGet" {
+			t.Errorf("got spans %q and %q", svc.Name(), store.Name())
+		}
+		if store.Parent().SpanID() != svc.SpanContext().SpanID() {
+			t.Error("store span is not a child of the service span")
+		}
+		if svc.Status().Code != codes.Error || store.Status().Code != codes.Error {
+			t.Errorf("got statuses %v and %v, want %v", svc.Status().Code, store.Status().Code, codes.Error)
+		}
+	})
+}